@@ -6,6 +6,8 @@ import (
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
 	artistrepository "eventro_aws/internals/repository/artist_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	artistservice "eventro_aws/internals/services/artist_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
@@ -16,6 +18,8 @@ import (
 )
 
 var artistService artistservice.ArtistServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -23,12 +27,15 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
 	artistRepo := artistrepository.NewArtistRepositoryDDB(ddb, "eventro")
 	artistService = artistservice.NewArtistService(artistRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(BrowseArtists)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, BrowseArtists)))
 }
 
 func BrowseArtists(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -6,7 +6,11 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
 	artistrepository "eventro_aws/internals/repository/artist_repository"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	artistservice "eventro_aws/internals/services/artist_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
@@ -18,6 +22,9 @@ import (
 )
 
 var artistService artistservice.ArtistServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -25,12 +32,17 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
 	artistRepo := artistrepository.NewArtistRepositoryDDB(ddb, "eventro")
 	artistService = artistservice.NewArtistService(artistRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(CreateArtist)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateArtist))))
 }
 
 type CreateArtistRequest struct {
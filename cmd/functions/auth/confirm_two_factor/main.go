@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ConfirmTwoFactor)))
+}
+
+// ConfirmTwoFactor switches 2FA on with a code from the newly enrolled app
+// and returns the caller's recovery codes.
+func ConfirmTwoFactor(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req models.TwoFactorRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.Code == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "code is required")
+	}
+
+	codes, err := authService.ConfirmTwoFactor(ctx, userEmail, req.Code)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidTwoFactor) {
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "two-factor authentication enabled", map[string][]string{"recovery_codes": codes})
+}
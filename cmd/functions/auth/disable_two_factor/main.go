@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, DisableTwoFactor)))
+}
+
+// DisableTwoFactor turns 2FA off for the caller after checking a code.
+func DisableTwoFactor(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req models.TwoFactorRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.Code == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "code is required")
+	}
+
+	if err := authService.DisableTwoFactor(ctx, userEmail, role, req.Code); err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "forbidden"):
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrInvalidTwoFactor):
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "two-factor authentication disabled", nil)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, EnrollTwoFactor)))
+}
+
+// EnrollTwoFactor issues a TOTP secret for the caller. It is not active
+// until confirmed with a code.
+func EnrollTwoFactor(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	enrollment, err := authService.EnrollTwoFactor(ctx, userEmail)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "two-factor enrollment started", enrollment)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"eventro_aws/db"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	service := authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+	service.AppURL = os.Getenv("APP_BASE_URL")
+	authService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(ForgotPassword))
+}
+
+// ForgotPassword mails a reset link to the address if it has an account. The
+// reply is the same either way.
+func ForgotPassword(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req models.ForgotPasswordRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.Email == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "email is required")
+	}
+
+	if err := authService.RequestPasswordReset(ctx, req.Email); err != nil {
+		return customresponse.LambdaError(http.StatusInternalServerError, "failed to start password reset")
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "if the email has an account, a reset link has been sent", nil)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// jwksMaxAge matches how long containers cache the key set, so a new key is
+// published well before tokens signed with it reach other services.
+const jwksMaxAge = 5 * time.Minute
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(GetJWKS))
+}
+
+// GetJWKS publishes the public keys our tokens can be verified with. The body
+// is the bare JWKS document rather than the usual response envelope, as
+// verifiers expect.
+func GetJWKS(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	ks, err := authorisation.CurrentKeySet(ctx, jwksMaxAge)
+	if err != nil {
+		log.Printf("failed to load JWT keys: %v", err)
+		return customresponse.LambdaError(http.StatusInternalServerError, "signing keys are unavailable")
+	}
+
+	body, err := json.Marshal(ks.JWKS())
+	if err != nil {
+		return customresponse.LambdaError(http.StatusInternalServerError, "failed to encode keys")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(body),
+		Headers: map[string]string{
+			"Content-Type":  "application/json",
+			"Cache-Control": "public, max-age=300",
+		},
+	}, nil
+}
@@ -3,13 +3,20 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"eventro_aws/db"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
 	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	userrepository "eventro_aws/internals/repository/user_repository"
 	"eventro_aws/internals/services/authorisation"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -24,7 +31,10 @@ func init() {
 	}
 
 	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
-	authService = authorisation.NewAuthService(userRepo)
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
 }
 
 func main() {
@@ -37,17 +47,26 @@ func Login(ctx context.Context, event events.APIGatewayProxyRequest) (events.API
 	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
 		return customresponse.LambdaError(400, "invalid request body ")
 	}
-	user, err := authService.ValidateLogin(ctx, req.Email, req.Password)
+	user, err := authService.ValidateLogin(ctx, req.Email, req.Password, event.RequestContext.Identity.SourceIP)
 
 	if err != nil {
+		var throttled *models.LoginThrottledError
+		if errors.As(err, &throttled) {
+			resp, rerr := customresponse.LambdaError(429, err.Error())
+			resp.Headers["Retry-After"] = strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds())))
+			return resp, rerr
+		}
 		message := err.Error()
 		return customresponse.LambdaError(401, message)
 	}
 
-	token, err := authorisation.GenerateJWT(user.UserID, user.Email, string(user.Role))
+	tokens, err := authService.CompleteLogin(ctx, user)
 	if err != nil {
 		return customresponse.LambdaError(500, "failed to generate token")
 	}
 
-	return customresponse.SendCustomResponse(200, "login sucessful", token)
+	if tokens.TwoFactorRequired {
+		return customresponse.SendCustomResponse(200, "two-factor code required", tokens)
+	}
+	return customresponse.SendCustomResponse(200, "login sucessful", tokens)
 }
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(LoginTwoFactor))
+}
+
+// LoginTwoFactor completes a login with the challenge token from the password
+// step and a code from the user's authenticator app or a recovery code.
+func LoginTwoFactor(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req models.TwoFactorRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "challenge_token and code are required")
+	}
+
+	tokens, err := authService.VerifyLoginChallenge(ctx, req.ChallengeToken, req.Code, event.RequestContext.Identity.SourceIP)
+	if err != nil {
+		var throttled *models.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			resp, rerr := customresponse.LambdaError(http.StatusTooManyRequests, err.Error())
+			resp.Headers["Retry-After"] = strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds())))
+			return resp, rerr
+		case errors.Is(err, models.ErrInvalidAuthToken), errors.Is(err, models.ErrInvalidTwoFactor):
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "login sucessful", tokens)
+}
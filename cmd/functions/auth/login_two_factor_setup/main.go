@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(LoginTwoFactorSetup))
+}
+
+// LoginTwoFactorSetup starts 2FA enrollment for a user who must have it
+// before they can log in. The code is then sent with the challenge token to
+// the login 2FA step.
+func LoginTwoFactorSetup(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req models.TwoFactorRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.ChallengeToken == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "challenge_token is required")
+	}
+
+	enrollment, err := authService.EnrollTwoFactorForLogin(ctx, req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidAuthToken) {
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "scan the code and enter a code to finish logging in", enrollment)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/authorisation"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var authService authorisation.AuthServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	authService = authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(RefreshToken))
+}
+
+// RefreshToken trades a refresh token for a new access token and refresh
+// token. The old refresh token cannot be used again.
+func RefreshToken(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req models.RefreshRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil || req.RefreshToken == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "refresh_token is required")
+	}
+
+	tokens, err := authService.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidRefreshToken),
+			errors.Is(err, models.ErrRefreshTokenReused),
+			errors.Is(err, models.ErrSessionRevoked):
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		log.Printf("failed to refresh token: %v", err)
+		return customresponse.LambdaError(http.StatusInternalServerError, "failed to refresh token")
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "token refreshed", tokens)
+}
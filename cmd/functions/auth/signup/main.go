@@ -6,10 +6,15 @@ import (
 	"eventro_aws/db"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
 	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	userrepository "eventro_aws/internals/repository/user_repository"
 	"eventro_aws/internals/services/authorisation"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -24,7 +29,12 @@ func init() {
 	}
 
 	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
-	authService = authorisation.NewAuthService(userRepo)
+	sessionRepo := sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	authTokenRepo := authtokenrepository.NewAuthTokenRepositoryDDB(ddb, "eventro")
+	loginAttemptRepo := loginattemptrepository.NewLoginAttemptRepositoryDDB(ddb, "eventro")
+	service := authorisation.NewAuthService(userRepo, sessionRepo, authTokenRepo, loginAttemptRepo, notifications.FromEnv())
+	service.AppURL = os.Getenv("APP_BASE_URL")
+	authService = service
 }
 
 func main() {
@@ -68,7 +78,7 @@ func Signup(ctx context.Context, event events.APIGatewayProxyRequest) (events.AP
 		}, nil
 	}
 
-	token, err := authorisation.GenerateJWT(user.UserID, user.Email, string(user.Role))
+	tokens, err := authService.StartSession(ctx, user, false)
 	if err != nil {
 		body, _ := json.Marshal(map[string]string{"message": "failed to generate token"})
 		return events.APIGatewayProxyResponse{
@@ -76,5 +86,5 @@ func Signup(ctx context.Context, event events.APIGatewayProxyRequest) (events.AP
 			Body:       string(body),
 		}, nil
 	}
-	return customresponse.SendCustomResponse(200, "signup successful", token)
+	return customresponse.SendCustomResponse(200, "signup successful", tokens)
 }
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	bookingService = bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
+}
+
+func main() {
+	lambda.Start(BackfillShowIndex)
+}
+
+// BackfillShowIndex runs on a schedule until every booking made before shows
+// kept a list of their bookings is indexed; after that each run returns at
+// once. Until it has finished, show lookups fall back to scanning.
+func BackfillShowIndex(ctx context.Context) error {
+	written, err := bookingService.BackfillShowIndex(ctx)
+	log.Printf("indexed %d bookings under their show", written)
+	return err
+}
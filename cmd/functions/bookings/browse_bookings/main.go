@@ -6,7 +6,10 @@ import (
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
 	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	bookingservice "eventro_aws/internals/services/booking_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
@@ -18,6 +21,8 @@ import (
 )
 
 var bookingService bookingservice.BookingServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -25,13 +30,17 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
 	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
 	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
-	bookingService = bookingservice.NewBookingService(bookingRepo, showRepo)
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	bookingService = bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(GetBookingsOfUser)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetBookingsOfUser)))
 }
 
 func GetBookingsOfUser(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
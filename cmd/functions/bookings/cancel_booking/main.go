@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/payments"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, CancelBooking)))
+}
+
+type CancelBookingRequest struct {
+	UserID string   `json:"user_id,omitempty"`
+	Seats  []string `json:"seats,omitempty"`
+}
+
+func CancelBooking(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	bookingID := event.PathParameters["bookingID"]
+	if bookingID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "bookingID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "customer" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only customers or admin for customers can cancel bookings")
+	}
+	authUserID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || authUserID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req CancelBookingRequest
+	if event.Body != "" {
+		if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+			return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+		}
+	}
+	userID := authUserID
+	if strings.ToLower(role) == "admin" {
+		if req.UserID == "" {
+			return customresponse.LambdaError(http.StatusBadRequest, "user_id is required when cancelling for a customer")
+		}
+		userID = req.UserID
+	}
+
+	if len(req.Seats) > 0 {
+		booking, err := bookingService.CancelSeats(ctx, userID, bookingID, req.Seats, authUserID)
+		if err != nil {
+			return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+		}
+		if booking.Status == models.BookingCancelled {
+			return customresponse.SendCustomResponse(http.StatusOK, "booking cancelled", booking)
+		}
+		return customresponse.SendCustomResponse(http.StatusOK, "seats cancelled", booking)
+	}
+
+	booking, err := bookingService.CancelBooking(ctx, userID, bookingID, authUserID)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "booking cancelled", booking)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/payments"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, authenticationmiddleware.RequireVerifiedEmail(ConfirmHold))))
+}
+
+type ConfirmHoldRequest struct {
+	UserID        string `json:"user_id,omitempty"`
+	PromoCode     string `json:"promo_code,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"`
+}
+
+func ConfirmHold(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	holdID := event.PathParameters["holdID"]
+	if holdID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "holdID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "customer" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only customers or admin for customers make booking")
+	}
+	authUserID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || authUserID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req ConfirmHoldRequest
+	if event.Body != "" {
+		if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+			return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+		}
+	}
+	userID := authUserID
+	if strings.ToLower(role) == "admin" && req.UserID != "" {
+		userID = req.UserID
+	}
+
+	booking, err := bookingService.ConfirmHold(ctx, userID, holdID, req.PromoCode, req.PaymentMethod)
+	if err != nil {
+		var conflict *models.SeatConflictError
+		if errors.As(err, &conflict) {
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		if errors.Is(err, models.ErrHoldExpired) {
+			return customresponse.LambdaError(http.StatusGone, err.Error())
+		}
+		if errors.Is(err, models.ErrPromoUnavailable) {
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, payments.ErrPaymentDeclined) || errors.Is(err, payments.ErrCaptureFailed) {
+			return customresponse.LambdaError(http.StatusPaymentRequired, err.Error())
+		}
+		if errors.Is(err, payments.ErrUnavailable) {
+			return customresponse.LambdaError(http.StatusServiceUnavailable, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "successfully created booking", booking)
+}
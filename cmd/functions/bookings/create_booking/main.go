@@ -3,14 +3,25 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/payments"
 	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
 	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
@@ -19,6 +30,9 @@ import (
 )
 
 var bookingService bookingservice.BookingServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -26,19 +40,35 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
 	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
 	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
-	bookingService = bookingservice.NewBookingService(bookingRepo, showRepo)
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(CreateBooking)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, authenticationmiddleware.RequireVerifiedEmail(idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateBooking)))))
 }
 
 type CreateBookingRequest struct {
 	UserID string   `json:"user_id,omitempty"`
 	ShowID string   `json:"show_id"`
 	Seats  []string `json:"seats"`
+
+	PromoCode     string `json:"promo_code,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"`
 }
 
 func CreateBooking(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -65,8 +95,21 @@ func CreateBooking(ctx context.Context, event events.APIGatewayProxyRequest) (ev
 		userID = req.UserID
 	}
 
-	booking, err := bookingService.AddBooking(ctx, userID, req.ShowID, req.Seats)
+	booking, err := bookingService.AddBooking(ctx, userID, req.ShowID, req.Seats, req.PromoCode, req.PaymentMethod)
 	if err != nil {
+		var conflict *models.SeatConflictError
+		if errors.As(err, &conflict) {
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		if errors.Is(err, models.ErrPromoUnavailable) {
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
+		if errors.Is(err, payments.ErrPaymentDeclined) || errors.Is(err, payments.ErrCaptureFailed) {
+			return customresponse.LambdaError(http.StatusPaymentRequired, err.Error())
+		}
+		if errors.Is(err, payments.ErrUnavailable) {
+			return customresponse.LambdaError(http.StatusServiceUnavailable, err.Error())
+		}
 		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
 	}
 
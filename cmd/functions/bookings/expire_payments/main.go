@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
+}
+
+func main() {
+	lambda.Start(ExpirePayments)
+}
+
+// ExpirePayments runs on a schedule to free the seats of bookings whose
+// payment never completed.
+func ExpirePayments(ctx context.Context) error {
+	return bookingService.ExpirePendingPayments(ctx)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
+	if minutes, err := strconv.Atoi(os.Getenv("SEAT_HOLD_MINUTES")); err == nil && minutes > 0 {
+		service.HoldDuration = time.Duration(minutes) * time.Minute
+	}
+	bookingService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, authenticationmiddleware.RequireVerifiedEmail(HoldSeats))))
+}
+
+type HoldSeatsRequest struct {
+	UserID string   `json:"user_id,omitempty"`
+	ShowID string   `json:"show_id"`
+	Seats  []string `json:"seats"`
+}
+
+func HoldSeats(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "customer" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only customers or admin for customers can hold seats")
+	}
+	authUserID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || authUserID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req HoldSeatsRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if req.ShowID == "" || len(req.Seats) == 0 {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request")
+	}
+	userID := authUserID
+	if strings.ToLower(role) == "admin" && req.UserID != "" {
+		userID = req.UserID
+	}
+
+	hold, err := bookingService.HoldSeats(ctx, userID, req.ShowID, req.Seats)
+	if err != nil {
+		var conflict *models.SeatConflictError
+		if errors.As(err, &conflict) {
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusCreated, "seats held", hold)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ReleaseHold)))
+}
+
+func ReleaseHold(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+
+	holdID := event.PathParameters["holdID"]
+	if holdID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "holdID is required")
+	}
+
+	role, _ := authenticationmiddleware.GetUserRole(ctx)
+	authUserID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || authUserID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	userID := authUserID
+	if strings.ToLower(role) == "admin" && event.QueryStringParameters["userID"] != "" {
+		userID = event.QueryStringParameters["userID"]
+	}
+
+	if err := bookingService.ReleaseHold(ctx, userID, holdID); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "hold released", nil)
+}
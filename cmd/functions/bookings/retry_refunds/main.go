@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	"eventro_aws/internals/payments"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var bookingService bookingservice.BookingServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	bookingService = bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+}
+
+func main() {
+	lambda.Start(RetryRefunds)
+}
+
+// RetryRefunds runs on a schedule to pay refunds of cancelled bookings that
+// failed when they were cancelled.
+func RetryRefunds(ctx context.Context) error {
+	return bookingService.RetryRefunds(ctx)
+}
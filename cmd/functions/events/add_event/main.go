@@ -6,8 +6,12 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
 	"eventro_aws/internals/models"
 	eventrepository "eventro_aws/internals/repository/event_repository"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	eventservice "eventro_aws/internals/services/event_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
@@ -19,6 +23,7 @@ import (
 )
 
 var eventService eventservice.EventServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
 
 type CreateEventRequest struct {
 	Name        string   `json:"name"`
@@ -29,18 +34,26 @@ type CreateEventRequest struct {
 	ArtistNames []string `json:"artist_names,omitempty"`
 }
 
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
 func init() {
 	ddb, err := db.InitDB()
 	if err != nil {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
 	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
 	eventService = eventservice.NewEventService(eventRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(CreateEvent)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateEvent))))
 }
 
 func CreateEvent(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	hostapplicationrepository "eventro_aws/internals/repository/host_application_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	hostapplicationservice "eventro_aws/internals/services/host_application_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var hostApplicationService hostapplicationservice.HostApplicationServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	hostApplicationRepo := hostapplicationrepository.NewHostApplicationRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	hostApplicationService = hostapplicationservice.NewHostApplicationService(hostApplicationRepo, userRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetHostApplication)))
+}
+
+// GetHostApplication returns the caller's host application and its outcome.
+func GetHostApplication(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	app, err := hostApplicationService.GetApplication(ctx, userEmail)
+	if err != nil {
+		if errors.Is(err, models.ErrHostApplicationNotFound) {
+			return customresponse.LambdaError(http.StatusNotFound, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "host application fetched", app)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/notifications"
+	hostapplicationrepository "eventro_aws/internals/repository/host_application_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	hostapplicationservice "eventro_aws/internals/services/host_application_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var hostApplicationService hostapplicationservice.HostApplicationServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	hostApplicationRepo := hostapplicationrepository.NewHostApplicationRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	hostApplicationService = hostapplicationservice.NewHostApplicationService(hostApplicationRepo, userRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ListHostApplications)))
+}
+
+// ListHostApplications returns the applications waiting for an admin,
+// oldest first.
+func ListHostApplications(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	apps, err := hostApplicationService.ListPending(ctx, role)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "host applications fetched", apps)
+}
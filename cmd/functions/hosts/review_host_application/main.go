@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	hostapplicationrepository "eventro_aws/internals/repository/host_application_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	hostapplicationservice "eventro_aws/internals/services/host_application_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var hostApplicationService hostapplicationservice.HostApplicationServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	hostApplicationRepo := hostapplicationrepository.NewHostApplicationRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	hostApplicationService = hostapplicationservice.NewHostApplicationService(hostApplicationRepo, userRepo, notifications.FromEnv())
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ReviewHostApplication)))
+}
+
+// ReviewHostApplication lets an admin approve or reject a user's pending
+// host application.
+func ReviewHostApplication(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	email := event.PathParameters["emailID"]
+	if email == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "emailID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	adminEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || adminEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var review models.HostApplicationReview
+	if err := json.Unmarshal([]byte(event.Body), &review); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	app, err := hostApplicationService.Review(ctx, adminEmail, role, email, review)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "forbidden"):
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrHostApplicationNotFound):
+			return customresponse.LambdaError(http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrHostApplicationNotPending):
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "host application "+app.Status, app)
+}
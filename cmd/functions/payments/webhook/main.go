@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/db"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/payments"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+const signatureHeader = "x-payment-signature"
+
+var bookingService bookingservice.BookingServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	service := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+	service.SeatReleaseListener = waitlistservice.NewWaitlistService(waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro"), showRepo, bookingRepo)
+	bookingService = service
+}
+
+// the provider authenticates itself with the body signature, so this
+// endpoint is not behind the JWT middleware
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(PaymentWebhook))
+}
+
+func PaymentWebhook(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var signature string
+	for k, v := range event.Headers {
+		if strings.ToLower(k) == signatureHeader {
+			signature = v
+			break
+		}
+	}
+	if signature == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "missing webhook signature")
+	}
+
+	err := bookingService.HandlePaymentWebhook(ctx, []byte(event.Body), signature)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			return customresponse.LambdaError(http.StatusUnauthorized, err.Error())
+		}
+		if errors.Is(err, payments.ErrUnavailable) {
+			return customresponse.LambdaError(http.StatusServiceUnavailable, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "webhook processed", nil)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	promoservice "eventro_aws/internals/services/promo_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var promoService promoservice.PromoServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	promoService = promoservice.NewPromoService(promoRepo, eventRepo, showRepo, venueRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, CreatePromo)))
+}
+
+func CreatePromo(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusForbidden, "unable to get user role")
+	}
+	role = strings.ToLower(role)
+	if role != "admin" && role != "host" {
+		return customresponse.LambdaError(http.StatusForbidden, "only admins and hosts can create promo codes")
+	}
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req models.PromoCode
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	promo, err := promoService.CreatePromo(ctx, userID, role, req)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusCreated, "created promo code successfully", promo)
+}
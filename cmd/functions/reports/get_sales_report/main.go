@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	reportrepository "eventro_aws/internals/repository/report_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	reportservice "eventro_aws/internals/services/report_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var reportService reportservice.ReportServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	reportRepo := reportrepository.NewReportRepositoryDDB(ddb, "eventro")
+	reportService = reportservice.NewReportService(reportRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetSalesReport)))
+}
+
+// GetSalesReport returns the host's sales and occupancy per show with totals
+// by event and venue. Admins pick the host with ?hostID=.
+func GetSalesReport(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	if r := strings.ToLower(role); r != "host" && r != "admin" {
+		return customresponse.LambdaError(http.StatusForbidden, "only hosts and admins can view sales reports")
+	}
+
+	query := event.QueryStringParameters
+	filter := reportservice.ReportFilter{
+		EventID: query["eventID"],
+		VenueID: query["venueID"],
+		From:    query["from"],
+		To:      query["to"],
+	}
+
+	report, err := reportService.GetHostReport(ctx, userID, role, query["hostID"], filter)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "sales report fetched", report)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/payments"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	showservice "eventro_aws/internals/services/show_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var showService showservice.ShowServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	paymentProvider, err := payments.FromEnv()
+	if err != nil {
+		// everything but taking and returning money still works
+		log.Printf("Payments unavailable: %v", err)
+	}
+	bookingService := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, paymentProvider)
+	showService = showservice.NewShowService(showRepo, venueRepo, eventRepo, bookingService)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, CancelSeries)))
+}
+
+type CancelSeriesRequest struct {
+	Reason string `json:"reason"`
+	ShowID string `json:"show_id,omitempty"`
+}
+
+// CancelSeries cancels every upcoming show of the series, or only show_id.
+func CancelSeries(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	seriesID := event.PathParameters["seriesID"]
+	if seriesID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "seriesID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "host" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only hosts and admins can cancel show series")
+	}
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req CancelSeriesRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	summaries, err := showService.CancelSeries(ctx, seriesID, userID, role, req.ShowID, req.Reason)
+	if err != nil {
+		if summaries != nil {
+			return customresponse.SendCustomResponse(http.StatusInternalServerError, err.Error(), summaries)
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "series cancelled", summaries)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
+	"eventro_aws/internals/models"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	showservice "eventro_aws/internals/services/show_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+type CreateSeriesRequest struct {
+	models.SeriesSpec
+	SkipConflicts bool `json:"skip_conflicts"`
+}
+
+var showService showservice.ShowServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	service := showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
+	if minutes, err := strconv.Atoi(os.Getenv("SHOW_CHANGEOVER_MINUTES")); err == nil && minutes >= 0 {
+		service.ChangeoverBuffer = time.Duration(minutes) * time.Minute
+	}
+	showService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateSeries))))
+}
+
+// CreateSeries creates a recurring show series, or with ?preview=true only
+// lists the occurrences it would create and their conflicts.
+func CreateSeries(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(403, "unable to get role")
+	}
+	if strings.ToLower(role) != "host" {
+		return customresponse.LambdaError(403, "only hosts can create show series")
+	}
+
+	var req CreateSeriesRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if preview, _ := strconv.ParseBool(event.QueryStringParameters["preview"]); preview {
+		result, err := showService.PreviewSeries(ctx, userID, req.SeriesSpec)
+		if err != nil {
+			return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+		}
+		return customresponse.SendCustomResponse(http.StatusOK, "series preview", result)
+	}
+
+	series, err := showService.CreateSeries(ctx, userID, req.SeriesSpec, req.SkipConflicts)
+	if err != nil {
+		var conflict *models.ShowConflictError
+		if errors.As(err, &conflict) {
+			if series != nil {
+				return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), series)
+			}
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		if errors.Is(err, models.ErrScheduleChanged) {
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
+		if series != nil {
+			return customresponse.SendCustomResponse(http.StatusInternalServerError, err.Error(), series)
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "series created", series)
+}
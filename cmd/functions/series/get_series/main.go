@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	showservice "eventro_aws/internals/services/show_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var showService showservice.ShowServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	showService = showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetSeries)))
+}
+
+func GetSeries(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	seriesID := event.PathParameters["seriesID"]
+	if seriesID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "seriesID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "host" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only hosts and admins can view show series")
+	}
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	series, err := showService.GetSeries(ctx, seriesID, userID, role)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "series fetched", series)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	showservice "eventro_aws/internals/services/show_service"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var showService showservice.ShowServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	showService = showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
+}
+
+func main() {
+	lambda.Start(BackfillVenueSlots)
+}
+
+// BackfillVenueSlots runs on a schedule until every upcoming show created
+// before venues kept a schedule is on it; after that each run returns at
+// once.
+func BackfillVenueSlots(ctx context.Context) error {
+	written, err := showService.BackfillVenueSlots(ctx)
+	log.Printf("added %d shows to their venue's schedule", written)
+	return err
+}
@@ -5,7 +5,11 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
 	showservice "eventro_aws/internals/services/show_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
@@ -17,6 +21,8 @@ import (
 )
 
 var showService showservice.ShowServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -24,12 +30,17 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
 	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
-	showService = showservice.NewShowService(showRepo)
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	showService = showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(BrowseShows)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, BrowseShows)))
 }
 
 func BrowseShows(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -3,14 +3,24 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
+	"eventro_aws/internals/models"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
 	showservice "eventro_aws/internals/services/show_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,9 +34,15 @@ type CreateShowRequest struct {
 	Price    float64 `json:"price"`
 	ShowDate string  `json:"show_date"`
 	ShowTime string  `json:"show_time"`
+
+	CancellationPolicy *models.CancellationPolicy `json:"cancellation_policy,omitempty"`
+	PriceTiers         []models.PriceTier         `json:"price_tiers,omitempty"`
 }
 
 var showService showservice.ShowServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -34,12 +50,23 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
 	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
-	showService = showservice.NewShowService(showRepo)
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	service := showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
+	if minutes, err := strconv.Atoi(os.Getenv("SHOW_CHANGEOVER_MINUTES")); err == nil && minutes >= 0 {
+		service.ChangeoverBuffer = time.Duration(minutes) * time.Minute
+	}
+	showService = service
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(CreateShow)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateShow))))
 }
 
 func CreateShow(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -76,8 +103,17 @@ func CreateShow(ctx context.Context, event events.APIGatewayProxyRequest) (event
 		req.Price,
 		parsedDate,
 		req.ShowTime,
+		req.CancellationPolicy,
+		req.PriceTiers,
 	)
 	if err != nil {
+		var conflict *models.ShowConflictError
+		if errors.As(err, &conflict) {
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		if errors.Is(err, models.ErrScheduleChanged) {
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
 		return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	ticketrepository "eventro_aws/internals/repository/ticket_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	attendeeservice "eventro_aws/internals/services/attendee_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var attendeeService attendeeservice.AttendeeServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	ticketRepo := ticketrepository.NewTicketRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	attendeeService = attendeeservice.NewAttendeeService(bookingRepo, showRepo, ticketRepo, userRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ExportAttendees)))
+}
+
+// ExportAttendees returns the show's door list as JSON, or as a CSV download
+// with ?format=csv.
+func ExportAttendees(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	showID := event.PathParameters["showID"]
+	if showID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "showID is required")
+	}
+
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+	if r := strings.ToLower(role); r != "host" && r != "admin" {
+		return customresponse.LambdaError(http.StatusForbidden, "only the show's host or an admin can view attendees")
+	}
+
+	switch strings.ToLower(event.QueryStringParameters["format"]) {
+	case "", "json":
+		list, err := attendeeService.ListAttendees(ctx, userID, role, showID)
+		if err != nil {
+			return attendeeError(err)
+		}
+		return customresponse.SendCustomResponse(http.StatusOK, "attendees fetched", list)
+	case "csv":
+		data, err := attendeeService.ExportCSV(ctx, userID, role, showID)
+		if err != nil {
+			return attendeeError(err)
+		}
+		return customresponse.SendBinaryResponse(http.StatusOK, "text/csv", "attendees-"+showID+".csv", data)
+	default:
+		return customresponse.LambdaError(http.StatusBadRequest, "format must be json or csv")
+	}
+}
+
+func attendeeError(err error) (events.APIGatewayProxyResponse, error) {
+	if strings.HasPrefix(err.Error(), "forbidden") {
+		return customresponse.LambdaError(http.StatusForbidden, err.Error())
+	}
+	return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	bookingservice "eventro_aws/internals/services/booking_service"
+	showservice "eventro_aws/internals/services/show_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var showService showservice.ShowServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	promoRepo := promorepository.NewPromoRepositoryDDB(ddb, "eventro")
+	bookingService := bookingservice.NewBookingService(bookingRepo, showRepo, promoRepo, nil)
+	service := showservice.NewShowService(showRepo, venueRepo, eventRepo, bookingService)
+	if minutes, err := strconv.Atoi(os.Getenv("SHOW_CHANGEOVER_MINUTES")); err == nil && minutes >= 0 {
+		service.ChangeoverBuffer = time.Duration(minutes) * time.Minute
+	}
+	showService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, RescheduleShow)))
+}
+
+type RescheduleShowRequest struct {
+	ShowDate string `json:"show_date"`
+	ShowTime string `json:"show_time"`
+}
+
+func RescheduleShow(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	showID := event.PathParameters["showID"]
+	if showID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "showID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || (strings.ToLower(role) != "host" && strings.ToLower(role) != "admin") {
+		return customresponse.LambdaError(http.StatusForbidden, "only hosts and admins can reschedule shows")
+	}
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req RescheduleShowRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	parsedDate, err := time.Parse("2006-01-02", req.ShowDate)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+	}
+
+	show, err := showService.RescheduleShow(ctx, showID, userID, role, parsedDate, req.ShowTime)
+	if err != nil {
+		var conflict *models.ShowConflictError
+		if errors.As(err, &conflict) {
+			return customresponse.SendCustomResponse(http.StatusConflict, conflict.Error(), conflict)
+		}
+		if errors.Is(err, models.ErrScheduleChanged) {
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "show rescheduled", show)
+}
@@ -6,11 +6,16 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
 	showservice "eventro_aws/internals/services/show_service"
 	customresponse "eventro_aws/internals/utils"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -21,6 +26,8 @@ type UpdateShowRequest struct {
 }
 
 var showService showservice.ShowServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -28,30 +35,39 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
 	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
-	showService = showservice.NewShowService(showRepo)
+	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
+	eventRepo := eventrepository.NewEventRepoDDB(ddb, "eventro")
+	showService = showservice.NewShowService(showRepo, venueRepo, eventRepo, nil)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(UpdateShow)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, UpdateShow)))
 }
 
 func UpdateShow(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 
 	showID := event.PathParameters["showID"]
 
-	userID, err := authenticationmiddleware.GetUserID(ctx)
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
 	if err != nil || userID == "" {
 		return customresponse.LambdaError(http.StatusUnauthorized, "user unauthorised")
 	}
+	role, _ := authenticationmiddleware.GetUserRole(ctx)
 
 	var req UpdateShowRequest
 	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
 		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
 	}
 
-	err = showService.UpdateShow(ctx, showID, userID, req.IsBlocked)
+	err = showService.UpdateShow(ctx, showID, userID, role, req.IsBlocked)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		}
 		return customresponse.LambdaError(http.StatusInternalServerError, "Failed to update show: "+err.Error())
 	}
 
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	ticketrepository "eventro_aws/internals/repository/ticket_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	ticketservice "eventro_aws/internals/services/ticket_service"
+	"eventro_aws/internals/tickets"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var ticketService ticketservice.TicketServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	signer, err := tickets.NewSigner(os.Getenv("TICKET_SIGNING_KEY"))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load ticket signing key: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	ticketRepo := ticketrepository.NewTicketRepositoryDDB(ddb, "eventro")
+	ticketService = ticketservice.NewTicketService(bookingRepo, showRepo, ticketRepo, signer)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetManifest)))
+}
+
+// GetManifest returns the signed ticket manifest door staff download before
+// a show to validate tickets offline.
+func GetManifest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	showID := event.PathParameters["showID"]
+	if showID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "showID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || strings.ToLower(role) != "host" {
+		return customresponse.LambdaError(http.StatusForbidden, "only hosts can download ticket manifests")
+	}
+	hostID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || hostID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	manifest, err := ticketService.GetManifest(ctx, hostID, showID)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "manifest generated", manifest)
+}
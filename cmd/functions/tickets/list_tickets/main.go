@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	ticketrepository "eventro_aws/internals/repository/ticket_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	ticketservice "eventro_aws/internals/services/ticket_service"
+	"eventro_aws/internals/tickets"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var ticketService ticketservice.TicketServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	signer, err := tickets.NewSigner(os.Getenv("TICKET_SIGNING_KEY"))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load ticket signing key: %v", err))
+	}
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	ticketRepo := ticketrepository.NewTicketRepositoryDDB(ddb, "eventro")
+	ticketService = ticketservice.NewTicketService(bookingRepo, showRepo, ticketRepo, signer)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ListTickets)))
+}
+
+func ListTickets(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	bookingID := event.PathParameters["bookingID"]
+	if bookingID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "bookingID is required")
+	}
+
+	role, _ := authenticationmiddleware.GetUserRole(ctx)
+	authUserID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || authUserID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	userID := authUserID
+	if strings.ToLower(role) == "admin" && event.QueryStringParameters["userID"] != "" {
+		userID = event.QueryStringParameters["userID"]
+	}
+
+	issued, err := ticketService.GetTickets(ctx, userID, bookingID)
+	if err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "tickets issued", issued)
+}
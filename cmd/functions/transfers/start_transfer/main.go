@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	transferservice "eventro_aws/internals/services/transfer_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var transferService transferservice.TransferServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	service := transferservice.NewTransferService(bookingRepo, showRepo, userRepo)
+	if hours, err := strconv.Atoi(os.Getenv("TRANSFER_CUTOFF_HOURS")); err == nil && hours >= 0 {
+		service.Cutoff = time.Duration(hours) * time.Hour
+	}
+	transferService = service
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, StartTransfer)))
+}
+
+type StartTransferRequest struct {
+	ToEmail string   `json:"to_email"`
+	Seats   []string `json:"seats,omitempty"`
+}
+
+func StartTransfer(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	bookingID := event.PathParameters["bookingID"]
+	if bookingID == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "bookingID is required")
+	}
+
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req StartTransferRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	transfer, err := transferService.StartTransfer(ctx, userID, bookingID, req.ToEmail, req.Seats)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTransferNotFound):
+			return customresponse.LambdaError(http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrTransferNotPending):
+			return customresponse.LambdaError(http.StatusConflict, err.Error())
+		case errors.Is(err, models.ErrTransferWindowClosed):
+			return customresponse.LambdaError(http.StatusUnprocessableEntity, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusCreated, "transfer started", transfer)
+}
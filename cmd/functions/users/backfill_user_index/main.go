@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/userservice"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var userService userservice.UserServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	userService = userservice.NewUserService(userRepo)
+}
+
+func main() {
+	lambda.Start(BackfillUserIndex)
+}
+
+// BackfillUserIndex runs on a schedule until every user who signed up before
+// the user listing index is in it; after that each run returns at once.
+// Until it has finished, listing users falls back to scanning.
+func BackfillUserIndex(ctx context.Context) error {
+	written, err := userService.BackfillUserIndex(ctx)
+	log.Printf("indexed %d users", written)
+	return err
+}
@@ -5,6 +5,7 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	userrepository "eventro_aws/internals/repository/user_repository"
 	"eventro_aws/internals/services/userservice"
 	customresponse "eventro_aws/internals/utils"
@@ -15,6 +16,8 @@ import (
 )
 
 var userService userservice.UserServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -22,12 +25,14 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
-	userRepo := userrepository.NewUserRepoDDB(ddb, "eventro")
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
 	userService = userservice.NewUserService(userRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(GetUserByID)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetUserByID)))
 }
 
 func GetUserByID(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
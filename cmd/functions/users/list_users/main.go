@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/userservice"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var userService userservice.UserServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	userService = userservice.NewUserService(userRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, ListUsers)))
+}
+
+// ListUsers pages through accounts for admins. ?q= searches usernames and
+// emails, ?role= filters by role and ?cursor= continues from a previous page.
+func ListUsers(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || role != "admin" {
+		return customresponse.LambdaError(http.StatusForbidden, "only admins can list users")
+	}
+
+	query := event.QueryStringParameters
+	limit := 0
+	if l := query["limit"]; l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			return customresponse.LambdaError(http.StatusBadRequest, "limit must be a positive number")
+		}
+	}
+
+	page, err := userService.ListUsers(ctx, role, query["q"], query["role"], limit, query["cursor"])
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "forbidden") {
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "users fetched", page)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"eventro_aws/internals/services/userservice"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var userService userservice.UserServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+	userService = userservice.NewUserService(userRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, UpdateUser)))
+}
+
+// UpdateUser lets an admin block or unblock a user or change their role.
+// The user's existing tokens stop working either way.
+func UpdateUser(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	email := event.PathParameters["emailID"]
+	if email == "" {
+		return customresponse.LambdaError(http.StatusBadRequest, "emailID is required")
+	}
+
+	role, err := authenticationmiddleware.GetUserRole(ctx)
+	if err != nil || role != "admin" {
+		return customresponse.LambdaError(http.StatusForbidden, "only admins can update users")
+	}
+	adminEmail, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || adminEmail == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	var req models.UpdateUserRequest
+	if err := json.Unmarshal([]byte(event.Body), &req); err != nil {
+		return customresponse.LambdaError(http.StatusBadRequest, "invalid request body")
+	}
+
+	user, err := userService.UpdateUser(ctx, adminEmail, role, email, req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "forbidden"):
+			return customresponse.LambdaError(http.StatusForbidden, err.Error())
+		case errors.Is(err, userrepository.ErrUserNotFound):
+			return customresponse.LambdaError(http.StatusNotFound, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusBadRequest, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "user updated", user)
+}
@@ -5,6 +5,9 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	venuerepository "eventro_aws/internals/repository/venue_repository"
 	venueservice "eventro_aws/internals/services/venue_service"
 	customresponse "eventro_aws/internals/utils"
@@ -16,6 +19,8 @@ import (
 )
 
 var venueService venueservice.VenueServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -23,12 +28,16 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
 	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
-	venueService = venueservice.NewVenueService(venueRepo)
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueService = venueservice.NewVenueService(venueRepo, showRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(BrowseVenues)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, BrowseVenues)))
 }
 
 func BrowseVenues(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
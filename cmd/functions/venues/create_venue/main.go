@@ -6,6 +6,12 @@ import (
 	"eventro_aws/db"
 	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
 	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	idempotencymiddleware "eventro_aws/internals/middleware/idempotency_middleware"
+	"eventro_aws/internals/models"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	venuerepository "eventro_aws/internals/repository/venue_repository"
 	venueservice "eventro_aws/internals/services/venue_service"
 	customresponse "eventro_aws/internals/utils"
@@ -22,9 +28,14 @@ type CreateVenueRequest struct {
 	City                 string `json:"city"`
 	State                string `json:"state"`
 	IsSeatLayoutRequired bool   `json:"is_seat_layout_required"`
+
+	SeatLayout *models.SeatLayout `json:"seat_layout,omitempty"`
 }
 
 var venueService venueservice.VenueServiceI
+var idempotencyRepo idempotencyrepository.IdempotencyRepositoryI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
 
 func init() {
 	ddb, err := db.InitDB()
@@ -32,12 +43,18 @@ func init() {
 		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
 	}
 
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	idempotencyRepo = idempotencyrepository.NewIdempotencyRepositoryDDB(ddb, "eventro")
+
 	venueRepo := venuerepository.NewVenueRepositoryDDB(ddb, "eventro")
-	venueService = venueservice.NewVenueService(venueRepo)
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	venueService = venueservice.NewVenueService(venueRepo, showRepo)
 }
 
 func main() {
-	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(CreateVenue)))
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, idempotencymiddleware.WithIdempotency(idempotencyRepo, CreateVenue))))
 }
 
 func CreateVenue(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -66,6 +83,7 @@ func CreateVenue(ctx context.Context, event events.APIGatewayProxyRequest) (even
 		req.City,
 		req.State,
 		req.IsSeatLayoutRequired,
+		req.SeatLayout,
 	)
 	if err != nil {
 		return customresponse.LambdaError(500, err.Error())
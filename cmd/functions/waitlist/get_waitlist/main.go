@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/db"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	corsmiddleware "eventro_aws/internals/middleware/cors_middleware"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	customresponse "eventro_aws/internals/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var waitlistService waitlistservice.WaitlistServiceI
+var sessionRepo sessionrepository.SessionRepositoryI
+var userRepo userrepository.UserRepositoryI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	sessionRepo = sessionrepository.NewSessionRepositoryDDB(ddb, "eventro")
+	userRepo = userrepository.NewUserRepoDDB(ddb, "eventro")
+
+	waitlistRepo := waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	waitlistService = waitlistservice.NewWaitlistService(waitlistRepo, showRepo, bookingRepo)
+}
+
+func main() {
+	lambda.Start(corsmiddleware.WithCORS(authenticationmiddleware.AuthorizedInvoke(sessionRepo, userRepo, GetWaitlist)))
+}
+
+// GetWaitlist returns the caller's place on one show's waitlist, or with no
+// showID every waitlist they are on.
+func GetWaitlist(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	userID, err := authenticationmiddleware.GetUserEmail(ctx)
+	if err != nil || userID == "" {
+		return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+	}
+
+	showID := event.QueryStringParameters["showID"]
+	if showID == "" {
+		entries, err := waitlistService.ListForUser(ctx, userID)
+		if err != nil {
+			return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
+		}
+		return customresponse.SendCustomResponse(http.StatusOK, "waitlists fetched", entries)
+	}
+
+	entry, err := waitlistService.GetPosition(ctx, userID, showID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotWaitlisted) {
+			return customresponse.LambdaError(http.StatusNotFound, err.Error())
+		}
+		return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
+	}
+
+	return customresponse.SendCustomResponse(http.StatusOK, "waitlist position fetched", entry)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"eventro_aws/db"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	waitlistservice "eventro_aws/internals/services/waitlist_service"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+var waitlistService waitlistservice.WaitlistServiceI
+
+func init() {
+	ddb, err := db.InitDB()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize DB: %v", err))
+	}
+
+	waitlistRepo := waitlistrepository.NewWaitlistRepositoryDDB(ddb, "eventro")
+	showRepo := showrepository.NewShowRepositoryDDB(ddb, "eventro")
+	bookingRepo := bookingrepository.NewBookingRepositoryDDB(ddb, "eventro")
+	waitlistService = waitlistservice.NewWaitlistService(waitlistRepo, showRepo, bookingRepo)
+}
+
+func main() {
+	lambda.Start(ProcessWaitlists)
+}
+
+// ProcessWaitlists runs on a schedule to offer seats freed by expired holds,
+// which no request would otherwise notice.
+func ProcessWaitlists(ctx context.Context) error {
+	return waitlistService.ProcessAll(ctx)
+}
@@ -3,9 +3,13 @@ package authenticationmiddleware
 import (
 	"context"
 	"errors"
+	"eventro_aws/internals/models"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
 	"eventro_aws/internals/services/authorisation"
 	customresponse "eventro_aws/internals/utils"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -16,9 +20,18 @@ const (
 	ContextUserIDKey    contextKey = "userID"
 	ContextUserEmailKey contextKey = "userEmail"
 	ContextUserRoleKey  contextKey = "userRole"
+	ContextSessionIDKey contextKey = "sessionID"
+
+	ContextEmailVerifiedKey contextKey = "emailVerified"
 )
 
-func AuthorizedInvoke(fn func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)) func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+// AuthorizedInvoke runs fn for callers with a valid access token whose
+// session, looked up in sessions and users, is still live.
+func AuthorizedInvoke(
+	sessions sessionrepository.SessionRepositoryI,
+	users userrepository.UserRepositoryI,
+	fn func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error),
+) func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 		authHeader := req.Headers["Authorization"]
 		if authHeader == "" {
@@ -39,14 +52,59 @@ func AuthorizedInvoke(fn func(ctx context.Context, req events.APIGatewayProxyReq
 		if err != nil {
 			return customresponse.LambdaError(401, "Unauthorized: "+err.Error())
 		}
+		user, err := checkSession(ctx, sessions, users, claims)
+		if err != nil {
+			return customresponse.LambdaError(401, "Unauthorized: "+err.Error())
+		}
 		authCtx := context.WithValue(ctx, ContextUserIDKey, claims.UserID)
 		authCtx = context.WithValue(authCtx, ContextUserEmailKey, claims.Email)
 		authCtx = context.WithValue(authCtx, ContextUserRoleKey, claims.Role)
+		authCtx = context.WithValue(authCtx, ContextSessionIDKey, claims.SessionID)
+		authCtx = context.WithValue(authCtx, ContextEmailVerifiedKey, user.IsEmailVerified())
 
 		return fn(authCtx, req)
 	}
 }
 
+// checkSession rejects tokens whose session was logged out or revoked, and
+// tokens of users who have since been blocked or whose role or access
+// changed after the token was issued. It returns the token's user.
+func checkSession(ctx context.Context, sessions sessionrepository.SessionRepositoryI, users userrepository.UserRepositoryI, claims *authorisation.Claims) (*models.User, error) {
+	if claims.SessionID == "" {
+		return nil, errors.New("token has been revoked, please log in again")
+	}
+	session, err := sessions.Get(ctx, claims.SessionID)
+	if err != nil {
+		return nil, errors.New("cannot verify session")
+	}
+	if session == nil || !session.Active(time.Now()) || session.UserEmail != claims.Email {
+		return nil, errors.New("session has ended, please log in again")
+	}
+
+	user, err := users.GetByEmail(claims.Email)
+	if err != nil {
+		return nil, errors.New("cannot verify session")
+	}
+	if user.IsBlocked {
+		return nil, errors.New("user account is blocked")
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, errors.New("token has been revoked, please log in again")
+	}
+	return user, nil
+}
+
+// RequireVerifiedEmail wraps a handler, inside AuthorizedInvoke, that only
+// users with a verified email address may call.
+func RequireVerifiedEmail(fn func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)) func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		if verified, _ := ctx.Value(ContextEmailVerifiedKey).(bool); !verified {
+			return customresponse.LambdaError(403, models.ErrEmailNotVerified.Error())
+		}
+		return fn(ctx, req)
+	}
+}
+
 func GetUserID(ctx context.Context) (string, error) {
 	userID, ok := ctx.Value(ContextUserIDKey).(string)
 	if !ok {
@@ -63,6 +121,14 @@ func GetUserEmail(ctx context.Context) (string, error) {
 	return email, nil
 }
 
+func GetSessionID(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value(ContextSessionIDKey).(string)
+	if !ok {
+		return "", errors.New("sessionID not found in context")
+	}
+	return sessionID, nil
+}
+
 func GetUserRole(ctx context.Context) (string, error) {
 	role, ok := ctx.Value(ContextUserRoleKey).(string)
 	if !ok {
@@ -8,7 +8,7 @@ import (
 
 var defaultHeaders = map[string]string{
 	"Access-Control-Allow-Origin":  "*",
-	"Access-Control-Allow-Headers": "Content-Type,Authorization",
+	"Access-Control-Allow-Headers": "Content-Type,Authorization,Idempotency-Key",
 	"Access-Control-Allow-Methods": "OPTIONS,GET,POST,PUT,PATCH,DELETE",
 }
 
@@ -0,0 +1,125 @@
+package idempotencymiddleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	"eventro_aws/internals/models"
+	idempotencyrepository "eventro_aws/internals/repository/idempotency_repository"
+	customresponse "eventro_aws/internals/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	HeaderName     = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// RecordTTL is how long a key and its response are kept.
+	RecordTTL = 24 * time.Hour
+
+	// LockTimeout is how long a request holds its key before a retry may take
+	// it over. It is longer than the functions' 200s timeout, so a request
+	// that is still running is never run twice.
+	LockTimeout = 5 * time.Minute
+
+	maxKeyLength = 255
+)
+
+// WithIdempotency makes fn safe to retry. A request carrying an
+// Idempotency-Key runs fn once; later requests with the same key and body get
+// the stored response back, and the same key with a different body is
+// rejected. Requests without the header are passed straight through. It must
+// run inside AuthorizedInvoke, since keys are scoped to the caller.
+func WithIdempotency(
+	repo idempotencyrepository.IdempotencyRepositoryI,
+	fn func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error),
+) func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		key := headerValue(req.Headers, HeaderName)
+		if key == "" {
+			return fn(ctx, req)
+		}
+		if len(key) > maxKeyLength {
+			return customresponse.LambdaError(http.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		userID, err := authenticationmiddleware.GetUserEmail(ctx)
+		if err != nil || userID == "" {
+			return customresponse.LambdaError(http.StatusUnauthorized, "not authorised")
+		}
+
+		now := time.Now()
+		record := &models.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint(req),
+			LockedUntil: now.Add(LockTimeout),
+			ExpiresAt:   now.Add(RecordTTL),
+		}
+
+		existing, err := repo.Reserve(ctx, record)
+		if err != nil {
+			return customresponse.LambdaError(http.StatusInternalServerError, err.Error())
+		}
+		if existing != nil {
+			return replay(existing, record.Fingerprint)
+		}
+
+		res, err := fn(ctx, req)
+
+		// server errors are not remembered so the client can retry them
+		if err != nil || res.StatusCode >= http.StatusInternalServerError {
+			_ = repo.Delete(ctx, userID, key)
+			return res, err
+		}
+
+		record.StatusCode = res.StatusCode
+		record.Body = res.Body
+		record.Headers = res.Headers
+		if cerr := repo.Complete(ctx, record); cerr != nil {
+			_ = repo.Delete(ctx, userID, key)
+		}
+		return res, nil
+	}
+}
+
+func replay(existing *models.IdempotencyRecord, fp string) (events.APIGatewayProxyResponse, error) {
+	if existing.Fingerprint != fp {
+		return customresponse.LambdaError(http.StatusConflict, "Idempotency-Key was already used with a different request")
+	}
+	if existing.Status != models.IdempotencyCompleted {
+		return customresponse.LambdaError(http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+	}
+
+	headers := make(map[string]string, len(existing.Headers)+1)
+	for k, v := range existing.Headers {
+		headers[k] = v
+	}
+	headers[ReplayedHeader] = "true"
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: existing.StatusCode,
+		Body:       existing.Body,
+		Headers:    headers,
+	}, nil
+}
+
+// fingerprint identifies the request a key was first used with.
+func fingerprint(req events.APIGatewayProxyRequest) string {
+	sum := sha256.Sum256([]byte(req.HTTPMethod + "\n" + req.Path + "\n" + req.Body))
+	return hex.EncodeToString(sum[:])
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package models
+
+const (
+	CheckInNone    = "not_checked_in"
+	CheckInPartial = "partially_checked_in"
+	CheckInAll     = "checked_in"
+)
+
+// Attendee is one booking on a show's door list. CheckedInSeats are the
+// booking's seats already admitted at the door.
+type Attendee struct {
+	BookingID      string   `json:"booking_id"`
+	Name           string   `json:"name"`
+	Email          string   `json:"email"`
+	Seats          []string `json:"seats"`
+	BookedAt       string   `json:"booked_at"`
+	Status         string   `json:"status"`
+	CheckInStatus  string   `json:"check_in_status"`
+	CheckedInSeats []string `json:"checked_in_seats"`
+}
+
+type AttendeeList struct {
+	ShowID       string     `json:"show_id"`
+	ShowDateTime string     `json:"show_date_time"`
+	Attendees    []Attendee `json:"attendees"`
+}
@@ -6,6 +6,14 @@ import (
 	"github.com/lib/pq"
 )
 
+const (
+	BookingPendingPayment = "pending_payment"
+	BookingConfirmed      = "confirmed"
+	BookingFailed         = "failed"
+	BookingCancelled      = "cancelled"
+	BookingTransferred    = "transferred"
+)
+
 type Booking struct {
 	BookingID         string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 	UserID            string         `gorm:"type:uuid;not null"`
@@ -16,9 +24,38 @@ type Booking struct {
 	NumTickets        int            `gorm:"not null"`
 	TotalBookingPrice float64        `gorm:"not null"`
 	Seats             pq.StringArray `gorm:"type:text[]"`
+	HoldID            string         `gorm:"-"`
+	LineItems         []SeatLineItem `gorm:"-"`
+	Promo             *PromoCode     `gorm:"-"`
+	OriginalPrice     float64        `gorm:"-"`
+	Discount          float64        `gorm:"-"`
+	Status            string         `gorm:"-"`
+	BookingDate       string         `gorm:"-"`
+	PaymentID         string         `gorm:"-"`
+	PaymentDueAt      time.Time      `gorm:"-"`
 }
 
+// PendingRefund is money owed back on a cancellation that the payment
+// gateway has not confirmed yet. It is recorded with the cancellation and
+// removed once the refund has gone through. RetryAt is when it may be
+// retried if the attempt in progress has not finished by then.
+type PendingRefund struct {
+	RefundID  string
+	UserID    string
+	BookingID string
+	ShowID    string
+	PaymentID string
+	Amount    float64
+	RetryAt   time.Time
+}
+
+// ShowBooking is a booking found through its show, along with the user it
+// belongs to.
+type ShowBooking struct {
+	UserID  string
+	Booking UserBookingDTO
+}
 
 // trigger showid search, you get venue id and event id, along with others, trigger venue id and event id search to get other fields
 //update show booked seats
-// successful creation of booking
\ No newline at end of file
+// successful creation of booking
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// RefundTier refunds RefundPercent of the booking when it is cancelled at
+// least HoursBefore hours before the show starts.
+type RefundTier struct {
+	HoursBefore   int     `json:"hours_before" dynamodbav:"hours_before"`
+	RefundPercent float64 `json:"refund_percent" dynamodbav:"refund_percent"`
+}
+
+type CancellationPolicy struct {
+	Tiers []RefundTier `json:"tiers" dynamodbav:"tiers"`
+}
+
+// DefaultCancellationPolicy gives a full refund until 48h before the show
+// and 50% after that.
+func DefaultCancellationPolicy() CancellationPolicy {
+	return CancellationPolicy{
+		Tiers: []RefundTier{
+			{HoursBefore: 48, RefundPercent: 100},
+			{HoursBefore: 0, RefundPercent: 50},
+		},
+	}
+}
+
+func (p CancellationPolicy) Validate() error {
+	if len(p.Tiers) == 0 {
+		return errors.New("cancellation policy needs at least one tier")
+	}
+	for _, t := range p.Tiers {
+		if t.HoursBefore < 0 {
+			return errors.New("hours_before cannot be negative")
+		}
+		if t.RefundPercent < 0 || t.RefundPercent > 100 {
+			return errors.New("refund_percent must be between 0 and 100")
+		}
+	}
+	return nil
+}
+
+// RefundPercent returns the percentage refunded for a cancellation at now.
+// The tier with the largest HoursBefore that still applies wins; nothing is
+// refunded once the show has started.
+func (p CancellationPolicy) RefundPercent(showStart, now time.Time) float64 {
+	if !now.Before(showStart) {
+		return 0
+	}
+
+	tiers := append([]RefundTier(nil), p.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].HoursBefore > tiers[j].HoursBefore })
+
+	hoursLeft := showStart.Sub(now).Hours()
+	for _, t := range tiers {
+		if hoursLeft >= float64(t.HoursBefore) {
+			return t.RefundPercent
+		}
+	}
+	return 0
+}
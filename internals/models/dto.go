@@ -5,8 +5,25 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// LoginResponse carries a short-lived access token and the refresh token
+// that replaces it; ExpiresIn is the access token's lifetime in seconds.
+// Users with two-factor authentication first get only a ChallengeToken,
+// which is exchanged for the tokens along with a code. RecoveryCodes are
+// returned once, when 2FA is switched on.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+
+	TwoFactorRequired      bool     `json:"two_factor_required,omitempty"`
+	TwoFactorSetupRequired bool     `json:"two_factor_setup_required,omitempty"`
+	ChallengeToken         string   `json:"challenge_token,omitempty"`
+	RecoveryCodes          []string `json:"recovery_codes,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type UserDTO struct {
@@ -22,9 +39,7 @@ type SignupRequest struct {
 	Password    string `json:"password"`
 }
 
-type SignupResponse struct {
-	Token string `json:"token"`
-}
+type SignupResponse = LoginResponse
 
 type ArtistDTO struct {
 	Name     string `dynamodbav:"sk"`
@@ -33,20 +48,41 @@ type ArtistDTO struct {
 }
 
 type UserBookingDTO struct {
-	UserEmail        string   `json:"user_email"`
-	BookingDate      string   `json:"booking_date"`
-	BookingID        string   `json:"booking_id"`
-	ShowID           string   `json:"show_id"`
-	TimeBooked       string   `json:"time_booked"`
-	NumTicketsBooked int      `json:"num_tickets_booked"`
-	TotalPrice       float64  `json:"total_price"`
-	Seats            []string `json:"seats"`
-	VenueCity        string   `json:"venue_city"`
-	VenueName        string   `json:"venue_name"`
-	VenueState       string   `json:"venue_state"`
-	EventName        string   `json:"event_name"`
-	EventDuration    string   `json:"event_duration"`
-	EventID          string   `json:"event_id"`
+	UserEmail        string         `json:"user_email"`
+	BookingDate      string         `json:"booking_date"`
+	BookingID        string         `json:"booking_id"`
+	ShowID           string         `json:"show_id"`
+	TimeBooked       string         `json:"time_booked"`
+	NumTicketsBooked int            `json:"num_tickets_booked"`
+	OriginalPrice    float64        `json:"original_price"`
+	Discount         float64        `json:"discount"`
+	PromoCode        string         `json:"promo_code,omitempty"`
+	TotalPrice       float64        `json:"total_price"`
+	Seats            []string       `json:"seats"`
+	LineItems        []SeatLineItem `json:"line_items,omitempty"`
+	VenueCity        string         `json:"venue_city"`
+	VenueName        string         `json:"venue_name"`
+	VenueState       string         `json:"venue_state"`
+	EventName        string         `json:"event_name"`
+	EventDuration    string         `json:"event_duration"`
+	EventID          string         `json:"event_id"`
+	Status           string         `json:"status"`
+	PaymentID        string         `json:"payment_id,omitempty"`
+	CancelledAt      string         `json:"cancelled_at,omitempty"`
+	CancelledBy      string         `json:"cancelled_by,omitempty"`
+	CancelReason     string         `json:"cancel_reason,omitempty"`
+	RefundAmount     float64        `json:"refund_amount,omitempty"`
+	TicketVersion    int            `json:"ticket_version,omitempty"`
+	TransferredFrom  string         `json:"transferred_from,omitempty"`
+	CancelledSeats   []string       `json:"cancelled_seats,omitempty"`
+	// RefundPending is set when a refund could not be paid straight away and
+	// will be retried.
+	RefundPending bool `json:"refund_pending,omitempty"`
+
+	// OriginalPaymentID is the sender's payment for a received booking.
+	OriginalPaymentID string `json:"-"`
+	// SaleDate is the day the booking was counted in the sales report.
+	SaleDate string `json:"-"`
 }
 
 type EventDTO struct {
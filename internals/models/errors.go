@@ -0,0 +1,21 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SeatConflictError is returned when one or more requested seats were booked
+// or held by someone else before the write could go through.
+type SeatConflictError struct {
+	Seats []string `json:"seats"`
+}
+
+func (e *SeatConflictError) Error() string {
+	return fmt.Sprintf("seats not available: %s", strings.Join(e.Seats, ", "))
+}
+
+// ErrHoldExpired is returned when a seat hold is confirmed or released after
+// its window has passed or its seats have been taken over.
+var ErrHoldExpired = errors.New("seat hold has expired")
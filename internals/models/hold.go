@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type SeatHold struct {
+	HoldID    string    `json:"hold_id"`
+	ShowID    string    `json:"show_id"`
+	UserID    string    `json:"user_id"`
+	Seats     []string  `json:"seats"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	HostApplicationPending  = "pending"
+	HostApplicationApproved = "approved"
+	HostApplicationRejected = "rejected"
+)
+
+var (
+	ErrHostApplicationNotFound   = errors.New("host application not found")
+	ErrHostApplicationNotPending = errors.New("host application is no longer pending")
+	ErrHostApplicationExists     = errors.New("a host application is already pending")
+)
+
+// HostApplication is a customer's request to become a host. A user has at
+// most one application; after a rejection they may apply again, which
+// replaces it.
+type HostApplication struct {
+	UserEmail    string    `json:"user_email"`
+	BusinessName string    `json:"business_name"`
+	ContactPhone string    `json:"contact_phone"`
+	Address      string    `json:"address"`
+	Website      string    `json:"website,omitempty"`
+	Description  string    `json:"description"`
+	Status       string    `json:"status"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
+
+type HostApplicationRequest struct {
+	BusinessName string `json:"business_name"`
+	ContactPhone string `json:"contact_phone"`
+	Address      string `json:"address"`
+	Website      string `json:"website"`
+	Description  string `json:"description"`
+}
+
+// HostApplicationReview is an admin's decision on an application. Reason is
+// required when rejecting.
+type HostApplicationReview struct {
+	Approve bool   `json:"approve"`
+	Reason  string `json:"reason"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	IdempotencyInProgress = "in_progress"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyRecord remembers the first request made with an Idempotency-Key
+// and, once it has finished, the response that was sent for it. A request
+// still in progress after LockedUntil is assumed to have died, and a retry of
+// it may take the key over.
+type IdempotencyRecord struct {
+	UserID      string
+	Key         string
+	Fingerprint string
+	Status      string
+	StatusCode  int
+	Body        string
+	Headers     map[string]string
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+}
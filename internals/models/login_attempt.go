@@ -0,0 +1,32 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Login attempts are counted per email and per source IP.
+const (
+	LoginAttemptEmail = "EMAIL"
+	LoginAttemptIP    = "IP"
+)
+
+// LoginAttempts is the failed login record of one email or IP. Logins are
+// refused until BlockedUntil.
+type LoginAttempts struct {
+	Kind         string    `json:"kind"`
+	Key          string    `json:"key"`
+	Failures     int       `json:"failures"`
+	BlockedUntil time.Time `json:"blocked_until"`
+}
+
+// LoginThrottledError is returned when a login is refused because of earlier
+// failures, before the password is checked.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(math.Ceil(e.RetryAfter.Seconds())))
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// PriceTier prices every seat in the listed layout sections. Seats in
+// sections not covered by any tier are charged the show's base price.
+type PriceTier struct {
+	Name     string   `json:"name" dynamodbav:"name"`
+	Sections []string `json:"sections" dynamodbav:"sections"`
+	Price    float64  `json:"price" dynamodbav:"price"`
+}
+
+type SeatLineItem struct {
+	Seat    string  `json:"seat" dynamodbav:"seat"`
+	Section string  `json:"section" dynamodbav:"section"`
+	Tier    string  `json:"tier,omitempty" dynamodbav:"tier,omitempty"`
+	Price   float64 `json:"price" dynamodbav:"price"`
+}
+
+// ValidatePriceTiers checks the tiers against the venue layout: names are
+// unique, prices non-negative and each section belongs to at most one tier.
+func ValidatePriceTiers(tiers []PriceTier, layout SeatLayout) error {
+	sections := make(map[string]bool, len(layout.Sections))
+	for _, sec := range layout.Sections {
+		sections[sec.Name] = true
+	}
+
+	names := make(map[string]bool, len(tiers))
+	assigned := make(map[string]string)
+	for _, t := range tiers {
+		if t.Name == "" {
+			return errors.New("price tier name is required")
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate price tier %s", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.Price < 0 {
+			return fmt.Errorf("price tier %s cannot have a negative price", t.Name)
+		}
+		if len(t.Sections) == 0 {
+			return fmt.Errorf("price tier %s has no sections", t.Name)
+		}
+		for _, sec := range t.Sections {
+			if !sections[sec] {
+				return fmt.Errorf("price tier %s refers to unknown section %s", t.Name, sec)
+			}
+			if other, ok := assigned[sec]; ok {
+				return fmt.Errorf("section %s is in both %s and %s", sec, other, t.Name)
+			}
+			assigned[sec] = t.Name
+		}
+	}
+	return nil
+}
+
+// PriceSeats breaks the seats down into per-seat line items and returns
+// them with their total.
+func PriceSeats(layout SeatLayout, tiers []PriceTier, basePrice float64, seats []string) ([]SeatLineItem, float64) {
+	tierBySection := make(map[string]PriceTier)
+	for _, t := range tiers {
+		for _, sec := range t.Sections {
+			tierBySection[sec] = t
+		}
+	}
+
+	items := make([]SeatLineItem, 0, len(seats))
+	var total float64
+	for _, seat := range seats {
+		section, _ := layout.SectionOf(seat)
+		item := SeatLineItem{Seat: seat, Section: section, Price: basePrice}
+		if t, ok := tierBySection[section]; ok {
+			item.Tier = t.Name
+			item.Price = t.Price
+		}
+		items = append(items, item)
+		total += item.Price
+	}
+
+	return items, math.Round(total*100) / 100
+}
+
+// SplitSeats divides the booking into the seats it keeps and the listed
+// seats taken out of it. Each seat is valued at its line item price, or an
+// even share of the original price for bookings made before line items, and
+// the discount is shared in proportion; the two parts always add up to the
+// booking's totals.
+func (b UserBookingDTO) SplitSeats(seats []string) (kept, taken UserBookingDTO, err error) {
+	if len(seats) == 0 {
+		return kept, taken, errors.New("no seats given")
+	}
+	remove := make(map[string]bool, len(seats))
+	for _, seat := range seats {
+		if remove[seat] {
+			return kept, taken, fmt.Errorf("seat %s is listed twice", seat)
+		}
+		remove[seat] = true
+	}
+	for _, seat := range seats {
+		if !slices.Contains(b.Seats, seat) {
+			return kept, taken, fmt.Errorf("seat %s is not part of the booking", seat)
+		}
+	}
+
+	kept, taken = b, b
+	kept.Seats, taken.Seats = nil, nil
+	kept.LineItems, taken.LineItems = nil, nil
+	for _, seat := range b.Seats {
+		if remove[seat] {
+			taken.Seats = append(taken.Seats, seat)
+		} else {
+			kept.Seats = append(kept.Seats, seat)
+		}
+	}
+	for _, item := range b.LineItems {
+		if remove[item.Seat] {
+			taken.LineItems = append(taken.LineItems, item)
+		} else {
+			kept.LineItems = append(kept.LineItems, item)
+		}
+	}
+
+	takenOriginal := b.OriginalPrice * float64(len(taken.Seats)) / float64(len(b.Seats))
+	if len(b.LineItems) > 0 {
+		takenOriginal = 0
+		for _, item := range taken.LineItems {
+			takenOriginal += item.Price
+		}
+	}
+	takenOriginal = math.Round(takenOriginal*100) / 100
+	takenDiscount := 0.0
+	if b.OriginalPrice > 0 {
+		takenDiscount = math.Round(b.Discount*takenOriginal/b.OriginalPrice*100) / 100
+	}
+
+	taken.NumTicketsBooked = len(taken.Seats)
+	taken.OriginalPrice = takenOriginal
+	taken.Discount = takenDiscount
+	taken.TotalPrice = math.Round((takenOriginal-takenDiscount)*100) / 100
+
+	kept.NumTicketsBooked = len(kept.Seats)
+	kept.OriginalPrice = math.Round((b.OriginalPrice-takenOriginal)*100) / 100
+	kept.Discount = math.Round((b.Discount-takenDiscount)*100) / 100
+	kept.TotalPrice = math.Round((b.TotalPrice-taken.TotalPrice)*100) / 100
+	return kept, taken, nil
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	PercentDiscount DiscountType = "percent"
+	FlatDiscount    DiscountType = "flat"
+)
+
+type PromoScope string
+
+const (
+	ScopeAll   PromoScope = ""
+	ScopeEvent PromoScope = "event"
+	ScopeShow  PromoScope = "show"
+	ScopeVenue PromoScope = "venue"
+	ScopeHost  PromoScope = "host"
+)
+
+// PromoCode limits of 0 mean unlimited.
+type PromoCode struct {
+	Code           string       `json:"code" dynamodbav:"code"`
+	DiscountType   DiscountType `json:"discount_type" dynamodbav:"discount_type"`
+	DiscountValue  float64      `json:"discount_value" dynamodbav:"discount_value"`
+	ValidFrom      time.Time    `json:"valid_from" dynamodbav:"valid_from"`
+	ValidUntil     time.Time    `json:"valid_until" dynamodbav:"valid_until"`
+	MaxRedemptions int          `json:"max_redemptions" dynamodbav:"max_redemptions"`
+	PerUserLimit   int          `json:"per_user_limit" dynamodbav:"per_user_limit"`
+	Redemptions    int          `json:"redemptions" dynamodbav:"redemptions"`
+	ScopeType      PromoScope   `json:"scope_type,omitempty" dynamodbav:"scope_type"`
+	ScopeID        string       `json:"scope_id,omitempty" dynamodbav:"scope_id"`
+	CreatedBy      string       `json:"created_by" dynamodbav:"created_by"`
+}
+
+// ErrPromoUnavailable is returned when a promo code runs out or expires
+// between validation and the booking being written.
+var ErrPromoUnavailable = errors.New("promo code is no longer available")
+
+func NormalisePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p PromoCode) Validate() error {
+	if p.Code == "" {
+		return errors.New("promo code is required")
+	}
+	switch p.DiscountType {
+	case PercentDiscount:
+		if p.DiscountValue <= 0 || p.DiscountValue > 100 {
+			return errors.New("percentage discount must be between 0 and 100")
+		}
+	case FlatDiscount:
+		if p.DiscountValue <= 0 {
+			return errors.New("flat discount must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown discount type %q", p.DiscountType)
+	}
+	if !p.ValidUntil.After(p.ValidFrom) {
+		return errors.New("valid_until must be after valid_from")
+	}
+	if p.MaxRedemptions < 0 || p.PerUserLimit < 0 {
+		return errors.New("redemption limits cannot be negative")
+	}
+	switch p.ScopeType {
+	case ScopeAll:
+	case ScopeEvent, ScopeShow, ScopeVenue, ScopeHost:
+		if p.ScopeID == "" {
+			return errors.New("scope_id is required for a scoped promo code")
+		}
+	default:
+		return fmt.Errorf("unknown scope %q", p.ScopeType)
+	}
+	return nil
+}
+
+// AppliesTo checks the code's window, remaining redemptions and scope for a
+// booking on show at now. Per-user limits are checked by the caller.
+func (p PromoCode) AppliesTo(show *ShowDTO, now time.Time) error {
+	if now.Before(p.ValidFrom) || now.After(p.ValidUntil) {
+		return errors.New("promo code is not valid at this time")
+	}
+	if p.MaxRedemptions > 0 && p.Redemptions >= p.MaxRedemptions {
+		return ErrPromoUnavailable
+	}
+
+	var target string
+	switch p.ScopeType {
+	case ScopeAll:
+		return nil
+	case ScopeEvent:
+		target = show.EventID
+	case ScopeShow:
+		target = show.ID
+	case ScopeVenue:
+		target = show.Venue.ID
+	case ScopeHost:
+		target = show.HostID
+	}
+	if target != p.ScopeID {
+		return errors.New("promo code does not apply to this show")
+	}
+	return nil
+}
+
+// Discount returns the amount taken off amount, never more than amount.
+func (p PromoCode) Discount(amount float64) float64 {
+	var d float64
+	switch p.DiscountType {
+	case PercentDiscount:
+		d = amount * p.DiscountValue / 100
+	case FlatDiscount:
+		d = p.DiscountValue
+	}
+	if d > amount {
+		d = amount
+	}
+	return math.Round(d*100) / 100
+}
@@ -0,0 +1,57 @@
+package models
+
+// DailySales is what a show sold on one day, by booking date.
+type DailySales struct {
+	Date      string  `json:"date"`
+	Bookings  int     `json:"bookings"`
+	SeatsSold int     `json:"seats_sold"`
+	Revenue   float64 `json:"revenue"`
+}
+
+// SalesTotals sums the sales of one or more shows. SeatsSold is net of
+// cancellations; Occupancy is SeatsSold over Capacity.
+type SalesTotals struct {
+	Shows          int     `json:"shows"`
+	Capacity       int     `json:"capacity"`
+	SeatsSold      int     `json:"seats_sold"`
+	CancelledSeats int     `json:"cancelled_seats"`
+	Occupancy      float64 `json:"occupancy"`
+	Bookings       int     `json:"bookings"`
+	GrossRevenue   float64 `json:"gross_revenue"`
+	Refunds        float64 `json:"refunds"`
+	NetRevenue     float64 `json:"net_revenue"`
+}
+
+type ShowSales struct {
+	ShowID       string `json:"show_id"`
+	EventID      string `json:"event_id"`
+	EventName    string `json:"event_name"`
+	VenueID      string `json:"venue_id"`
+	VenueName    string `json:"venue_name"`
+	ShowDateTime string `json:"show_date_time"`
+	Status       string `json:"status"`
+	SalesTotals
+	Daily []DailySales `json:"daily"`
+}
+
+type EventSales struct {
+	EventID   string `json:"event_id"`
+	EventName string `json:"event_name"`
+	SalesTotals
+}
+
+type VenueSales struct {
+	VenueID   string `json:"venue_id"`
+	VenueName string `json:"venue_name"`
+	SalesTotals
+}
+
+// HostSalesReport is a host's sales per show, rolled up by event and venue.
+type HostSalesReport struct {
+	HostID      string       `json:"host_id"`
+	GeneratedAt string       `json:"generated_at"`
+	Totals      SalesTotals  `json:"totals"`
+	Shows       []ShowSales  `json:"shows"`
+	Events      []EventSales `json:"events"`
+	Venues      []VenueSales `json:"venues"`
+}
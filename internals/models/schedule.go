@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxEventDuration bounds how far back a venue's schedule has to be
+	// searched for a show that is still running.
+	MaxEventDuration = 24 * time.Hour
+
+	// DefaultEventDuration is assumed for events stored before durations
+	// were validated, whose duration cannot be parsed.
+	DefaultEventDuration = 3 * time.Hour
+
+	DefaultChangeoverBuffer = 30 * time.Minute
+)
+
+// ErrScheduleChanged is returned when another show was added to or moved at
+// the venue while a show was being scheduled.
+var ErrScheduleChanged = errors.New("venue schedule changed, please retry")
+
+// ShowConflictError lists the shows already occupying the venue at the
+// requested time.
+type ShowConflictError struct {
+	ShowIDs []string `json:"show_ids"`
+}
+
+func (e *ShowConflictError) Error() string {
+	return fmt.Sprintf("venue is already booked by shows: %s", strings.Join(e.ShowIDs, ", "))
+}
+
+// VenueSlot is the time a show occupies its venue.
+type VenueSlot struct {
+	ShowID   string
+	VenueID  string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// VenueSchedule is a window of a venue's slots. Version changes every time a
+// show is added to or moved at the venue.
+type VenueSchedule struct {
+	Slots   []VenueSlot
+	Version int64
+}
+
+// Conflicts returns the IDs of slots that overlap [start, end) once buffer is
+// added on either side. Slots for ignoreShowID are skipped.
+func (vs VenueSchedule) Conflicts(start, end time.Time, buffer time.Duration, ignoreShowID string) []string {
+	var ids []string
+	for _, slot := range vs.Slots {
+		if slot.ShowID == ignoreShowID {
+			continue
+		}
+		if start.Before(slot.EndsAt.Add(buffer)) && slot.StartsAt.Before(end.Add(buffer)) {
+			ids = append(ids, slot.ShowID)
+		}
+	}
+	return ids
+}
+
+var durationPart = regexp.MustCompile(`(\d+)\s*(hours|hour|hrs|hr|h|minutes|minute|mins|min|m)\b`)
+
+// ParseEventDuration understands Go durations ("2h30m"), clock form ("2:30"),
+// a bare number of minutes ("150") and phrases such as "2 hours 30 mins".
+func ParseEventDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, errors.New("duration is required")
+	}
+
+	d, err := parseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	if d > MaxEventDuration {
+		return 0, fmt.Errorf("duration cannot be longer than %s", MaxEventDuration)
+	}
+	return d, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	if mins, err := strconv.Atoi(s); err == nil {
+		return time.Duration(mins) * time.Minute, nil
+	}
+	if h, m, ok := strings.Cut(s, ":"); ok {
+		hours, herr := strconv.Atoi(h)
+		mins, merr := strconv.Atoi(m)
+		if herr != nil || merr != nil || mins < 0 || mins >= 60 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(hours)*time.Hour + time.Duration(mins)*time.Minute, nil
+	}
+
+	matches := durationPart.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	// everything apart from the matched parts must be separators
+	rest := durationPart.ReplaceAllString(s, "")
+	if strings.Trim(rest, " ,and") != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	for _, m := range matches {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(m[2], "h") {
+			d += time.Duration(n) * time.Hour
+		} else {
+			d += time.Duration(n) * time.Minute
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SeatLayout is a venue's seat map. Seat IDs are the row label followed by
+// the seat number, e.g. "A1", so row labels must be unique across sections.
+type SeatLayout struct {
+	Sections []SeatSection `json:"sections" dynamodbav:"sections"`
+}
+
+type SeatSection struct {
+	Name string    `json:"name" dynamodbav:"name"`
+	Rows []SeatRow `json:"rows" dynamodbav:"rows"`
+}
+
+// SeatRow numbers its seats 1..Seats. Missing lists numbers that do not
+// physically exist, AisleAfter the numbers followed by an aisle gap and
+// Accessible the wheelchair-accessible seats.
+type SeatRow struct {
+	Label      string `json:"label" dynamodbav:"label"`
+	Seats      int    `json:"seats" dynamodbav:"seats"`
+	AisleAfter []int  `json:"aisle_after,omitempty" dynamodbav:"aisle_after,omitempty"`
+	Missing    []int  `json:"missing,omitempty" dynamodbav:"missing,omitempty"`
+	Accessible []int  `json:"accessible,omitempty" dynamodbav:"accessible,omitempty"`
+}
+
+const (
+	SeatFree    = "free"
+	SeatBooked  = "booked"
+	SeatHeld    = "held"
+	SeatMissing = "missing"
+)
+
+type RenderedSeat struct {
+	ID         string `json:"id,omitempty"`
+	Number     int    `json:"number"`
+	Status     string `json:"status"`
+	Accessible bool   `json:"accessible,omitempty"`
+	AisleAfter bool   `json:"aisle_after,omitempty"`
+}
+
+type RenderedRow struct {
+	Label string         `json:"label"`
+	Seats []RenderedSeat `json:"seats"`
+}
+
+type RenderedSection struct {
+	Name string        `json:"name"`
+	Rows []RenderedRow `json:"rows"`
+}
+
+type ShowSeatMap struct {
+	ShowID   string            `json:"show_id"`
+	VenueID  string            `json:"venue_id"`
+	Sections []RenderedSection `json:"sections"`
+}
+
+// DefaultSeatLayout is the A–J / 1–10 grid used by venues without their own
+// layout.
+func DefaultSeatLayout() SeatLayout {
+	rows := make([]SeatRow, 0, 10)
+	for row := 'A'; row <= 'J'; row++ {
+		rows = append(rows, SeatRow{Label: string(row), Seats: 10})
+	}
+	return SeatLayout{Sections: []SeatSection{{Name: "Standard", Rows: rows}}}
+}
+
+func (l SeatLayout) Validate() error {
+	if len(l.Sections) == 0 {
+		return errors.New("seat layout needs at least one section")
+	}
+
+	sections := make(map[string]bool)
+	labels := make(map[string]bool)
+	for _, sec := range l.Sections {
+		if strings.TrimSpace(sec.Name) == "" {
+			return errors.New("section name is required")
+		}
+		if sections[sec.Name] {
+			return fmt.Errorf("duplicate section %s", sec.Name)
+		}
+		sections[sec.Name] = true
+
+		if len(sec.Rows) == 0 {
+			return fmt.Errorf("section %s has no rows", sec.Name)
+		}
+		for _, row := range sec.Rows {
+			label := strings.ToUpper(strings.TrimSpace(row.Label))
+			if label == "" || strings.ContainsAny(label, "0123456789#") {
+				return fmt.Errorf("invalid row label %q", row.Label)
+			}
+			if labels[label] {
+				return fmt.Errorf("duplicate row %s", label)
+			}
+			labels[label] = true
+
+			if row.Seats <= 0 {
+				return fmt.Errorf("row %s must have at least one seat", label)
+			}
+			for _, nums := range [][]int{row.AisleAfter, row.Missing, row.Accessible} {
+				for _, n := range nums {
+					if n < 1 || n > row.Seats {
+						return fmt.Errorf("seat %d is outside row %s", n, label)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Seats lists every seat that exists in the layout, in layout order.
+func (l SeatLayout) Seats() []string {
+	var seats []string
+	for _, sec := range l.Sections {
+		for _, row := range sec.Rows {
+			missing := toSet(row.Missing)
+			for n := 1; n <= row.Seats; n++ {
+				if !missing[n] {
+					seats = append(seats, seatID(row.Label, n))
+				}
+			}
+		}
+	}
+	return seats
+}
+
+// SectionOf returns the section containing seat, or false if the seat does
+// not exist in this layout.
+func (l SeatLayout) SectionOf(seat string) (string, bool) {
+	for _, sec := range l.Sections {
+		for _, row := range sec.Rows {
+			label := strings.ToUpper(row.Label)
+			if !strings.HasPrefix(seat, label) {
+				continue
+			}
+			var n int
+			if _, err := fmt.Sscanf(seat[len(label):], "%d", &n); err != nil || seatID(label, n) != seat {
+				continue
+			}
+			if n >= 1 && n <= row.Seats && !toSet(row.Missing)[n] {
+				return sec.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (l SeatLayout) HasSeat(seat string) bool {
+	_, ok := l.SectionOf(seat)
+	return ok
+}
+
+// Render lays out every seat position, including missing ones so clients can
+// draw gaps, with its availability.
+func (l SeatLayout) Render(booked, held []string) []RenderedSection {
+	bookedSet := make(map[string]bool, len(booked))
+	for _, s := range booked {
+		bookedSet[s] = true
+	}
+	heldSet := make(map[string]bool, len(held))
+	for _, s := range held {
+		heldSet[s] = true
+	}
+
+	sections := make([]RenderedSection, 0, len(l.Sections))
+	for _, sec := range l.Sections {
+		rendered := RenderedSection{Name: sec.Name, Rows: make([]RenderedRow, 0, len(sec.Rows))}
+		for _, row := range sec.Rows {
+			missing := toSet(row.Missing)
+			aisles := toSet(row.AisleAfter)
+			accessible := toSet(row.Accessible)
+
+			r := RenderedRow{Label: strings.ToUpper(row.Label), Seats: make([]RenderedSeat, 0, row.Seats)}
+			for n := 1; n <= row.Seats; n++ {
+				seat := RenderedSeat{Number: n, AisleAfter: aisles[n]}
+				if missing[n] {
+					seat.Status = SeatMissing
+					r.Seats = append(r.Seats, seat)
+					continue
+				}
+				seat.ID = seatID(row.Label, n)
+				seat.Accessible = accessible[n]
+				switch {
+				case bookedSet[seat.ID]:
+					seat.Status = SeatBooked
+				case heldSet[seat.ID]:
+					seat.Status = SeatHeld
+				default:
+					seat.Status = SeatFree
+				}
+				r.Seats = append(r.Seats, seat)
+			}
+			rendered.Rows = append(rendered.Rows, r)
+		}
+		sections = append(sections, rendered)
+	}
+	return sections
+}
+
+func seatID(label string, n int) string {
+	return fmt.Sprintf("%s%d", strings.ToUpper(label), n)
+}
+
+func toSet(nums []int) map[int]bool {
+	set := make(map[int]bool, len(nums))
+	for _, n := range nums {
+		set[n] = true
+	}
+	return set
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SeriesActive    = "active"
+	SeriesCancelled = "cancelled"
+
+	// OccurrenceConflict marks a previewed occurrence that overlaps another
+	// show at the venue and would be skipped.
+	OccurrenceConflict = "conflict"
+
+	MaxSeriesOccurrences = 200
+	MaxSeriesSpanDays    = 366
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// SeriesSpec describes a recurring schedule: a show at each of Times on every
+// listed weekday between StartDate and EndDate inclusive. Dates are
+// YYYY-MM-DD and times HH:MM, both UTC like single shows.
+type SeriesSpec struct {
+	EventID   string   `json:"event_id" dynamodbav:"event_id"`
+	VenueID   string   `json:"venue_id" dynamodbav:"venue_id"`
+	StartDate string   `json:"start_date" dynamodbav:"start_date"`
+	EndDate   string   `json:"end_date" dynamodbav:"end_date"`
+	Weekdays  []string `json:"weekdays" dynamodbav:"weekdays"`
+	Times     []string `json:"times" dynamodbav:"times"`
+	Price     float64  `json:"price" dynamodbav:"price"`
+
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy,omitempty" dynamodbav:"cancellation_policy,omitempty"`
+	PriceTiers         []PriceTier         `json:"price_tiers,omitempty" dynamodbav:"price_tiers,omitempty"`
+}
+
+// Occurrences expands the spec into show start times in order, leaving out
+// any that are not after now.
+func (s SeriesSpec) Occurrences(now time.Time) ([]time.Time, error) {
+	if s.EventID == "" || s.VenueID == "" {
+		return nil, errors.New("event_id and venue_id are required")
+	}
+	if s.Price < 0 {
+		return nil, errors.New("price cannot be negative")
+	}
+
+	start, err := time.Parse("2006-01-02", s.StartDate)
+	if err != nil {
+		return nil, errors.New("invalid start_date, expected YYYY-MM-DD")
+	}
+	end, err := time.Parse("2006-01-02", s.EndDate)
+	if err != nil {
+		return nil, errors.New("invalid end_date, expected YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return nil, errors.New("end_date cannot be before start_date")
+	}
+	if end.Sub(start) > MaxSeriesSpanDays*24*time.Hour {
+		return nil, fmt.Errorf("a series cannot span more than %d days", MaxSeriesSpanDays)
+	}
+
+	if len(s.Weekdays) == 0 {
+		return nil, errors.New("at least one weekday is required")
+	}
+	days := make(map[time.Weekday]bool, len(s.Weekdays))
+	for _, d := range s.Weekdays {
+		wd, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", d)
+		}
+		days[wd] = true
+	}
+
+	if len(s.Times) == 0 {
+		return nil, errors.New("at least one show time is required")
+	}
+	offsets := make([]time.Duration, 0, len(s.Times))
+	seen := make(map[string]bool, len(s.Times))
+	for _, t := range s.Times {
+		tod, err := time.Parse("15:04", t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid show time %q, expected HH:MM", t)
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		offsets = append(offsets, time.Duration(tod.Hour())*time.Hour+time.Duration(tod.Minute())*time.Minute)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	var starts []time.Time
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if !days[day.Weekday()] {
+			continue
+		}
+		for _, off := range offsets {
+			startsAt := day.Add(off)
+			if !startsAt.After(now) {
+				continue
+			}
+			starts = append(starts, startsAt)
+			if len(starts) > MaxSeriesOccurrences {
+				return nil, fmt.Errorf("a series cannot have more than %d shows", MaxSeriesOccurrences)
+			}
+		}
+	}
+	if len(starts) == 0 {
+		return nil, errors.New("the schedule has no upcoming shows")
+	}
+	return starts, nil
+}
+
+// SeriesOccurrence is one show of a series. ConflictsWith lists the shows
+// that kept it from being scheduled.
+type SeriesOccurrence struct {
+	ShowID        string    `json:"show_id,omitempty"`
+	StartsAt      time.Time `json:"starts_at"`
+	Status        string    `json:"status"`
+	ConflictsWith []string  `json:"conflicts_with,omitempty"`
+}
+
+// SeriesPreview is what creating a series would produce.
+type SeriesPreview struct {
+	Occurrences []SeriesOccurrence `json:"occurrences"`
+	Conflicts   int                `json:"conflicts"`
+}
+
+// Scheduled returns the occurrences that would be created.
+func (p SeriesPreview) Scheduled() []SeriesOccurrence {
+	out := make([]SeriesOccurrence, 0, len(p.Occurrences)-p.Conflicts)
+	for _, o := range p.Occurrences {
+		if o.Status != OccurrenceConflict {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// ConflictError gathers every show the preview's conflicts clash with.
+func (p SeriesPreview) ConflictError() *ShowConflictError {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, o := range p.Occurrences {
+		for _, id := range o.ConflictsWith {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return &ShowConflictError{ShowIDs: ids}
+}
+
+// ShowSeries is a recurring schedule and the shows created from it. Skipped
+// is only filled in on creation.
+type ShowSeries struct {
+	ID        string `json:"id"`
+	HostID    string `json:"host_id"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	SeriesSpec
+
+	Occurrences []SeriesOccurrence `json:"occurrences"`
+	Skipped     []SeriesOccurrence `json:"skipped,omitempty"`
+}
+
+// SeriesUpdate changes a series. With ShowID set it applies to that
+// occurrence only, which may also be moved with ShowDate and ShowTime;
+// otherwise the pricing applies to every upcoming occurrence.
+type SeriesUpdate struct {
+	ShowID     string      `json:"show_id,omitempty"`
+	Price      *float64    `json:"price,omitempty"`
+	PriceTiers []PriceTier `json:"price_tiers,omitempty"`
+	ShowDate   string      `json:"show_date,omitempty"`
+	ShowTime   string      `json:"show_time,omitempty"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Purposes of the single-use tokens mailed to users.
+const (
+	AuthTokenPasswordReset     = "PASSWORD_RESET"
+	AuthTokenEmailVerification = "EMAIL_VERIFICATION"
+	AuthTokenLoginChallenge    = "LOGIN_CHALLENGE"
+)
+
+var (
+	ErrInvalidAuthToken    = errors.New("link is invalid or has expired")
+	ErrEmailNotVerified    = errors.New("please verify your email address first")
+	ErrInvalidTwoFactor    = errors.New("invalid two-factor code")
+	ErrTwoFactorConflict   = errors.New("two-factor settings changed, please try again")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, please log in again")
+	ErrSessionRevoked      = errors.New("session has ended, please log in again")
+)
+
+// Session is one login of a user, kept server side. Its refresh token is
+// replaced on every use; presenting a replaced token again revokes the whole
+// session, since only a stolen copy would still be in circulation.
+type Session struct {
+	SessionID    string    `json:"session_id"`
+	UserEmail    string    `json:"user_email"`
+	TokenVersion int       `json:"token_version"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Revoked      bool      `json:"revoked"`
+	// TwoFactor is set when the login that opened the session passed a
+	// second factor.
+	TwoFactor bool `json:"two_factor"`
+}
+
+// Active reports whether access tokens of the session may still be used.
+func (s Session) Active(now time.Time) bool {
+	return !s.Revoked && now.Before(s.ExpiresAt)
+}
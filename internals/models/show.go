@@ -6,6 +6,11 @@ import (
 	"github.com/lib/pq"
 )
 
+const (
+	ShowScheduled = "scheduled"
+	ShowCancelled = "cancelled"
+)
+
 type Show struct {
 	ID string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
 
@@ -24,6 +29,16 @@ type Show struct {
 	ShowDate    time.Time      `gorm:"not null"`
 	ShowTime    string         `gorm:"type:varchar(5);not null"`
 	BookedSeats pq.StringArray `gorm:"type:text[]"`
+
+	CancellationPolicy *CancellationPolicy `gorm:"-"`
+	PriceTiers         []PriceTier         `gorm:"-"`
+
+	// EndsAt and ScheduleVersion come from the venue schedule check; the
+	// show is only written if the venue's schedule is still at that version.
+	EndsAt          time.Time `gorm:"-"`
+	ScheduleVersion int64     `gorm:"-"`
+
+	SeriesID string `gorm:"-"`
 }
 
 // type ShowResponse struct {
@@ -47,13 +62,47 @@ type Show struct {
 // }
 
 type ShowDTO struct {
-	ID          string    `json:"id"`
-	EventID     string    `json:"event_id"`
-	Price       float64   `json:"price"`
-	ShowDate    time.Time `json:"show_date"`
-	ShowTime    string    `json:"show_time"`
-	BookedSeats []string  `json:"booked_seats"`
-	Venue       VenueDTO  `json:"venue"`
-	IsBlocked   bool      `json:"is_blocked"`
-	HostID      string    `json:"host_id"`
+	ID             string    `json:"id"`
+	EventID        string    `json:"event_id"`
+	Price          float64   `json:"price"`
+	ShowDate       time.Time `json:"show_date"`
+	ShowTime       string    `json:"show_time"`
+	BookedSeats    []string  `json:"booked_seats"`
+	HeldSeats      []string  `json:"held_seats"`
+	AvailableSeats []string  `json:"available_seats"`
+	Venue          VenueDTO  `json:"venue"`
+	IsBlocked      bool      `json:"is_blocked"`
+	HostID         string    `json:"host_id"`
+	Status         string    `json:"status"`
+	CancelReason   string    `json:"cancel_reason,omitempty"`
+	CancelledAt    string    `json:"cancelled_at,omitempty"`
+	SeriesID       string    `json:"series_id,omitempty"`
+
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"`
+	PriceTiers         []PriceTier        `json:"price_tiers"`
+	SeatLayout         SeatLayout         `json:"-"`
+}
+
+// ShowDateTime is the "2006-01-02T15:04" form used in the show's keys.
+func (s ShowDTO) ShowDateTime() string {
+	return s.ShowDate.Format("2006-01-02") + "T" + s.ShowTime
+}
+
+// StartsAt combines the show's date and HH:MM time into a UTC timestamp.
+func (s ShowDTO) StartsAt() (time.Time, error) {
+	return time.ParseInLocation("2006-01-02T15:04", s.ShowDateTime(), time.UTC)
+}
+
+// ShowCancellationSummary reports the outcome of cancelling a show and its
+// bookings. PaymentsFailed counts bookings that were still awaiting payment.
+// FailedBookings lists bookings that could not be cancelled and need another
+// attempt.
+type ShowCancellationSummary struct {
+	ShowID            string   `json:"show_id"`
+	Reason            string   `json:"reason"`
+	BookingsCancelled int      `json:"bookings_cancelled"`
+	PaymentsFailed    int      `json:"payments_failed"`
+	TotalRefunded     float64  `json:"total_refunded"`
+	RefundsPending    int      `json:"refunds_pending"`
+	FailedBookings    []string `json:"failed_bookings,omitempty"`
 }
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+// Ticket admits one seat of a booking. Version is the booking's
+// ticket_version when the ticket was issued; tickets from an older version
+// are no longer valid.
+type Ticket struct {
+	TicketID  string `json:"tid"`
+	BookingID string `json:"bid"`
+	ShowID    string `json:"sid"`
+	Seat      string `json:"seat"`
+	Version   int    `json:"v"`
+	IssuedAt  int64  `json:"iat"`
+}
+
+// TicketIDFor is the ticket ID of a seat in a booking.
+func TicketIDFor(bookingID, seat string) string {
+	return bookingID + ":" + seat
+}
+
+// IssuedTicket is a ticket with the signed token encoded in its QR code.
+type IssuedTicket struct {
+	Ticket
+	Token string `json:"token"`
+}
+
+// Admission records a ticket scanned at the door.
+type Admission struct {
+	TicketID   string `json:"ticket_id" dynamodbav:"ticket_id"`
+	BookingID  string `json:"booking_id" dynamodbav:"booking_id"`
+	ShowID     string `json:"show_id" dynamodbav:"show_id"`
+	Seat       string `json:"seat" dynamodbav:"seat"`
+	AdmittedAt string `json:"admitted_at" dynamodbav:"admitted_at"`
+	AdmittedBy string `json:"admitted_by" dynamodbav:"admitted_by"`
+	Device     string `json:"device,omitempty" dynamodbav:"device,omitempty"`
+}
+
+// AlreadyAdmittedError is returned when a seat is scanned a second time. It
+// carries the first admission.
+type AlreadyAdmittedError struct {
+	Admission Admission `json:"admission"`
+}
+
+func (e *AlreadyAdmittedError) Error() string {
+	return fmt.Sprintf("seat %s was already admitted at %s", e.Admission.Seat, e.Admission.AdmittedAt)
+}
+
+// TicketManifest lets a scanner validate a show's tickets offline: a ticket
+// is valid if its signature checks out against PublicKey and its ticket ID
+// and version are listed here.
+type TicketManifest struct {
+	ShowID      string           `json:"show_id"`
+	GeneratedAt string           `json:"generated_at"`
+	PublicKey   string           `json:"public_key"`
+	Tickets     []ManifestTicket `json:"tickets"`
+	Admitted    []string         `json:"admitted_seats"`
+}
+
+type ManifestTicket struct {
+	TicketID  string `json:"ticket_id"`
+	BookingID string `json:"booking_id"`
+	Seat      string `json:"seat"`
+	Version   int    `json:"v"`
+}
+
+// SignedManifest carries the manifest and the same manifest as a signed
+// token. Scanners should verify and read the token.
+type SignedManifest struct {
+	Manifest TicketManifest `json:"manifest"`
+	Token    string         `json:"token"`
+}
+
+// OfflineAdmission is one scan from a door device's offline log.
+type OfflineAdmission struct {
+	Token      string `json:"token"`
+	AdmittedAt string `json:"admitted_at"`
+}
+
+type RejectedAdmission struct {
+	Token  string `json:"token"`
+	Reason string `json:"reason"`
+}
+
+// DuplicateAdmission is a scan of a seat that had already been admitted,
+// with the admission that got there first.
+type DuplicateAdmission struct {
+	Scan  Admission `json:"scan"`
+	First Admission `json:"first"`
+}
+
+type AdmissionSyncResult struct {
+	Accepted   int                  `json:"accepted"`
+	Duplicates []DuplicateAdmission `json:"duplicates,omitempty"`
+	Rejected   []RejectedAdmission  `json:"rejected,omitempty"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferDeclined  = "declined"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+)
+
+const (
+	TransferActionCreated   = "created"
+	TransferActionAccepted  = "accepted"
+	TransferActionDeclined  = "declined"
+	TransferActionCancelled = "cancelled"
+)
+
+var (
+	ErrTransferNotFound     = errors.New("transfer not found")
+	ErrTransferNotPending   = errors.New("transfer is no longer pending")
+	ErrTransferWindowClosed = errors.New("tickets can no longer be transferred for this show")
+)
+
+// BookingTransfer moves Seats of FromUserID's booking to ToUserID once the
+// recipient accepts. Accepting creates NewBookingID under the recipient and
+// reissues the original booking's tickets.
+type BookingTransfer struct {
+	TransferID   string          `json:"transfer_id"`
+	BookingID    string          `json:"booking_id"`
+	ShowID       string          `json:"show_id"`
+	FromUserID   string          `json:"from_user_id"`
+	ToUserID     string          `json:"to_user_id"`
+	Seats        []string        `json:"seats"`
+	Status       string          `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	NewBookingID string          `json:"new_booking_id,omitempty"`
+	History      []TransferEvent `json:"history"`
+}
+
+// TransferEvent is one step in a transfer's audit trail.
+type TransferEvent struct {
+	Action string `json:"action" dynamodbav:"action"`
+	By     string `json:"by" dynamodbav:"by"`
+	At     string `json:"at" dynamodbav:"at"`
+}
+
+// TransferList is a user's transfers split by direction.
+type TransferList struct {
+	Incoming []BookingTransfer `json:"incoming"`
+	Outgoing []BookingTransfer `json:"outgoing"`
+}
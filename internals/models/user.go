@@ -1,5 +1,10 @@
 package models
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Role string
 
 const (
@@ -16,6 +21,83 @@ type User struct {
 	Password    string `dynamodbav:"password"`
 	Role        Role   `dynamodbav:"role"`
 	IsBlocked   bool   `dynamodbav:"is_blocked"`
+
+	// EmailVerified is set once the user follows the link mailed at signup.
+	// Unverified users can browse but not book. Accounts created before
+	// verification existed have no value and count as verified; use
+	// IsEmailVerified rather than reading it directly.
+	EmailVerified *bool `dynamodbav:"email_verified"`
+
+	// Two-factor authentication. TOTPPendingSecret holds a secret that was
+	// issued but not yet confirmed with a code; RecoveryCodes are hashes of
+	// the unused recovery codes and TOTPLastStep is the time step of the
+	// last code accepted, so a code cannot be used twice.
+	TOTPEnabled       bool     `dynamodbav:"totp_enabled"`
+	TOTPSecret        string   `dynamodbav:"totp_secret,omitempty" json:"-"`
+	TOTPPendingSecret string   `dynamodbav:"totp_pending_secret,omitempty" json:"-"`
+	TOTPLastStep      int64    `dynamodbav:"totp_last_step,omitempty" json:"-"`
+	RecoveryCodes     []string `dynamodbav:"recovery_codes,stringset,omitempty" json:"-"`
+
+	// TokenVersion is embedded in the user's JWTs and bumped whenever their
+	// role or access changes, which invalidates every token issued before.
+	TokenVersion int `dynamodbav:"token_version"`
+}
+
+// IsEmailVerified reports whether the user may use features that need a
+// verified email address.
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerified == nil || *u.EmailVerified
+}
+
+// ParseRole matches a role name case-insensitively.
+func ParseRole(name string) (Role, error) {
+	for _, role := range []Role{Customer, Host, Admin} {
+		if strings.EqualFold(name, string(role)) {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("role must be one of %s, %s or %s", Customer, Host, Admin)
+}
+
+// UserSummary is what admins see of a user when listing accounts.
+type UserSummary struct {
+	Email       string `json:"email" dynamodbav:"email"`
+	Username    string `json:"username" dynamodbav:"username"`
+	PhoneNumber string `json:"phone_number" dynamodbav:"phone_number"`
+	Role        Role   `json:"role" dynamodbav:"role"`
+	IsBlocked   bool   `json:"is_blocked" dynamodbav:"is_blocked"`
+}
+
+// UserPage is one page of a user listing. NextCursor is empty on the last
+// page.
+type UserPage struct {
+	Users      []UserSummary `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// TwoFactorEnrollment is handed out when a user sets up 2FA. The secret
+// only becomes active once a code generated from it is confirmed.
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token,omitempty"`
+	Code           string `json:"code"`
 }
 
 type UpdateUserRequest struct {
@@ -7,6 +7,8 @@ type Venue struct {
 	City      string `gorm:"type:text;not null" dynamodbav:"venue_city"`
 	State     string `gorm:"type:text;not null" dynamodbav:"venue_state"`
 	IsBlocked bool   `gorm:"default:false" dynamodbav:"is_blocked"`
+
+	SeatLayout *SeatLayout `gorm:"-" dynamodbav:"seat_layout,omitempty"`
 }
 
 type VenueResponse struct {
@@ -16,6 +18,8 @@ type VenueResponse struct {
 	City      string `gorm:"type:text;not null" dynamodbav:"venue_city"`
 	State     string `gorm:"type:text;not null" dynamodbav:"venue_state"`
 	IsBlocked bool   `gorm:"default:false" dynamodbav:"is_blocked"`
+
+	SeatLayout *SeatLayout `gorm:"-" dynamodbav:"seat_layout,omitempty"`
 }
 
 type VenueDTO struct {
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	WaitlistWaiting = "waiting"
+	WaitlistOffered = "offered"
+)
+
+var (
+	ErrAlreadyWaitlisted = errors.New("already on the waitlist for this show")
+	ErrNotWaitlisted     = errors.New("not on the waitlist for this show")
+)
+
+// WaitlistEntry is a customer waiting for Seats seats of a sold-out show.
+// Once seats free up they are offered a hold, OfferHoldID, which they can
+// confirm like any other hold until OfferExpiresAt.
+type WaitlistEntry struct {
+	ShowID   string    `json:"show_id"`
+	UserID   string    `json:"user_id"`
+	Seats    int       `json:"seats"`
+	JoinedAt time.Time `json:"joined_at"`
+	Status   string    `json:"status"`
+
+	// Position counts the waiting entries ahead of this one, starting at 1.
+	// It is only set for waiting entries.
+	Position int `json:"position,omitempty"`
+
+	OfferHoldID    string     `json:"offer_hold_id,omitempty"`
+	OfferedSeats   []string   `json:"offered_seats,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+}
+
+// OfferExpired reports whether the entry's offer has lapsed.
+func (e WaitlistEntry) OfferExpired(now time.Time) bool {
+	return e.Status == WaitlistOffered && e.OfferExpiresAt != nil && now.After(*e.OfferExpiresAt)
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notification is a message for a single user, addressed by email.
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+//go:generate mockgen -destination=../mocks/notifier_mock.go -package=mocks -source=notifier.go
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// FromEnv returns the notifier for this deployment: a FileNotifier when
+// NOTIFY_OUTBOX_FILE is set, otherwise a LogNotifier.
+func FromEnv() Notifier {
+	if path := os.Getenv("NOTIFY_OUTBOX_FILE"); path != "" {
+		return NewFileNotifier(path)
+	}
+	return NewLogNotifier()
+}
+
+// LogNotifier writes notifications to the function's log instead of
+// delivering them. It stands in for a mail provider in local runs.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("notification to %s: %s\n%s", n.To, n.Subject, n.Body)
+	return nil
+}
+
+// FileNotifier appends each notification to a file as a line of JSON, so
+// local runs and scripts can pick up links sent by mail.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+func (f *FileNotifier) Notify(ctx context.Context, n Notification) error {
+	line, err := json.Marshal(struct {
+		Notification
+		SentAt time.Time `json:"sent_at"`
+	}{n, time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
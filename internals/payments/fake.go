@@ -0,0 +1,99 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Payment methods understood by FakeProvider. Any other method succeeds.
+const (
+	FakeMethodDecline     = "fake_decline"
+	FakeMethodCaptureFail = "fake_capture_fail"
+)
+
+const fakeCaptureFailPrefix = "pay_capfail_"
+
+const minWebhookSecretLen = 16
+
+// FakeProvider is an in-process gateway for local runs. It keeps no state:
+// the outcome of every call is decided by the payment method and payment ID,
+// so separate Lambdas see the same results. Webhooks are signed with
+// HMAC-SHA256 over the raw body.
+type FakeProvider struct {
+	secret []byte
+}
+
+// NewFakeProvider refuses an empty or short secret, which would let anyone
+// sign webhooks.
+func NewFakeProvider(webhookSecret string) (*FakeProvider, error) {
+	if len(webhookSecret) < minWebhookSecretLen {
+		return nil, fmt.Errorf("payment webhook secret must be at least %d characters", minWebhookSecretLen)
+	}
+	return &FakeProvider{secret: []byte(webhookSecret)}, nil
+}
+
+func (f *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Payment, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("payment amount must be positive")
+	}
+	if req.PaymentMethod == FakeMethodDecline {
+		return nil, ErrPaymentDeclined
+	}
+
+	sum := sha256.Sum256([]byte(req.BookingID))
+	prefix := "pay_"
+	if req.PaymentMethod == FakeMethodCaptureFail {
+		prefix = fakeCaptureFailPrefix
+	}
+
+	return &Payment{
+		ID:        prefix + hex.EncodeToString(sum[:8]),
+		BookingID: req.BookingID,
+		UserID:    req.UserID,
+		Amount:    req.Amount,
+		Status:    PaymentAuthorized,
+	}, nil
+}
+
+func (f *FakeProvider) Capture(ctx context.Context, paymentID string, amount float64) (*Payment, error) {
+	if strings.HasPrefix(paymentID, fakeCaptureFailPrefix) {
+		return nil, ErrCaptureFailed
+	}
+	return &Payment{ID: paymentID, Amount: amount, Status: PaymentCaptured}, nil
+}
+
+func (f *FakeProvider) Refund(ctx context.Context, paymentID string, amount float64) error {
+	if paymentID == "" {
+		return errors.New("payment id is required")
+	}
+	if amount < 0 {
+		return errors.New("refund amount cannot be negative")
+	}
+	return nil
+}
+
+func (f *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	expected := f.Sign(payload)
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return &event, nil
+}
+
+// Sign returns the signature the fake gateway would send with payload.
+func (f *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package authtokenrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Single-use tokens mailed to users, such as password reset links, are
+// AUTH_TOKEN#<purpose>#<hash> items keyed by a hash of the token, so the
+// token itself is never stored. expires_at is the table's TTL attribute.
+
+type AuthTokenRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewAuthTokenRepositoryDDB(db *dynamodb.Client, tableName string) *AuthTokenRepositoryDDB {
+	return &AuthTokenRepositoryDDB{db: db, TableName: tableName}
+}
+
+type AuthTokenDDB struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	Purpose   string `dynamodbav:"purpose"`
+	UserEmail string `dynamodbav:"user_email"`
+	ExpiresAt int64  `dynamodbav:"expires_at"`
+}
+
+func tokenKey(purpose, tokenHash string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "AUTH_TOKEN#" + purpose + "#" + tokenHash},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+func (tr *AuthTokenRepositoryDDB) Create(ctx context.Context, purpose, tokenHash, email string, expiresAt time.Time) error {
+	item, err := attributevalue.MarshalMap(AuthTokenDDB{
+		PK:        "AUTH_TOKEN#" + purpose + "#" + tokenHash,
+		SK:        "DETAILS",
+		Purpose:   purpose,
+		UserEmail: email,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := tr.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(tr.TableName),
+		Item:      item,
+	}); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+	return nil
+}
+
+// Get returns the email the token was issued to without using it up.
+func (tr *AuthTokenRepositoryDDB) Get(ctx context.Context, purpose, tokenHash string) (string, error) {
+	out, err := tr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(tr.TableName),
+		Key:       tokenKey(purpose, tokenHash),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+
+	var item AuthTokenDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return "", fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	if item.UserEmail == "" || item.ExpiresAt <= time.Now().Unix() {
+		return "", models.ErrInvalidAuthToken
+	}
+	return item.UserEmail, nil
+}
+
+// Consume deletes the token and returns the email it was issued to. A token
+// can only be consumed once, and not after it expires even if the TTL has
+// not removed it yet.
+func (tr *AuthTokenRepositoryDDB) Consume(ctx context.Context, purpose, tokenHash string) (string, error) {
+	out, err := tr.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(tr.TableName),
+		Key:                 tokenKey(purpose, tokenHash),
+		ConditionExpression: aws.String("attribute_exists(pk) AND expires_at > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return "", models.ErrInvalidAuthToken
+		}
+		return "", fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	var item AuthTokenDDB
+	if err := attributevalue.UnmarshalMap(out.Attributes, &item); err != nil {
+		return "", fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	return item.UserEmail, nil
+}
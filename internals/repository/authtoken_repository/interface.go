@@ -0,0 +1,13 @@
+package authtokenrepository
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/authtoken_repository_mock.go -package=mocks -source=interface.go
+type AuthTokenRepositoryI interface {
+	Create(ctx context.Context, purpose, tokenHash, email string, expiresAt time.Time) error
+	Get(ctx context.Context, purpose, tokenHash string) (string, error)
+	Consume(ctx context.Context, purpose, tokenHash string) (string, error)
+}
@@ -2,9 +2,13 @@ package bookingrepository
 
 import (
 	"context"
+	"errors"
 	"eventro_aws/internals/models"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	reportrepository "eventro_aws/internals/repository/report_repository"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -12,25 +16,69 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// DynamoDB allows at most maxTransactItems items in one transaction. Booking
+// or holding seats writes one item per seat, next to a few fixed ones such as
+// the booking, the show and the hold, so the number of seats is capped to fit.
+const (
+	maxTransactItems  = 100
+	bookingFixedItems = 10
+
+	// MaxSeatsPerBooking is the most seats one booking or hold can cover.
+	MaxSeatsPerBooking = maxTransactItems - bookingFixedItems
+)
+
 type BookingRepositoryDDB struct {
 	db        *dynamodb.Client
 	TableName string
+
+	showIndexReady bool
 }
 
 type UserBookingDDB struct {
-	UserEmail             string   `dynamodbav:"pk"`
-	BookingDate_BookingID string   `dynamodbav:"sk"`
-	ShowID                string   `dynamodbav:"show_id"`
-	TimeBooked            string   `dynamodbav:"time_booked"`
-	NumTicketsBooked      int      `dynamodbav:"num_tickets_booked"`
-	TotalPrice            float64  `dynamodbav:"total_price"`
-	Seats                 []string `dynamodbav:"seats"`
-	VenueCity             string   `dynamodbav:"venue_city"`
-	VenueName             string   `dynamodbav:"venue_name"`
-	VenueState            string   `dynamodbav:"venue_state"`
-	EventName             string   `dynamodbav:"event_name"`
-	EventDuration         string   `dynamodbav:"event_duration"`
-	EventID               string   `dynamodbav:"event_id"`
+	UserEmail             string                `dynamodbav:"pk"`
+	BookingDate_BookingID string                `dynamodbav:"sk"`
+	ShowID                string                `dynamodbav:"show_id"`
+	TimeBooked            string                `dynamodbav:"time_booked"`
+	NumTicketsBooked      int                   `dynamodbav:"num_tickets_booked"`
+	OriginalPrice         float64               `dynamodbav:"original_price"`
+	Discount              float64               `dynamodbav:"discount"`
+	PromoCode             string                `dynamodbav:"promo_code,omitempty"`
+	TotalPrice            float64               `dynamodbav:"total_price"`
+	Seats                 []string              `dynamodbav:"seats"`
+	LineItems             []models.SeatLineItem `dynamodbav:"line_items,omitempty"`
+	VenueCity             string                `dynamodbav:"venue_city"`
+	VenueName             string                `dynamodbav:"venue_name"`
+	VenueState            string                `dynamodbav:"venue_state"`
+	EventName             string                `dynamodbav:"event_name"`
+	EventDuration         string                `dynamodbav:"event_duration"`
+	EventID               string                `dynamodbav:"event_id"`
+	Status                string                `dynamodbav:"status"`
+	PaymentID             string                `dynamodbav:"payment_id,omitempty"`
+	CancelledAt           string                `dynamodbav:"cancelled_at,omitempty"`
+	CancelledBy           string                `dynamodbav:"cancelled_by,omitempty"`
+	CancelReason          string                `dynamodbav:"cancel_reason,omitempty"`
+	RefundAmount          float64               `dynamodbav:"refund_amount,omitempty"`
+	TicketVersion         int                   `dynamodbav:"ticket_version,omitempty"`
+	TransferredFrom       string                `dynamodbav:"transferred_from,omitempty"`
+	CancelledSeats        []string              `dynamodbav:"cancelled_seats,omitempty"`
+	OriginalPaymentID     string                `dynamodbav:"original_payment_id,omitempty"`
+	SaleDate              string                `dynamodbav:"sale_date,omitempty"`
+}
+
+// ShowBookingDDB indexes a booking under its show so a show's bookings can be
+// found without scanning every user.
+type ShowBookingDDB struct {
+	ShowPK    string `dynamodbav:"pk"`
+	BookingSK string `dynamodbav:"sk"`
+	UserID    string `dynamodbav:"user_id"`
+	UserSK    string `dynamodbav:"booking_sk"`
+}
+
+func showBookingKey(showID, bookingID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+		"sk": &types.AttributeValueMemberS{Value: "BOOKING#" + bookingID},
+	}
 }
 
 func NewBookingRepositoryDDB(db *dynamodb.Client, tableName string) *BookingRepositoryDDB {
@@ -61,6 +109,7 @@ func (br *BookingRepositoryDDB) Create(ctx context.Context, booking *models.Book
 		City         string  `dynamodbav:"city"`
 		ShowDateTime string  `dynamodbav:"show_date_time"`
 		Price        float64 `dynamodbav:"price"`
+		HostID       string  `dynamodbav:"host_id"`
 	}
 	if err := attributevalue.UnmarshalMap(showOut.Item, &showDDB); err != nil {
 		return err
@@ -127,14 +176,27 @@ func (br *BookingRepositoryDDB) Create(ctx context.Context, booking *models.Book
 		ShowID:                booking.ShowID,
 		TimeBooked:            booking.TimeBooked.String(),
 		NumTicketsBooked:      booking.NumTickets,
+		OriginalPrice:         booking.OriginalPrice,
+		Discount:              booking.Discount,
 		TotalPrice:            booking.TotalBookingPrice,
 		Seats:                 booking.Seats,
+		LineItems:             booking.LineItems,
 		VenueCity:             venueDDB.City,
 		VenueName:             venueDDB.Name,
 		VenueState:            venueDDB.State,
 		EventName:             eventDDB.Name,
 		EventDuration:         eventDDB.Duration,
 		EventID:               showDDB.EventID,
+		Status:                booking.Status,
+	}
+	if bookingDDB.Status == "" {
+		bookingDDB.Status = models.BookingConfirmed
+	}
+	if bookingDDB.Status == models.BookingConfirmed {
+		bookingDDB.SaleDate = reportrepository.SaleDate(booking.TimeBooked)
+	}
+	if booking.Promo != nil {
+		bookingDDB.PromoCode = booking.Promo.Code
 	}
 
 	item, err := attributevalue.MarshalMap(bookingDDB)
@@ -142,12 +204,196 @@ func (br *BookingRepositoryDDB) Create(ctx context.Context, booking *models.Book
 		return err
 	}
 
-	_, err = br.db.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(br.TableName),
-		Item:      item,
+	indexItem, err := attributevalue.MarshalMap(ShowBookingDDB{
+		ShowPK:    showPK,
+		BookingSK: "BOOKING#" + booking.BookingID,
+		UserID:    booking.UserID,
+		UserSK:    compositeKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	txItems := []types.TransactWriteItem{
+		{
+			Put: &types.Put{
+				TableName:           aws.String(br.TableName),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(pk)"),
+			},
+		},
+		{Update: seatBookingUpdate(br.TableName, booking.ShowID, showDDB.ShowDateTime, booking.Seats)},
+	}
+
+	// every seat must either be free of holds or held by this booking's hold;
+	// confirming a hold also consumes its seat and hold records
+	now := time.Now().Unix()
+	for _, seat := range booking.Seats {
+		if booking.HoldID == "" {
+			txItems = append(txItems, types.TransactWriteItem{
+				ConditionCheck: seatNotHeldCheck(br.TableName, booking.ShowID, seat, now),
+			})
+			continue
+		}
+		txItems = append(txItems, types.TransactWriteItem{
+			Delete: heldSeatDelete(br.TableName, booking.ShowID, seat, booking.HoldID, now),
+		})
+	}
+	if booking.HoldID != "" {
+		txItems = append(txItems, types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName: aws.String(br.TableName),
+				Key:       holdKey(booking.UserID, booking.HoldID),
+			},
+		})
+	}
+
+	txItems = append(txItems, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(br.TableName),
+			Item:      indexItem,
+		},
+	})
+
+	if bookingDDB.Status == models.BookingPendingPayment {
+		pendingItem, err := attributevalue.MarshalMap(PendingPaymentDDB{
+			PK:        "PENDING_PAYMENTS",
+			SK:        "BOOKING#" + booking.BookingID,
+			UserID:    booking.UserID,
+			BookingID: booking.BookingID,
+			DueAt:     booking.PaymentDueAt.Unix(),
+		})
+		if err != nil {
+			return err
+		}
+		txItems = append(txItems, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(br.TableName),
+				Item:      pendingItem,
+			},
+		})
+	}
+
+	promoIdx := len(txItems)
+	if booking.Promo != nil {
+		txItems = append(txItems, promorepository.RedemptionItems(br.TableName, booking.Promo, booking.UserID, booking.TimeBooked)...)
+	}
+	if bookingDDB.Status == models.BookingConfirmed && showDDB.HostID != "" {
+		txItems = append(txItems, reportrepository.SaleItems(br.TableName, showDDB.HostID, booking.ShowID,
+			booking.TimeBooked, len(booking.Seats), booking.TotalBookingPrice)...)
+	}
+
+	_, err = br.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: txItems,
 	})
+	if err != nil {
+		if booking.HoldID != "" && txItemsFailed(err, 2, len(booking.Seats)) {
+			return models.ErrHoldExpired
+		}
+		if booking.Promo != nil && txItemsFailed(err, promoIdx, len(txItems)-promoIdx) {
+			return models.ErrPromoUnavailable
+		}
+		return seatConflictFromTxError(err, booking.Seats, 1, 2)
+	}
+
+	booking.BookingDate = showDDB.ShowDateTime
+	return nil
+}
+
+// seatBookingUpdate appends seats to the show's booked_seats, failing if any of
+// them is already present. It also fails if the show was cancelled or moved
+// away from showDateTime, which is baked into the booking's sort key.
+func seatBookingUpdate(tableName, showID, showDateTime string, seats []string) *types.Update {
+	values := map[string]types.AttributeValue{
+		":newSeats":  &types.AttributeValueMemberL{Value: toAVList(seats)},
+		":empty":     &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		":sdt":       &types.AttributeValueMemberS{Value: showDateTime},
+		":cancelled": &types.AttributeValueMemberS{Value: models.ShowCancelled},
+	}
+
+	conditions := []string{
+		"attribute_exists(pk)",
+		"show_date_time = :sdt",
+		"(attribute_not_exists(#status) OR #status <> :cancelled)",
+	}
+	for i, seat := range seats {
+		key := fmt.Sprintf(":seat%d", i)
+		values[key] = &types.AttributeValueMemberS{Value: seat}
+		conditions = append(conditions, "NOT contains(booked_seats, "+key+")")
+	}
+
+	return &types.Update{
+		TableName: aws.String(tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		UpdateExpression:                    aws.String("SET booked_seats = list_append(if_not_exists(booked_seats, :empty), :newSeats)"),
+		ConditionExpression:                 aws.String(strings.Join(conditions, " AND ")),
+		ExpressionAttributeNames:            map[string]string{"#status": "status"},
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+}
+
+// seatConflictFromTxError turns a cancelled transaction into a
+// SeatConflictError. The show item at showIdx reports seats already booked and
+// the per-seat items starting at seatIdx (one per requested seat, -1 if absent)
+// report seats held by someone else.
+func seatConflictFromTxError(err error, requested []string, showIdx, seatIdx int) error {
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) || len(tce.CancellationReasons) <= showIdx {
+		return fmt.Errorf("booking transaction failed: %w", err)
+	}
+
+	contested := make(map[string]bool)
 
-	return err
+	reason := tce.CancellationReasons[showIdx]
+	if aws.ToString(reason.Code) == "ConditionalCheckFailed" && reason.Item != nil {
+		var show struct {
+			BookedSeats []string `dynamodbav:"booked_seats"`
+		}
+		if uerr := attributevalue.UnmarshalMap(reason.Item, &show); uerr == nil {
+			taken := make(map[string]bool, len(show.BookedSeats))
+			for _, s := range show.BookedSeats {
+				taken[s] = true
+			}
+			for _, s := range requested {
+				if taken[s] {
+					contested[s] = true
+				}
+			}
+		}
+	}
+
+	if seatIdx >= 0 {
+		for i, s := range requested {
+			idx := seatIdx + i
+			if idx < len(tce.CancellationReasons) && aws.ToString(tce.CancellationReasons[idx].Code) == "ConditionalCheckFailed" {
+				contested[s] = true
+			}
+		}
+	}
+
+	if len(contested) == 0 {
+		return fmt.Errorf("booking transaction failed: %w", err)
+	}
+
+	seats := make([]string, 0, len(contested))
+	for _, s := range requested {
+		if contested[s] {
+			seats = append(seats, s)
+		}
+	}
+	return &models.SeatConflictError{Seats: seats}
+}
+
+func toAVList(strs []string) []types.AttributeValue {
+	avs := make([]types.AttributeValue, 0, len(strs))
+	for _, s := range strs {
+		avs = append(avs, &types.AttributeValueMemberS{Value: s})
+	}
+	return avs
 }
 
 func (r *BookingRepositoryDDB) ListByUser(ctx context.Context, userID string) ([]models.UserBookingDTO, error) {
@@ -177,26 +423,7 @@ func (r *BookingRepositoryDDB) ListByUser(ctx context.Context, userID string) ([
 	var dtoList []models.UserBookingDTO
 
 	for _, b := range bookingRecords {
-		parts := strings.Split(b.BookingDate_BookingID, "#")
-
-		dto := models.UserBookingDTO{
-			UserEmail:        b.UserEmail,
-			BookingDate:      parts[1],
-			BookingID:        parts[3],
-			ShowID:           b.ShowID,
-			TimeBooked:       b.TimeBooked,
-			NumTicketsBooked: b.NumTicketsBooked,
-			TotalPrice:       b.TotalPrice,
-			Seats:            b.Seats,
-			VenueCity:        b.VenueCity,
-			VenueName:        b.VenueName,
-			VenueState:       b.VenueState,
-			EventName:        b.EventName,
-			EventDuration:    b.EventDuration,
-			EventID:          b.EventID,
-		}
-
-		dtoList = append(dtoList, dto)
+		dtoList = append(dtoList, toUserBookingDTO(b))
 	}
 
 	if dtoList == nil {
@@ -204,3 +431,686 @@ func (r *BookingRepositoryDDB) ListByUser(ctx context.Context, userID string) ([
 	}
 	return dtoList, nil
 }
+
+func toUserBookingDTO(b UserBookingDDB) models.UserBookingDTO {
+	parts := strings.Split(b.BookingDate_BookingID, "#")
+
+	status := b.Status
+	if status == "" {
+		status = models.BookingConfirmed
+	}
+
+	return models.UserBookingDTO{
+		UserEmail:        b.UserEmail,
+		BookingDate:      parts[1],
+		BookingID:        parts[3],
+		ShowID:           b.ShowID,
+		TimeBooked:       b.TimeBooked,
+		NumTicketsBooked: b.NumTicketsBooked,
+		OriginalPrice:    b.OriginalPrice,
+		Discount:         b.Discount,
+		PromoCode:        b.PromoCode,
+		TotalPrice:       b.TotalPrice,
+		Seats:            b.Seats,
+		LineItems:        b.LineItems,
+		VenueCity:        b.VenueCity,
+		VenueName:        b.VenueName,
+		VenueState:       b.VenueState,
+		EventName:        b.EventName,
+		EventDuration:    b.EventDuration,
+		EventID:          b.EventID,
+		Status:           status,
+		PaymentID:        b.PaymentID,
+		CancelledAt:      b.CancelledAt,
+		CancelledBy:      b.CancelledBy,
+		CancelReason:     b.CancelReason,
+		RefundAmount:     b.RefundAmount,
+		TicketVersion:    b.TicketVersion,
+		TransferredFrom:  b.TransferredFrom,
+		CancelledSeats:   b.CancelledSeats,
+
+		OriginalPaymentID: b.OriginalPaymentID,
+		SaleDate:          b.SaleDate,
+	}
+}
+
+func bookingKey(userID, bookingDate, bookingID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"sk": &types.AttributeValueMemberS{Value: "BOOKED_SHOW_DATE#" + bookingDate + "#BOOKINGID#" + bookingID},
+	}
+}
+
+func (r *BookingRepositoryDDB) GetByID(ctx context.Context, userID, bookingID string) (*models.UserBookingDTO, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :skPrefix)"),
+		FilterExpression:       aws.String("contains(sk, :bid)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":       &types.AttributeValueMemberS{Value: "USER#" + userID},
+			":skPrefix": &types.AttributeValueMemberS{Value: "BOOKED_SHOW_DATE#"},
+			":bid":      &types.AttributeValueMemberS{Value: "#BOOKINGID#" + bookingID},
+		},
+	}
+
+	for {
+		result, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query booking: %w", err)
+		}
+		if len(result.Items) > 0 {
+			var b UserBookingDDB
+			if err := attributevalue.UnmarshalMap(result.Items[0], &b); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal booking: %w", err)
+			}
+			dto := toUserBookingDTO(b)
+			return &dto, nil
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// Cancel marks the booking as cancelled, adds the refund to any refund from
+// earlier seat cancellations, removes its seats from the show's booked_seats,
+// gives back its promo redemption and queues owed, the refund still to be
+// paid if any, in one transaction.
+func (r *BookingRepositoryDDB) Cancel(ctx context.Context, userID string, booking *models.UserBookingDTO, refund float64, owed *models.PendingRefund, cancelledBy, reason string) error {
+	update := &types.Update{
+		TableName:           aws.String(r.TableName),
+		Key:                 bookingKey(userID, booking.BookingDate, booking.BookingID),
+		UpdateExpression:    aws.String("SET #status = :cancelled, cancelled_at = :at, cancelled_by = :by, refund_amount = if_not_exists(refund_amount, :zero) + :refund, cancel_reason = :reason"),
+		ConditionExpression: aws.String("attribute_exists(pk) AND (attribute_not_exists(#status) OR #status <> :cancelled)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cancelled": &types.AttributeValueMemberS{Value: models.BookingCancelled},
+			":at":        &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+			":by":        &types.AttributeValueMemberS{Value: cancelledBy},
+			":zero":      &types.AttributeValueMemberN{Value: "0"},
+			":refund":    &types.AttributeValueMemberN{Value: fmt.Sprint(refund)},
+			":reason":    &types.AttributeValueMemberS{Value: reason},
+		},
+	}
+	stats, err := r.cancellationStats(ctx, booking, len(booking.Seats), refund)
+	if err != nil {
+		return err
+	}
+	queued, err := pendingRefundPut(r.TableName, owed)
+	if err != nil {
+		return err
+	}
+	extra := append(stats, r.promoRelease(userID, booking)...)
+	extra = append(extra, queued...)
+	return r.updateAndReleaseSeats(ctx, booking, update, errors.New("booking is already cancelled"), extra...)
+}
+
+// CancelSeats cancels some of a booking's seats: the booking is cut down to
+// kept with the refund added to its refund_amount, and the cancelled seats
+// are removed from the show's booked_seats and owed is queued in the same
+// transaction.
+func (r *BookingRepositoryDDB) CancelSeats(ctx context.Context, userID string, booking, kept *models.UserBookingDTO, seats []string, refund float64, owed *models.PendingRefund) error {
+	update, err := seatSplitUpdate(r.TableName, bookingKey(userID, booking.BookingDate, booking.BookingID), booking, seats, kept,
+		[]string{
+			"refund_amount = if_not_exists(refund_amount, :zero) + :refund",
+			"cancelled_seats = list_append(if_not_exists(cancelled_seats, :none), :cancelled)",
+		},
+		map[string]types.AttributeValue{
+			":zero":      &types.AttributeValueMemberN{Value: "0"},
+			":refund":    &types.AttributeValueMemberN{Value: fmt.Sprint(refund)},
+			":none":      &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+			":cancelled": &types.AttributeValueMemberL{Value: toAVList(seats)},
+		})
+	if err != nil {
+		return err
+	}
+
+	stats, err := r.cancellationStats(ctx, booking, len(seats), refund)
+	if err != nil {
+		return err
+	}
+
+	queued, err := pendingRefundPut(r.TableName, owed)
+	if err != nil {
+		return err
+	}
+
+	released := *booking
+	released.Seats = seats
+	return r.updateAndReleaseSeats(ctx, &released, update, errors.New("the booking has changed, fetch it and try again"), append(stats, queued...)...)
+}
+
+// seatSplitUpdate builds an update taking seats out of the booking, leaving
+// kept (nil when no seats remain), and moving it to the next ticket_version
+// so tickets issued before the change stop working. It only applies while
+// the booking is confirmed, still holds every seat and is at the ticket
+// version it was read at. sets and values are added to the update.
+func seatSplitUpdate(tableName string, key map[string]types.AttributeValue, booking *models.UserBookingDTO, seats []string, kept *models.UserBookingDTO, sets []string, values map[string]types.AttributeValue) (*types.Update, error) {
+	values[":confirmed"] = &types.AttributeValueMemberS{Value: models.BookingConfirmed}
+	values[":nextv"] = &types.AttributeValueMemberN{Value: fmt.Sprint(booking.TicketVersion + 1)}
+	sets = append(sets, "ticket_version = :nextv")
+
+	conditions := []string{"#status = :confirmed"}
+	if booking.TicketVersion == 0 {
+		conditions = append(conditions, "attribute_not_exists(ticket_version)")
+	} else {
+		conditions = append(conditions, "ticket_version = :v")
+		values[":v"] = &types.AttributeValueMemberN{Value: fmt.Sprint(booking.TicketVersion)}
+	}
+	for i, seat := range seats {
+		name := fmt.Sprintf(":seat%d", i)
+		conditions = append(conditions, fmt.Sprintf("contains(seats, %s)", name))
+		values[name] = &types.AttributeValueMemberS{Value: seat}
+	}
+
+	if kept != nil {
+		lineItems, err := attributevalue.Marshal(kept.LineItems)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, "seats = :seats", "num_tickets_booked = :n", "original_price = :original",
+			"discount = :discount", "total_price = :total", "line_items = :items")
+		values[":seats"] = &types.AttributeValueMemberL{Value: toAVList(kept.Seats)}
+		values[":n"] = &types.AttributeValueMemberN{Value: fmt.Sprint(kept.NumTicketsBooked)}
+		values[":original"] = &types.AttributeValueMemberN{Value: fmt.Sprint(kept.OriginalPrice)}
+		values[":discount"] = &types.AttributeValueMemberN{Value: fmt.Sprint(kept.Discount)}
+		values[":total"] = &types.AttributeValueMemberN{Value: fmt.Sprint(kept.TotalPrice)}
+		values[":items"] = lineItems
+	}
+
+	return &types.Update{
+		TableName:           aws.String(tableName),
+		Key:                 key,
+		UpdateExpression:    aws.String("SET " + strings.Join(sets, ", ")),
+		ConditionExpression: aws.String(strings.Join(conditions, " AND ")),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: values,
+	}, nil
+}
+
+// ConfirmPayment moves a pending booking to confirmed once its payment has
+// been captured and takes it off the pending payment queue.
+func (r *BookingRepositoryDDB) ConfirmPayment(ctx context.Context, userID string, booking *models.UserBookingDTO, paymentID string) error {
+	now := time.Now()
+	txItems := []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:           aws.String(r.TableName),
+				Key:                 bookingKey(userID, booking.BookingDate, booking.BookingID),
+				UpdateExpression:    aws.String("SET #status = :confirmed, payment_id = :pid, sale_date = :date"),
+				ConditionExpression: aws.String("#status = :pending"),
+				ExpressionAttributeNames: map[string]string{
+					"#status": "status",
+				},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":confirmed": &types.AttributeValueMemberS{Value: models.BookingConfirmed},
+					":pending":   &types.AttributeValueMemberS{Value: models.BookingPendingPayment},
+					":pid":       &types.AttributeValueMemberS{Value: paymentID},
+					":date":      &types.AttributeValueMemberS{Value: reportrepository.SaleDate(now)},
+				},
+			},
+		},
+		pendingPaymentDelete(r.TableName, booking.BookingID),
+	}
+
+	hostID, err := r.showHostID(ctx, booking.ShowID)
+	if err != nil {
+		return err
+	}
+	if hostID != "" {
+		txItems = append(txItems, reportrepository.SaleItems(r.TableName, hostID, booking.ShowID,
+			now, len(booking.Seats), booking.TotalPrice)...)
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: txItems,
+	})
+	if err != nil {
+		if txItemsFailed(err, 0, 1) {
+			return errors.New("booking is not awaiting payment")
+		}
+		return fmt.Errorf("failed to confirm payment: %w", err)
+	}
+	return nil
+}
+
+// showHostID returns the host of the show, or "" once the show item is gone.
+func (r *BookingRepositoryDDB) showHostID(ctx context.Context, showID string) (string, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		ProjectionExpression: aws.String("host_id"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch show: %w", err)
+	}
+
+	var show struct {
+		HostID string `dynamodbav:"host_id"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &show); err != nil {
+		return "", fmt.Errorf("failed to unmarshal show: %w", err)
+	}
+	return show.HostID, nil
+}
+
+// cancellationStats returns the transaction items recording cancelled seats
+// and their refund in the host's sales figures, against the show and the day
+// the booking was sold on.
+func (r *BookingRepositoryDDB) cancellationStats(ctx context.Context, booking *models.UserBookingDTO, seats int, refund float64) ([]types.TransactWriteItem, error) {
+	hostID, err := r.showHostID(ctx, booking.ShowID)
+	if err != nil || hostID == "" {
+		return nil, err
+	}
+	return reportrepository.CancellationItems(r.TableName, hostID, booking.ShowID, saleDate(booking), seats, refund), nil
+}
+
+// saleDate is the day the booking was counted as sold. Bookings from before
+// it was stored were sold when they were made, whose time starts with the
+// date.
+func saleDate(booking *models.UserBookingDTO) string {
+	if booking.SaleDate != "" {
+		return booking.SaleDate
+	}
+	if len(booking.TimeBooked) >= 10 {
+		if _, err := time.Parse("2006-01-02", booking.TimeBooked[:10]); err == nil {
+			return booking.TimeBooked[:10]
+		}
+	}
+	return ""
+}
+
+// FailPayment marks a pending booking as failed, gives its seats back to the
+// show and its promo redemption back to the user, and takes it off the
+// pending payment queue.
+func (r *BookingRepositoryDDB) FailPayment(ctx context.Context, userID string, booking *models.UserBookingDTO, paymentID string) error {
+	update := &types.Update{
+		TableName:           aws.String(r.TableName),
+		Key:                 bookingKey(userID, booking.BookingDate, booking.BookingID),
+		UpdateExpression:    aws.String("SET #status = :failed, payment_id = :pid"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":failed":  &types.AttributeValueMemberS{Value: models.BookingFailed},
+			":pending": &types.AttributeValueMemberS{Value: models.BookingPendingPayment},
+			":pid":     &types.AttributeValueMemberS{Value: paymentID},
+		},
+	}
+	extra := append(r.promoRelease(userID, booking), pendingPaymentDelete(r.TableName, booking.BookingID))
+	return r.updateAndReleaseSeats(ctx, booking, update, errors.New("booking is not awaiting payment"), extra...)
+}
+
+// promoRelease returns the items undoing the promo redemption counted when
+// the booking was created, if it used a code.
+func (r *BookingRepositoryDDB) promoRelease(userID string, booking *models.UserBookingDTO) []types.TransactWriteItem {
+	if booking.PromoCode == "" {
+		return nil
+	}
+	return promorepository.ReleaseItems(r.TableName, booking.PromoCode, userID)
+}
+
+// updateAndReleaseSeats applies bookingUpdate and removes the booking's seats
+// from the show's booked_seats in one transaction. The seats are removed by
+// list index, so each index is checked to still hold the expected seat; if
+// the list moved underneath us the transaction is retried with fresh indexes.
+// conditionErr is returned when bookingUpdate's own condition fails. extra
+// items are written in the same transaction.
+func (r *BookingRepositoryDDB) updateAndReleaseSeats(ctx context.Context, booking *models.UserBookingDTO, bookingUpdate *types.Update, conditionErr error, extra ...types.TransactWriteItem) error {
+	const maxAttempts = 3
+
+	for attempt := 1; ; attempt++ {
+		showUpdate, err := r.seatReleaseUpdate(ctx, booking.ShowID, booking.Seats)
+		if err != nil {
+			return err
+		}
+
+		txItems := []types.TransactWriteItem{{Update: bookingUpdate}}
+		if showUpdate != nil {
+			txItems = append(txItems, types.TransactWriteItem{Update: showUpdate})
+		}
+		txItems = append(txItems, extra...)
+
+		_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: txItems,
+		})
+		if err == nil {
+			return nil
+		}
+
+		var tce *types.TransactionCanceledException
+		if !errors.As(err, &tce) || len(tce.CancellationReasons) == 0 {
+			return fmt.Errorf("booking transaction failed: %w", err)
+		}
+		if aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return conditionErr
+		}
+		if attempt == maxAttempts {
+			return fmt.Errorf("booking transaction failed: %w", err)
+		}
+	}
+}
+
+// seatReleaseUpdate builds an update removing seats from the show's
+// booked_seats. It returns nil when none of the seats are booked any more.
+func (r *BookingRepositoryDDB) seatReleaseUpdate(ctx context.Context, showID string, seats []string) (*types.Update, error) {
+	key := map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(r.TableName),
+		Key:                  key,
+		ConsistentRead:       aws.Bool(true),
+		ProjectionExpression: aws.String("booked_seats"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch show: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var show struct {
+		BookedSeats []string `dynamodbav:"booked_seats"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &show); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal show: %w", err)
+	}
+
+	release := make(map[string]bool, len(seats))
+	for _, s := range seats {
+		release[s] = true
+	}
+
+	var removals, conditions []string
+	values := map[string]types.AttributeValue{}
+	for i, s := range show.BookedSeats {
+		if !release[s] {
+			continue
+		}
+		key := fmt.Sprintf(":seat%d", i)
+		removals = append(removals, fmt.Sprintf("booked_seats[%d]", i))
+		conditions = append(conditions, fmt.Sprintf("booked_seats[%d] = %s", i, key))
+		values[key] = &types.AttributeValueMemberS{Value: s}
+	}
+	if len(removals) == 0 {
+		return nil, nil
+	}
+
+	return &types.Update{
+		TableName:                 aws.String(r.TableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("REMOVE " + strings.Join(removals, ", ")),
+		ConditionExpression:       aws.String(strings.Join(conditions, " AND ")),
+		ExpressionAttributeValues: values,
+	}, nil
+}
+
+// ListByShow returns every booking made for the show through the show's
+// booking index, adding those made before the index until it is backfilled.
+func (r *BookingRepositoryDDB) ListByShow(ctx context.Context, showID string) ([]models.ShowBooking, error) {
+	bookings, err := r.listIndexedShowBookings(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+	if complete, err := r.showIndexComplete(ctx); err != nil || complete {
+		return bookings, err
+	}
+
+	older, err := r.scanShowBookings(ctx, showID, "")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(bookings))
+	for _, b := range bookings {
+		seen[b.Booking.BookingID] = true
+	}
+	for _, b := range older {
+		if !seen[b.Booking.BookingID] {
+			bookings = append(bookings, b)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *BookingRepositoryDDB) listIndexedShowBookings(ctx context.Context, showID string) ([]models.ShowBooking, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			":sk": &types.AttributeValueMemberS{Value: "BOOKING#"},
+		},
+	}
+
+	var refs []ShowBookingDDB
+	for {
+		out, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query show bookings: %w", err)
+		}
+		var page []ShowBookingDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal show bookings: %w", err)
+		}
+		refs = append(refs, page...)
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	bookings := make([]models.ShowBooking, 0, len(refs))
+	for start := 0; start < len(refs); start += 100 {
+		end := start + 100
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		keys := make([]map[string]types.AttributeValue, 0, end-start)
+		for _, ref := range refs[start:end] {
+			keys = append(keys, map[string]types.AttributeValue{
+				"pk": &types.AttributeValueMemberS{Value: "USER#" + ref.UserID},
+				"sk": &types.AttributeValueMemberS{Value: ref.UserSK},
+			})
+		}
+
+		req := &dynamodb.BatchGetItemInput{
+			RequestItems: map[string]types.KeysAndAttributes{
+				r.TableName: {Keys: keys},
+			},
+		}
+		for {
+			resp, err := r.db.BatchGetItem(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("batch get bookings failed: %w", err)
+			}
+
+			for _, item := range resp.Responses[r.TableName] {
+				var b UserBookingDDB
+				if err := attributevalue.UnmarshalMap(item, &b); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal booking: %w", err)
+				}
+				bookings = append(bookings, models.ShowBooking{
+					UserID:  strings.TrimPrefix(b.UserEmail, "USER#"),
+					Booking: toUserBookingDTO(b),
+				})
+			}
+
+			unprocessed := resp.UnprocessedKeys
+			if len(unprocessed) == 0 || len(unprocessed[r.TableName].Keys) == 0 {
+				break
+			}
+			req.RequestItems = unprocessed
+		}
+	}
+
+	return bookings, nil
+}
+
+// GetShowBooking finds a booking from its show through the show's booking
+// index, falling back to a scan until the index is backfilled.
+func (r *BookingRepositoryDDB) GetShowBooking(ctx context.Context, showID, bookingID string) (*models.ShowBooking, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       showBookingKey(showID, bookingID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get show booking: %w", err)
+	}
+	if out.Item == nil {
+		if complete, err := r.showIndexComplete(ctx); err != nil || complete {
+			return nil, err
+		}
+		older, err := r.scanShowBookings(ctx, showID, bookingID)
+		if err != nil || len(older) == 0 {
+			return nil, err
+		}
+		return &older[0], nil
+	}
+
+	var ref ShowBookingDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &ref); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal show booking: %w", err)
+	}
+
+	out, err = r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USER#" + ref.UserID},
+			"sk": &types.AttributeValueMemberS{Value: ref.UserSK},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var b UserBookingDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &b); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal booking: %w", err)
+	}
+	return &models.ShowBooking{UserID: ref.UserID, Booking: toUserBookingDTO(b)}, nil
+}
+
+// MoveShowDate rewrites the booking under a sort key for the show's new date
+// and time and points the show's booking index at it. The old item is only
+// replaced while it still has the status and ticket version it was copied
+// with, so a cancellation, payment or transfer landing in between is not
+// overwritten; the move is then retried from a fresh copy.
+func (r *BookingRepositoryDDB) MoveShowDate(ctx context.Context, userID string, booking *models.UserBookingDTO, newShowDateTime string) error {
+	const maxAttempts = 3
+
+	if booking.BookingDate == newShowDateTime {
+		return nil
+	}
+
+	oldKey := bookingKey(userID, booking.BookingDate, booking.BookingID)
+	newKey := bookingKey(userID, newShowDateTime, booking.BookingID)
+	for attempt := 1; ; attempt++ {
+		out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+			TableName:      aws.String(r.TableName),
+			Key:            oldKey,
+			ConsistentRead: aws.Bool(true),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch booking: %w", err)
+		}
+		if out.Item == nil {
+			return r.checkMoved(ctx, booking, newKey, newShowDateTime)
+		}
+
+		item := out.Item
+		item["sk"] = newKey["sk"]
+
+		values := map[string]types.AttributeValue{}
+		conditions := []string{"attribute_exists(pk)"}
+		if status, ok := item["status"]; ok {
+			conditions = append(conditions, "#status = :status")
+			values[":status"] = status
+		} else {
+			conditions = append(conditions, "attribute_not_exists(#status)")
+		}
+		if version, ok := item["ticket_version"]; ok {
+			conditions = append(conditions, "ticket_version = :v")
+			values[":v"] = version
+		} else {
+			conditions = append(conditions, "attribute_not_exists(ticket_version)")
+		}
+		remove := &types.Delete{
+			TableName:           aws.String(r.TableName),
+			Key:                 oldKey,
+			ConditionExpression: aws.String(strings.Join(conditions, " AND ")),
+			ExpressionAttributeNames: map[string]string{
+				"#status": "status",
+			},
+		}
+		if len(values) > 0 {
+			remove.ExpressionAttributeValues = values
+		}
+
+		_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{
+				{
+					Put: &types.Put{
+						TableName:           aws.String(r.TableName),
+						Item:                item,
+						ConditionExpression: aws.String("attribute_not_exists(pk)"),
+					},
+				},
+				{Delete: remove},
+				{
+					Update: &types.Update{
+						TableName:        aws.String(r.TableName),
+						Key:              showBookingKey(booking.ShowID, booking.BookingID),
+						UpdateExpression: aws.String("SET booking_sk = :sk, user_id = :uid"),
+						ExpressionAttributeValues: map[string]types.AttributeValue{
+							":sk":  newKey["sk"],
+							":uid": &types.AttributeValueMemberS{Value: userID},
+						},
+					},
+				},
+			},
+		})
+		if err == nil {
+			booking.BookingDate = newShowDateTime
+			return nil
+		}
+		if !txItemsFailed(err, 0, 2) || attempt == maxAttempts {
+			return fmt.Errorf("failed to move booking %s: %w", booking.BookingID, err)
+		}
+	}
+}
+
+// checkMoved handles a booking missing from its old key: it is fine if it
+// has already been moved to the new one.
+func (r *BookingRepositoryDDB) checkMoved(ctx context.Context, booking *models.UserBookingDTO, newKey map[string]types.AttributeValue, newShowDateTime string) error {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:            aws.String(r.TableName),
+		Key:                  newKey,
+		ConsistentRead:       aws.Bool(true),
+		ProjectionExpression: aws.String("pk"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	if out.Item == nil {
+		return fmt.Errorf("booking not found: %s", booking.BookingID)
+	}
+	booking.BookingDate = newShowDateTime
+	return nil
+}
@@ -0,0 +1,225 @@
+package bookingrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Holds are stored as one HOLD_SEAT#<seat> item per seat under the show's
+// partition plus a HOLD#<id> record under the user's partition. Both carry
+// expires_at, so the table TTL removes them once the window has passed; until
+// then every condition treats an expired hold as a free seat.
+
+type HoldDDB struct {
+	UserPK    string   `dynamodbav:"pk"`
+	HoldSK    string   `dynamodbav:"sk"`
+	ShowID    string   `dynamodbav:"show_id"`
+	Seats     []string `dynamodbav:"seats"`
+	CreatedAt string   `dynamodbav:"created_at"`
+	ExpiresAt int64    `dynamodbav:"expires_at"`
+}
+
+type HeldSeatDDB struct {
+	ShowPK    string `dynamodbav:"pk"`
+	SeatSK    string `dynamodbav:"sk"`
+	HoldID    string `dynamodbav:"hold_id"`
+	UserID    string `dynamodbav:"user_id"`
+	ExpiresAt int64  `dynamodbav:"expires_at"`
+}
+
+func holdKey(userID, holdID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"sk": &types.AttributeValueMemberS{Value: "HOLD#" + holdID},
+	}
+}
+
+func heldSeatKey(showID, seat string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+		"sk": &types.AttributeValueMemberS{Value: "HOLD_SEAT#" + seat},
+	}
+}
+
+// seatNotHeldCheck passes when nobody holds the seat or the hold has expired.
+func seatNotHeldCheck(tableName, showID, seat string, now int64) *types.ConditionCheck {
+	return &types.ConditionCheck{
+		TableName:           aws.String(tableName),
+		Key:                 heldSeatKey(showID, seat),
+		ConditionExpression: aws.String("attribute_not_exists(pk) OR expires_at < :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(now)},
+		},
+	}
+}
+
+// heldSeatDelete consumes a seat that must still be held by holdID.
+func heldSeatDelete(tableName, showID, seat, holdID string, now int64) *types.Delete {
+	return &types.Delete{
+		TableName:           aws.String(tableName),
+		Key:                 heldSeatKey(showID, seat),
+		ConditionExpression: aws.String("hold_id = :hid AND expires_at >= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hid": &types.AttributeValueMemberS{Value: holdID},
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(now)},
+		},
+	}
+}
+
+// txItemsFailed reports whether any of the n transaction items starting at
+// from failed its condition in a cancelled transaction.
+func txItemsFailed(err error, from, n int) bool {
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return false
+	}
+	for i := from; i < from+n && i < len(tce.CancellationReasons); i++ {
+		if aws.ToString(tce.CancellationReasons[i].Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+func (br *BookingRepositoryDDB) CreateHold(ctx context.Context, hold *models.SeatHold) error {
+	now := time.Now().Unix()
+	expiresAt := hold.ExpiresAt.Unix()
+
+	values := map[string]types.AttributeValue{}
+	conditions := []string{"attribute_exists(pk)"}
+	for i, seat := range hold.Seats {
+		key := fmt.Sprintf(":seat%d", i)
+		values[key] = &types.AttributeValueMemberS{Value: seat}
+		conditions = append(conditions, "NOT contains(booked_seats, "+key+")")
+	}
+
+	txItems := []types.TransactWriteItem{
+		{
+			ConditionCheck: &types.ConditionCheck{
+				TableName: aws.String(br.TableName),
+				Key: map[string]types.AttributeValue{
+					"pk": &types.AttributeValueMemberS{Value: "SHOW#" + hold.ShowID},
+					"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+				},
+				ConditionExpression:                 aws.String(strings.Join(conditions, " AND ")),
+				ExpressionAttributeValues:           values,
+				ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+			},
+		},
+	}
+
+	for _, seat := range hold.Seats {
+		seatItem, err := attributevalue.MarshalMap(HeldSeatDDB{
+			ShowPK:    "SHOW#" + hold.ShowID,
+			SeatSK:    "HOLD_SEAT#" + seat,
+			HoldID:    hold.HoldID,
+			UserID:    hold.UserID,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		txItems = append(txItems, types.TransactWriteItem{
+			Put: &types.Put{
+				TableName:           aws.String(br.TableName),
+				Item:                seatItem,
+				ConditionExpression: aws.String("attribute_not_exists(pk) OR expires_at < :now"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":now": &types.AttributeValueMemberN{Value: fmt.Sprint(now)},
+				},
+			},
+		})
+	}
+
+	holdItem, err := attributevalue.MarshalMap(HoldDDB{
+		UserPK:    "USER#" + hold.UserID,
+		HoldSK:    "HOLD#" + hold.HoldID,
+		ShowID:    hold.ShowID,
+		Seats:     hold.Seats,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	txItems = append(txItems, types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(br.TableName),
+			Item:      holdItem,
+		},
+	})
+
+	_, err = br.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: txItems,
+	})
+	if err != nil {
+		return seatConflictFromTxError(err, hold.Seats, 0, 1)
+	}
+	return nil
+}
+
+func (br *BookingRepositoryDDB) GetHold(ctx context.Context, userID, holdID string) (*models.SeatHold, error) {
+	out, err := br.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(br.TableName),
+		Key:       holdKey(userID, holdID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var h HoldDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &h); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hold: %w", err)
+	}
+
+	return &models.SeatHold{
+		HoldID:    holdID,
+		ShowID:    h.ShowID,
+		UserID:    userID,
+		Seats:     h.Seats,
+		ExpiresAt: time.Unix(h.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+// ReleaseHold frees every seat still held by the hold and removes the hold
+// record. Seats already re-held by someone else after expiry are left alone.
+func (br *BookingRepositoryDDB) ReleaseHold(ctx context.Context, hold *models.SeatHold) error {
+	for _, seat := range hold.Seats {
+		_, err := br.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName:           aws.String(br.TableName),
+			Key:                 heldSeatKey(hold.ShowID, seat),
+			ConditionExpression: aws.String("hold_id = :hid"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":hid": &types.AttributeValueMemberS{Value: hold.HoldID},
+			},
+		})
+		if err != nil {
+			var cce *types.ConditionalCheckFailedException
+			if errors.As(err, &cce) {
+				continue
+			}
+			return fmt.Errorf("failed to release seat %s: %w", seat, err)
+		}
+	}
+
+	_, err := br.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(br.TableName),
+		Key:       holdKey(hold.UserID, hold.HoldID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete hold: %w", err)
+	}
+	return nil
+}
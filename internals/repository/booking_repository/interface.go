@@ -3,10 +3,34 @@ package bookingrepository
 import (
 	"context"
 	"eventro_aws/internals/models"
+	"time"
 )
 
 //go:generate mockgen -destination=../../mocks/booking_repository_mock.go -package=mocks -source=interface.go
 type BookingRepositoryI interface {
 	Create(ctx context.Context, booking *models.Booking) error
 	ListByUser(ctx context.Context, userID string) ([]models.UserBookingDTO, error)
+	GetByID(ctx context.Context, userID, bookingID string) (*models.UserBookingDTO, error)
+	Cancel(ctx context.Context, userID string, booking *models.UserBookingDTO, refund float64, owed *models.PendingRefund, cancelledBy, reason string) error
+	CancelSeats(ctx context.Context, userID string, booking, kept *models.UserBookingDTO, seats []string, refund float64, owed *models.PendingRefund) error
+	ListPendingRefunds(ctx context.Context, now time.Time) ([]models.PendingRefund, error)
+	ClaimRefund(ctx context.Context, refundID string, now, until time.Time) (bool, error)
+	ReleaseRefund(ctx context.Context, refundID string) error
+	CompleteRefund(ctx context.Context, refundID string) error
+	ListByShow(ctx context.Context, showID string) ([]models.ShowBooking, error)
+	GetShowBooking(ctx context.Context, showID, bookingID string) (*models.ShowBooking, error)
+	BackfillShowIndex(ctx context.Context) (int, error)
+	MoveShowDate(ctx context.Context, userID string, booking *models.UserBookingDTO, newShowDateTime string) error
+	ConfirmPayment(ctx context.Context, userID string, booking *models.UserBookingDTO, paymentID string) error
+	FailPayment(ctx context.Context, userID string, booking *models.UserBookingDTO, paymentID string) error
+	ListOverduePayments(ctx context.Context, now time.Time) ([]models.ShowBooking, error)
+	DropPendingPayment(ctx context.Context, bookingID string) error
+	CreateHold(ctx context.Context, hold *models.SeatHold) error
+	GetHold(ctx context.Context, userID, holdID string) (*models.SeatHold, error)
+	ReleaseHold(ctx context.Context, hold *models.SeatHold) error
+	CreateTransfer(ctx context.Context, transfer *models.BookingTransfer) error
+	GetTransfer(ctx context.Context, transferID string) (*models.BookingTransfer, error)
+	ListTransfers(ctx context.Context, userID string) (*models.TransferList, error)
+	UpdateTransferStatus(ctx context.Context, transfer *models.BookingTransfer, status string, event models.TransferEvent) error
+	AcceptTransfer(ctx context.Context, transfer *models.BookingTransfer, original, kept, received *models.UserBookingDTO, event models.TransferEvent) error
 }
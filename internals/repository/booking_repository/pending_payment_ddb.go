@@ -0,0 +1,98 @@
+package bookingrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Bookings awaiting payment are queued under PENDING_PAYMENTS until their
+// payment is confirmed or fails. The partition only holds bookings still in
+// flight, so the sweeper reads all of it and filters on due_at.
+
+type PendingPaymentDDB struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	UserID    string `dynamodbav:"user_id"`
+	BookingID string `dynamodbav:"booking_id"`
+	DueAt     int64  `dynamodbav:"due_at"`
+}
+
+func pendingPaymentKey(bookingID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "PENDING_PAYMENTS"},
+		"sk": &types.AttributeValueMemberS{Value: "BOOKING#" + bookingID},
+	}
+}
+
+func pendingPaymentDelete(tableName, bookingID string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Delete: &types.Delete{
+			TableName: aws.String(tableName),
+			Key:       pendingPaymentKey(bookingID),
+		},
+	}
+}
+
+// ListOverduePayments returns the bookings whose payment was due before now.
+// Queue entries whose booking no longer exists are dropped.
+func (r *BookingRepositoryDDB) ListOverduePayments(ctx context.Context, now time.Time) ([]models.ShowBooking, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		FilterExpression:       aws.String("due_at <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: "PENDING_PAYMENTS"},
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(now.Unix())},
+		},
+	}
+
+	var overdue []models.ShowBooking
+	for {
+		out, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query pending payments: %w", err)
+		}
+		var rows []PendingPaymentDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending payments: %w", err)
+		}
+		for _, row := range rows {
+			booking, err := r.GetByID(ctx, row.UserID, row.BookingID)
+			if err != nil {
+				return nil, err
+			}
+			if booking == nil {
+				if err := r.DropPendingPayment(ctx, row.BookingID); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			overdue = append(overdue, models.ShowBooking{UserID: row.UserID, Booking: *booking})
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return overdue, nil
+}
+
+// DropPendingPayment takes a booking that is no longer awaiting payment off
+// the queue.
+func (r *BookingRepositoryDDB) DropPendingPayment(ctx context.Context, bookingID string) error {
+	_, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       pendingPaymentKey(bookingID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove pending payment: %w", err)
+	}
+	return nil
+}
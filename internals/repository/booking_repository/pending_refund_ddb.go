@@ -0,0 +1,166 @@
+package bookingrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Refunds owed on cancelled bookings are queued under PENDING_REFUNDS in the
+// same transaction as the cancellation and removed once the gateway has paid
+// them. retry_at keeps two callers from paying the same refund: whoever is
+// paying it holds it until then.
+
+type PendingRefundDDB struct {
+	PK        string  `dynamodbav:"pk"`
+	SK        string  `dynamodbav:"sk"`
+	UserID    string  `dynamodbav:"user_id"`
+	BookingID string  `dynamodbav:"booking_id"`
+	ShowID    string  `dynamodbav:"show_id"`
+	PaymentID string  `dynamodbav:"payment_id"`
+	Amount    float64 `dynamodbav:"amount"`
+	RetryAt   int64   `dynamodbav:"retry_at"`
+	CreatedAt string  `dynamodbav:"created_at"`
+}
+
+func pendingRefundKey(refundID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "PENDING_REFUNDS"},
+		"sk": &types.AttributeValueMemberS{Value: "REFUND#" + refundID},
+	}
+}
+
+// pendingRefundPut returns the item queueing refund, or none when nothing is
+// owed.
+func pendingRefundPut(tableName string, refund *models.PendingRefund) ([]types.TransactWriteItem, error) {
+	if refund == nil {
+		return nil, nil
+	}
+	item, err := attributevalue.MarshalMap(PendingRefundDDB{
+		PK:        "PENDING_REFUNDS",
+		SK:        "REFUND#" + refund.RefundID,
+		UserID:    refund.UserID,
+		BookingID: refund.BookingID,
+		ShowID:    refund.ShowID,
+		PaymentID: refund.PaymentID,
+		Amount:    refund.Amount,
+		RetryAt:   refund.RetryAt.Unix(),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []types.TransactWriteItem{
+		{
+			Put: &types.Put{
+				TableName:           aws.String(tableName),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(pk)"),
+			},
+		},
+	}, nil
+}
+
+// ListPendingRefunds returns the refunds still owed that nobody is paying
+// at now.
+func (r *BookingRepositoryDDB) ListPendingRefunds(ctx context.Context, now time.Time) ([]models.PendingRefund, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		FilterExpression:       aws.String("retry_at <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: "PENDING_REFUNDS"},
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(now.Unix())},
+		},
+	}
+
+	var refunds []models.PendingRefund
+	for {
+		out, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query pending refunds: %w", err)
+		}
+		var rows []PendingRefundDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal pending refunds: %w", err)
+		}
+		for _, row := range rows {
+			refunds = append(refunds, models.PendingRefund{
+				RefundID:  strings.TrimPrefix(row.SK, "REFUND#"),
+				UserID:    row.UserID,
+				BookingID: row.BookingID,
+				ShowID:    row.ShowID,
+				PaymentID: row.PaymentID,
+				Amount:    row.Amount,
+				RetryAt:   time.Unix(row.RetryAt, 0).UTC(),
+			})
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return refunds, nil
+}
+
+// ClaimRefund holds the refund until until, so nobody else pays it in the
+// meantime. It reports false when the refund is already paid or someone else
+// holds it.
+func (r *BookingRepositoryDDB) ClaimRefund(ctx context.Context, refundID string, now, until time.Time) (bool, error) {
+	_, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 pendingRefundKey(refundID),
+		UpdateExpression:    aws.String("SET retry_at = :until"),
+		ConditionExpression: aws.String("attribute_exists(pk) AND retry_at <= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":until": &types.AttributeValueMemberN{Value: fmt.Sprint(until.Unix())},
+			":now":   &types.AttributeValueMemberN{Value: fmt.Sprint(now.Unix())},
+		},
+	})
+	var cce *types.ConditionalCheckFailedException
+	if errors.As(err, &cce) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim refund: %w", err)
+	}
+	return true, nil
+}
+
+// ReleaseRefund lets a refund whose payment failed be retried straight away.
+func (r *BookingRepositoryDDB) ReleaseRefund(ctx context.Context, refundID string) error {
+	_, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 pendingRefundKey(refundID),
+		UpdateExpression:    aws.String("SET retry_at = :zero"),
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":zero": &types.AttributeValueMemberN{Value: "0"},
+		},
+	})
+	var cce *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &cce) {
+		return fmt.Errorf("failed to release refund: %w", err)
+	}
+	return nil
+}
+
+// CompleteRefund takes a refund the gateway has paid off the queue.
+func (r *BookingRepositoryDDB) CompleteRefund(ctx context.Context, refundID string) error {
+	_, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       pendingRefundKey(refundID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete refund: %w", err)
+	}
+	return nil
+}
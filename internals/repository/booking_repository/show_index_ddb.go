@@ -0,0 +1,161 @@
+package bookingrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Bookings made before the show booking index existed have no
+// SHOW#/BOOKING# item. BackfillShowIndex writes the missing ones and then a
+// marker; until the marker exists, lookups through the index also scan the
+// users' bookings so none of the older ones are missed.
+
+func showIndexMarkerKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "MIGRATION#SHOW_BOOKING_INDEX"},
+		"sk": &types.AttributeValueMemberS{Value: "DONE"},
+	}
+}
+
+// showIndexComplete reports whether the backfill has finished. Once it has,
+// the answer is kept for the life of the container.
+func (r *BookingRepositoryDDB) showIndexComplete(ctx context.Context) (bool, error) {
+	if r.showIndexReady {
+		return true, nil
+	}
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       showIndexMarkerKey(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check show booking index: %w", err)
+	}
+	r.showIndexReady = out.Item != nil
+	return r.showIndexReady, nil
+}
+
+// scanShowBookings finds the show's bookings by scanning every user's
+// bookings, optionally only the one with bookingID.
+func (r *BookingRepositoryDDB) scanShowBookings(ctx context.Context, showID, bookingID string) ([]models.ShowBooking, error) {
+	filter := "begins_with(pk, :user) AND begins_with(sk, :sk) AND show_id = :show"
+	values := map[string]types.AttributeValue{
+		":user": &types.AttributeValueMemberS{Value: "USER#"},
+		":sk":   &types.AttributeValueMemberS{Value: "BOOKED_SHOW_DATE#"},
+		":show": &types.AttributeValueMemberS{Value: showID},
+	}
+	if bookingID != "" {
+		filter += " AND contains(sk, :bid)"
+		values[":bid"] = &types.AttributeValueMemberS{Value: "#BOOKINGID#" + bookingID}
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(r.TableName),
+		FilterExpression:          aws.String(filter),
+		ExpressionAttributeValues: values,
+	}
+
+	var bookings []models.ShowBooking
+	for {
+		out, err := r.db.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bookings: %w", err)
+		}
+		var page []UserBookingDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bookings: %w", err)
+		}
+		for _, b := range page {
+			bookings = append(bookings, models.ShowBooking{
+				UserID:  strings.TrimPrefix(b.UserEmail, "USER#"),
+				Booking: toUserBookingDTO(b),
+			})
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return bookings, nil
+}
+
+// BackfillShowIndex adds the show booking index item of every booking that
+// lacks one and returns how many were written. Once it has finished it does
+// nothing, so it is safe to run on a schedule.
+func (r *BookingRepositoryDDB) BackfillShowIndex(ctx context.Context) (int, error) {
+	if complete, err := r.showIndexComplete(ctx); err != nil || complete {
+		return 0, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.TableName),
+		FilterExpression: aws.String("begins_with(pk, :user) AND begins_with(sk, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user": &types.AttributeValueMemberS{Value: "USER#"},
+			":sk":   &types.AttributeValueMemberS{Value: "BOOKED_SHOW_DATE#"},
+		},
+		ProjectionExpression: aws.String("pk, sk, show_id"),
+	}
+
+	written := 0
+	for {
+		out, err := r.db.Scan(ctx, input)
+		if err != nil {
+			return written, fmt.Errorf("failed to scan bookings: %w", err)
+		}
+		var page []UserBookingDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return written, fmt.Errorf("failed to unmarshal bookings: %w", err)
+		}
+		for _, b := range page {
+			_, bookingID, ok := strings.Cut(b.BookingDate_BookingID, "#BOOKINGID#")
+			if !ok || b.ShowID == "" {
+				continue
+			}
+			item, err := attributevalue.MarshalMap(ShowBookingDDB{
+				ShowPK:    "SHOW#" + b.ShowID,
+				BookingSK: "BOOKING#" + bookingID,
+				UserID:    strings.TrimPrefix(b.UserEmail, "USER#"),
+				UserSK:    b.BookingDate_BookingID,
+			})
+			if err != nil {
+				return written, err
+			}
+			// an existing item is newer, e.g. written when the booking moved
+			_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+				TableName:           aws.String(r.TableName),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(pk)"),
+			})
+			var cce *types.ConditionalCheckFailedException
+			if errors.As(err, &cce) {
+				continue
+			}
+			if err != nil {
+				return written, fmt.Errorf("failed to index booking %s: %w", bookingID, err)
+			}
+			written++
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	_, err := r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.TableName),
+		Item:      showIndexMarkerKey(),
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to mark show booking index complete: %w", err)
+	}
+	r.showIndexReady = true
+	return written, nil
+}
@@ -0,0 +1,370 @@
+package bookingrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// A transfer is a TRANSFER#<id> item holding its state and audit history,
+// with TRANSFER_OUT#<id> and TRANSFER_IN#<id> pointers under the sender's and
+// recipient's partitions. Transfers are kept after they finish as the record
+// of who gave which seats to whom, so they carry no TTL.
+
+type TransferDDB struct {
+	PK           string                 `dynamodbav:"pk"`
+	SK           string                 `dynamodbav:"sk"`
+	TransferID   string                 `dynamodbav:"transfer_id"`
+	BookingID    string                 `dynamodbav:"booking_id"`
+	ShowID       string                 `dynamodbav:"show_id"`
+	FromUserID   string                 `dynamodbav:"from_user_id"`
+	ToUserID     string                 `dynamodbav:"to_user_id"`
+	Seats        []string               `dynamodbav:"seats"`
+	Status       string                 `dynamodbav:"status"`
+	CreatedAt    string                 `dynamodbav:"created_at"`
+	AcceptBy     string                 `dynamodbav:"accept_by"`
+	NewBookingID string                 `dynamodbav:"new_booking_id,omitempty"`
+	History      []models.TransferEvent `dynamodbav:"history"`
+}
+
+func transferKey(transferID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "TRANSFER#" + transferID},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+func (t TransferDDB) toModel() models.BookingTransfer {
+	createdAt, _ := time.Parse(time.RFC3339, t.CreatedAt)
+	acceptBy, _ := time.Parse(time.RFC3339, t.AcceptBy)
+	return models.BookingTransfer{
+		TransferID:   t.TransferID,
+		BookingID:    t.BookingID,
+		ShowID:       t.ShowID,
+		FromUserID:   t.FromUserID,
+		ToUserID:     t.ToUserID,
+		Seats:        t.Seats,
+		Status:       t.Status,
+		CreatedAt:    createdAt,
+		ExpiresAt:    acceptBy,
+		NewBookingID: t.NewBookingID,
+		History:      t.History,
+	}
+}
+
+// transferEventAV is a one element list holding the event, ready for
+// list_append.
+func transferEventAV(event models.TransferEvent) (types.AttributeValue, error) {
+	av, err := attributevalue.Marshal([]models.TransferEvent{event})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transfer event: %w", err)
+	}
+	return av, nil
+}
+
+// CreateTransfer stores a new transfer with its pointers for both users.
+func (r *BookingRepositoryDDB) CreateTransfer(ctx context.Context, transfer *models.BookingTransfer) error {
+	item, err := attributevalue.MarshalMap(TransferDDB{
+		PK:         "TRANSFER#" + transfer.TransferID,
+		SK:         "DETAILS",
+		TransferID: transfer.TransferID,
+		BookingID:  transfer.BookingID,
+		ShowID:     transfer.ShowID,
+		FromUserID: transfer.FromUserID,
+		ToUserID:   transfer.ToUserID,
+		Seats:      transfer.Seats,
+		Status:     transfer.Status,
+		CreatedAt:  transfer.CreatedAt.UTC().Format(time.RFC3339),
+		AcceptBy:   transfer.ExpiresAt.UTC().Format(time.RFC3339),
+		History:    transfer.History,
+	})
+	if err != nil {
+		return err
+	}
+
+	pointer := func(userID, sk string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"pk":          &types.AttributeValueMemberS{Value: "USER#" + userID},
+			"sk":          &types.AttributeValueMemberS{Value: sk + transfer.TransferID},
+			"transfer_id": &types.AttributeValueMemberS{Value: transfer.TransferID},
+		}
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.TableName),
+					Item:                item,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(r.TableName),
+					Item:      pointer(transfer.FromUserID, "TRANSFER_OUT#"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(r.TableName),
+					Item:      pointer(transfer.ToUserID, "TRANSFER_IN#"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
+	return nil
+}
+
+// GetTransfer returns the transfer, or nil if there is no such transfer.
+func (r *BookingRepositoryDDB) GetTransfer(ctx context.Context, transferID string) (*models.BookingTransfer, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.TableName),
+		Key:            transferKey(transferID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transfer: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var t TransferDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &t); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transfer: %w", err)
+	}
+	transfer := t.toModel()
+	return &transfer, nil
+}
+
+// ListTransfers returns the transfers the user has sent and received.
+func (r *BookingRepositoryDDB) ListTransfers(ctx context.Context, userID string) (*models.TransferList, error) {
+	list := &models.TransferList{
+		Incoming: []models.BookingTransfer{},
+		Outgoing: []models.BookingTransfer{},
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+			":sk": &types.AttributeValueMemberS{Value: "TRANSFER_"},
+		},
+	}
+
+	for {
+		page, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query transfers: %w", err)
+		}
+
+		for _, item := range page.Items {
+			var pointer struct {
+				SK         string `dynamodbav:"sk"`
+				TransferID string `dynamodbav:"transfer_id"`
+			}
+			if err := attributevalue.UnmarshalMap(item, &pointer); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal transfer pointer: %w", err)
+			}
+
+			transfer, err := r.GetTransfer(ctx, pointer.TransferID)
+			if err != nil {
+				return nil, err
+			}
+			if transfer == nil {
+				continue
+			}
+			if strings.HasPrefix(pointer.SK, "TRANSFER_IN#") {
+				list.Incoming = append(list.Incoming, *transfer)
+			} else {
+				list.Outgoing = append(list.Outgoing, *transfer)
+			}
+		}
+
+		if len(page.LastEvaluatedKey) == 0 {
+			return list, nil
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+}
+
+// UpdateTransferStatus ends a pending transfer without moving any seats and
+// records who did it.
+func (r *BookingRepositoryDDB) UpdateTransferStatus(ctx context.Context, transfer *models.BookingTransfer, status string, event models.TransferEvent) error {
+	eventAV, err := transferEventAV(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 transferKey(transfer.TransferID),
+		UpdateExpression:    aws.String("SET #status = :status, history = list_append(history, :event)"),
+		ConditionExpression: aws.String("#status = :pending"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status":  &types.AttributeValueMemberS{Value: status},
+			":pending": &types.AttributeValueMemberS{Value: models.TransferPending},
+			":event":   eventAV,
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return models.ErrTransferNotPending
+		}
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+
+	transfer.Status = status
+	transfer.History = append(transfer.History, event)
+	return nil
+}
+
+// AcceptTransfer moves the transferred seats in one transaction: the
+// received booking is created under the recipient, the original booking is
+// cut down to kept (or marked transferred when kept is nil) and its
+// ticket_version bumped so tickets issued before the transfer stop working,
+// and the transfer is marked accepted. The original booking must still be
+// confirmed, hold every transferred seat and be at the ticket version it was
+// read at.
+func (r *BookingRepositoryDDB) AcceptTransfer(ctx context.Context, transfer *models.BookingTransfer, original, kept, received *models.UserBookingDTO, event models.TransferEvent) error {
+	originalKey := bookingKey(transfer.FromUserID, original.BookingDate, original.BookingID)
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(r.TableName),
+		Key:            originalKey,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch booking: %w", err)
+	}
+	if out.Item == nil {
+		return fmt.Errorf("booking not found: %s", original.BookingID)
+	}
+
+	var b UserBookingDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &b); err != nil {
+		return fmt.Errorf("failed to unmarshal booking: %w", err)
+	}
+	receivedKey := bookingKey(transfer.ToUserID, original.BookingDate, received.BookingID)
+	b.UserEmail = receivedKey["pk"].(*types.AttributeValueMemberS).Value
+	b.BookingDate_BookingID = receivedKey["sk"].(*types.AttributeValueMemberS).Value
+	// the seats still count against the day the sender bought them
+	b.SaleDate = saleDate(original)
+	b.TimeBooked = time.Now().Format(time.RFC3339)
+	b.NumTicketsBooked = received.NumTicketsBooked
+	b.OriginalPrice = received.OriginalPrice
+	b.Discount = received.Discount
+	b.TotalPrice = received.TotalPrice
+	b.Seats = received.Seats
+	b.LineItems = received.LineItems
+	b.Status = models.BookingConfirmed
+	b.TicketVersion = 0
+	b.TransferredFrom = original.BookingID
+	// the recipient paid nothing, so the booking has no payment, refunds or
+	// promo of its own; the sender's payment is kept only so a cancelled show
+	// can refund whoever paid for the seats
+	if b.PaymentID != "" {
+		b.OriginalPaymentID = b.PaymentID
+	}
+	b.PaymentID = ""
+	b.RefundAmount = 0
+	b.PromoCode = ""
+	b.CancelledSeats = nil
+	receivedItem, err := attributevalue.MarshalMap(b)
+	if err != nil {
+		return err
+	}
+
+	indexItem, err := attributevalue.MarshalMap(ShowBookingDDB{
+		ShowPK:    "SHOW#" + original.ShowID,
+		BookingSK: "BOOKING#" + received.BookingID,
+		UserID:    transfer.ToUserID,
+		UserSK:    b.BookingDate_BookingID,
+	})
+	if err != nil {
+		return err
+	}
+
+	eventAV, err := transferEventAV(event)
+	if err != nil {
+		return err
+	}
+
+	var sets []string
+	values := map[string]types.AttributeValue{}
+	if kept == nil {
+		sets = append(sets, "#status = :transferred")
+		values[":transferred"] = &types.AttributeValueMemberS{Value: models.BookingTransferred}
+	}
+	originalUpdate, err := seatSplitUpdate(r.TableName, originalKey, original, transfer.Seats, kept, sets, values)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.TableName),
+					Key:                 transferKey(transfer.TransferID),
+					UpdateExpression:    aws.String("SET #status = :accepted, new_booking_id = :bid, history = list_append(history, :event)"),
+					ConditionExpression: aws.String("#status = :pending"),
+					ExpressionAttributeNames: map[string]string{
+						"#status": "status",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":accepted": &types.AttributeValueMemberS{Value: models.TransferAccepted},
+						":pending":  &types.AttributeValueMemberS{Value: models.TransferPending},
+						":bid":      &types.AttributeValueMemberS{Value: received.BookingID},
+						":event":    eventAV,
+					},
+				},
+			},
+			{Update: originalUpdate},
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.TableName),
+					Item:                receivedItem,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.TableName),
+					Item:                indexItem,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		if txItemsFailed(err, 0, 1) {
+			return models.ErrTransferNotPending
+		}
+		if txItemsFailed(err, 1, 1) {
+			return errors.New("the booking has changed since the transfer was started")
+		}
+		return fmt.Errorf("failed to accept transfer: %w", err)
+	}
+
+	transfer.Status = models.TransferAccepted
+	transfer.NewBookingID = received.BookingID
+	transfer.History = append(transfer.History, event)
+	return nil
+}
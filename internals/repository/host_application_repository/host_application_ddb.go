@@ -0,0 +1,269 @@
+package hostapplicationrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// A user's application is a HOST_APPLICATION item under their USER#<email>
+// partition. While it is pending a copy also sits in the HOST_APPLICATIONS
+// partition under PENDING#<submitted_at>#<email>, so admins get the queue
+// oldest first from a single query. Reviewing the application removes the
+// copy.
+
+// submittedAtLayout is fixed width so queue keys sort by time.
+const submittedAtLayout = "2006-01-02T15:04:05.000000000Z"
+
+type HostApplicationRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewHostApplicationRepositoryDDB(db *dynamodb.Client, tableName string) *HostApplicationRepositoryDDB {
+	return &HostApplicationRepositoryDDB{db: db, TableName: tableName}
+}
+
+type HostApplicationDDB struct {
+	PK           string `dynamodbav:"pk"`
+	SK           string `dynamodbav:"sk"`
+	UserEmail    string `dynamodbav:"user_email"`
+	BusinessName string `dynamodbav:"business_name"`
+	ContactPhone string `dynamodbav:"contact_phone"`
+	Address      string `dynamodbav:"address"`
+	Website      string `dynamodbav:"website,omitempty"`
+	Description  string `dynamodbav:"description"`
+	Status       string `dynamodbav:"status"`
+	SubmittedAt  string `dynamodbav:"submitted_at"`
+	ReviewedBy   string `dynamodbav:"reviewed_by,omitempty"`
+	ReviewedAt   string `dynamodbav:"reviewed_at,omitempty"`
+	Reason       string `dynamodbav:"reason,omitempty"`
+}
+
+func applicationKey(email string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#" + email},
+		"sk": &types.AttributeValueMemberS{Value: "HOST_APPLICATION"},
+	}
+}
+
+func queueSK(app *models.HostApplication) string {
+	return "PENDING#" + app.SubmittedAt.UTC().Format(submittedAtLayout) + "#" + app.UserEmail
+}
+
+func queueKey(app *models.HostApplication) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "HOST_APPLICATIONS"},
+		"sk": &types.AttributeValueMemberS{Value: queueSK(app)},
+	}
+}
+
+func toDDB(app *models.HostApplication, pk, sk string) HostApplicationDDB {
+	return HostApplicationDDB{
+		PK:           pk,
+		SK:           sk,
+		UserEmail:    app.UserEmail,
+		BusinessName: app.BusinessName,
+		ContactPhone: app.ContactPhone,
+		Address:      app.Address,
+		Website:      app.Website,
+		Description:  app.Description,
+		Status:       app.Status,
+		SubmittedAt:  app.SubmittedAt.UTC().Format(submittedAtLayout),
+	}
+}
+
+func (h HostApplicationDDB) toModel() models.HostApplication {
+	submittedAt, _ := time.Parse(submittedAtLayout, h.SubmittedAt)
+	app := models.HostApplication{
+		UserEmail:    h.UserEmail,
+		BusinessName: h.BusinessName,
+		ContactPhone: h.ContactPhone,
+		Address:      h.Address,
+		Website:      h.Website,
+		Description:  h.Description,
+		Status:       h.Status,
+		SubmittedAt:  submittedAt,
+		ReviewedBy:   h.ReviewedBy,
+		Reason:       h.Reason,
+	}
+	if h.ReviewedAt != "" {
+		if t, err := time.Parse(time.RFC3339, h.ReviewedAt); err == nil {
+			app.ReviewedAt = &t
+		}
+	}
+	return app
+}
+
+func conditionFailed(err error, index int) bool {
+	var tce *types.TransactionCanceledException
+	return errors.As(err, &tce) && index < len(tce.CancellationReasons) &&
+		aws.ToString(tce.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
+
+// Submit stores a new pending application. It replaces an earlier
+// application only if that one was rejected.
+func (hr *HostApplicationRepositoryDDB) Submit(ctx context.Context, app *models.HostApplication) error {
+	appItem, err := attributevalue.MarshalMap(toDDB(app, "USER#"+app.UserEmail, "HOST_APPLICATION"))
+	if err != nil {
+		return err
+	}
+	queueItem, err := attributevalue.MarshalMap(toDDB(app, "HOST_APPLICATIONS", queueSK(app)))
+	if err != nil {
+		return err
+	}
+
+	_, err = hr.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:                aws.String(hr.TableName),
+					Item:                     appItem,
+					ConditionExpression:      aws.String("attribute_not_exists(pk) OR #status = :rejected"),
+					ExpressionAttributeNames: map[string]string{"#status": "status"},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":rejected": &types.AttributeValueMemberS{Value: models.HostApplicationRejected},
+					},
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(hr.TableName),
+					Item:      queueItem,
+				},
+			},
+		},
+	})
+	if err != nil {
+		if conditionFailed(err, 0) {
+			return models.ErrHostApplicationExists
+		}
+		return fmt.Errorf("failed to submit host application: %w", err)
+	}
+	return nil
+}
+
+// Get returns the user's application, or nil if they never applied.
+func (hr *HostApplicationRepositoryDDB) Get(ctx context.Context, email string) (*models.HostApplication, error) {
+	out, err := hr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(hr.TableName),
+		Key:       applicationKey(email),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host application: %w", err)
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+
+	var item HostApplicationDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal host application: %w", err)
+	}
+	app := item.toModel()
+	return &app, nil
+}
+
+// ListPending returns the applications awaiting review, oldest first.
+func (hr *HostApplicationRepositoryDDB) ListPending(ctx context.Context) ([]models.HostApplication, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(hr.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: "HOST_APPLICATIONS"},
+			":prefix": &types.AttributeValueMemberS{Value: "PENDING#"},
+		},
+	}
+
+	apps := []models.HostApplication{}
+	for {
+		out, err := hr.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list host applications: %w", err)
+		}
+		for _, item := range out.Items {
+			var h HostApplicationDDB
+			if err := attributevalue.UnmarshalMap(item, &h); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal host application: %w", err)
+			}
+			apps = append(apps, h.toModel())
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return apps, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// Approve records the approval and makes user a host in one transaction.
+// It fails if the user's role changed after they applied.
+func (hr *HostApplicationRepositoryDDB) Approve(ctx context.Context, app *models.HostApplication, user *models.User) error {
+	roleItems, err := userrepository.RoleChangeItems(hr.TableName, user, models.Host)
+	if err != nil {
+		return err
+	}
+	err = hr.review(ctx, app, roleItems...)
+	if conditionFailed(err, 2) {
+		return errors.New("the user's role has changed since they applied")
+	}
+	return err
+}
+
+// Reject records the rejection and its reason.
+func (hr *HostApplicationRepositoryDDB) Reject(ctx context.Context, app *models.HostApplication) error {
+	return hr.review(ctx, app)
+}
+
+// review moves a pending application to app.Status and takes it off the
+// queue, along with any extra writes that go with the decision.
+func (hr *HostApplicationRepositoryDDB) review(ctx context.Context, app *models.HostApplication, extra ...types.TransactWriteItem) error {
+	if app.ReviewedAt == nil {
+		return errors.New("reviewed_at is required")
+	}
+	values := map[string]types.AttributeValue{
+		":status":     &types.AttributeValueMemberS{Value: app.Status},
+		":pending":    &types.AttributeValueMemberS{Value: models.HostApplicationPending},
+		":reviewedBy": &types.AttributeValueMemberS{Value: app.ReviewedBy},
+		":reviewedAt": &types.AttributeValueMemberS{Value: app.ReviewedAt.UTC().Format(time.RFC3339)},
+		":reason":     &types.AttributeValueMemberS{Value: app.Reason},
+	}
+
+	txItems := []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                 aws.String(hr.TableName),
+				Key:                       applicationKey(app.UserEmail),
+				UpdateExpression:          aws.String("SET #status = :status, reviewed_by = :reviewedBy, reviewed_at = :reviewedAt, reason = :reason"),
+				ConditionExpression:       aws.String("#status = :pending"),
+				ExpressionAttributeNames:  map[string]string{"#status": "status"},
+				ExpressionAttributeValues: values,
+			},
+		},
+		{
+			Delete: &types.Delete{
+				TableName: aws.String(hr.TableName),
+				Key:       queueKey(app),
+			},
+		},
+	}
+	txItems = append(txItems, extra...)
+
+	_, err := hr.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: txItems,
+	})
+	if err != nil {
+		if conditionFailed(err, 0) {
+			return models.ErrHostApplicationNotPending
+		}
+		return fmt.Errorf("failed to review host application: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package hostapplicationrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/host_application_repository_mock.go -package=mocks -source=interface.go
+type HostApplicationRepositoryI interface {
+	Submit(ctx context.Context, app *models.HostApplication) error
+	Get(ctx context.Context, email string) (*models.HostApplication, error)
+	ListPending(ctx context.Context) ([]models.HostApplication, error)
+	Approve(ctx context.Context, app *models.HostApplication, user *models.User) error
+	Reject(ctx context.Context, app *models.HostApplication) error
+}
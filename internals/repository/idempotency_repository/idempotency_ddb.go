@@ -0,0 +1,138 @@
+package idempotencyrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type IdempotencyRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewIdempotencyRepositoryDDB(db *dynamodb.Client, tableName string) *IdempotencyRepositoryDDB {
+	return &IdempotencyRepositoryDDB{db: db, TableName: tableName}
+}
+
+// IdempotencyDDB is removed by the table TTL on expires_at.
+type IdempotencyDDB struct {
+	PK          string            `dynamodbav:"pk"`
+	SK          string            `dynamodbav:"sk"`
+	Fingerprint string            `dynamodbav:"fingerprint"`
+	Status      string            `dynamodbav:"status"`
+	StatusCode  int               `dynamodbav:"status_code,omitempty"`
+	Body        string            `dynamodbav:"body,omitempty"`
+	Headers     map[string]string `dynamodbav:"headers,omitempty"`
+	LockedUntil int64             `dynamodbav:"locked_until,omitempty"`
+	ExpiresAt   int64             `dynamodbav:"expires_at"`
+}
+
+func idempotencyKey(userID, key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "IDEMPOTENCY#" + userID + "#" + key},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+// Reserve claims the key for a new request, or takes over the key of an
+// identical request whose lock has lapsed. If the key is otherwise in use the
+// existing record is returned instead and nothing is written.
+func (r *IdempotencyRepositoryDDB) Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	item, err := attributevalue.MarshalMap(IdempotencyDDB{
+		PK:          "IDEMPOTENCY#" + record.UserID + "#" + record.Key,
+		SK:          "DETAILS",
+		Fingerprint: record.Fingerprint,
+		Status:      models.IdempotencyInProgress,
+		LockedUntil: record.LockedUntil.Unix(),
+		ExpiresAt:   record.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal idempotency record: %w", err)
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.TableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk) OR expires_at < :now OR (#status = :inprogress AND locked_until < :now AND fingerprint = :fp)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":        &types.AttributeValueMemberN{Value: fmt.Sprint(time.Now().Unix())},
+			":inprogress": &types.AttributeValueMemberS{Value: models.IdempotencyInProgress},
+			":fp":         &types.AttributeValueMemberS{Value: record.Fingerprint},
+		},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	if err == nil {
+		return nil, nil
+	}
+
+	var cce *types.ConditionalCheckFailedException
+	if !errors.As(err, &cce) {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var existing IdempotencyDDB
+	if err := attributevalue.UnmarshalMap(cce.Item, &existing); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+	return &models.IdempotencyRecord{
+		UserID:      record.UserID,
+		Key:         record.Key,
+		Fingerprint: existing.Fingerprint,
+		Status:      existing.Status,
+		StatusCode:  existing.StatusCode,
+		Body:        existing.Body,
+		Headers:     existing.Headers,
+		LockedUntil: time.Unix(existing.LockedUntil, 0).UTC(),
+		ExpiresAt:   time.Unix(existing.ExpiresAt, 0).UTC(),
+	}, nil
+}
+
+func (r *IdempotencyRepositoryDDB) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	headers, err := attributevalue.Marshal(record.Headers)
+	if err != nil {
+		return fmt.Errorf("marshal response headers: %w", err)
+	}
+
+	_, err = r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 idempotencyKey(record.UserID, record.Key),
+		UpdateExpression:    aws.String("SET #status = :completed, status_code = :code, body = :body, headers = :headers"),
+		ConditionExpression: aws.String("fingerprint = :fp"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":completed": &types.AttributeValueMemberS{Value: models.IdempotencyCompleted},
+			":code":      &types.AttributeValueMemberN{Value: fmt.Sprint(record.StatusCode)},
+			":body":      &types.AttributeValueMemberS{Value: record.Body},
+			":headers":   headers,
+			":fp":        &types.AttributeValueMemberS{Value: record.Fingerprint},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepositoryDDB) Delete(ctx context.Context, userID, key string) error {
+	_, err := r.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       idempotencyKey(userID, key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package idempotencyrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/idempotency_repository_mock.go -package=mocks -source=interface.go
+type IdempotencyRepositoryI interface {
+	Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	Delete(ctx context.Context, userID, key string) error
+}
@@ -0,0 +1,15 @@
+package loginattemptrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/loginattempt_repository_mock.go -package=mocks -source=interface.go
+type LoginAttemptRepositoryI interface {
+	Get(ctx context.Context, kind, key string) (*models.LoginAttempts, error)
+	RecordFailure(ctx context.Context, kind, key string, expiresAt time.Time) (*models.LoginAttempts, error)
+	Block(ctx context.Context, kind, key string, until time.Time) error
+	Reset(ctx context.Context, kind, key string) error
+}
@@ -0,0 +1,123 @@
+package loginattemptrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Failed logins are counted on a LOGIN_ATTEMPTS#<kind>#<key> item per email
+// or source IP. expires_at is the table's TTL attribute and is pushed back on
+// every failure, so a count is forgotten once it goes quiet.
+
+type LoginAttemptRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewLoginAttemptRepositoryDDB(db *dynamodb.Client, tableName string) *LoginAttemptRepositoryDDB {
+	return &LoginAttemptRepositoryDDB{db: db, TableName: tableName}
+}
+
+type LoginAttemptsDDB struct {
+	PK           string `dynamodbav:"pk"`
+	SK           string `dynamodbav:"sk"`
+	Failures     int    `dynamodbav:"failures"`
+	BlockedUntil int64  `dynamodbav:"blocked_until"`
+	ExpiresAt    int64  `dynamodbav:"expires_at"`
+}
+
+func attemptsKey(kind, key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "LOGIN_ATTEMPTS#" + kind + "#" + key},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+func (l LoginAttemptsDDB) toModel(kind, key string) *models.LoginAttempts {
+	attempts := &models.LoginAttempts{Kind: kind, Key: key, Failures: l.Failures}
+	if l.BlockedUntil > 0 {
+		attempts.BlockedUntil = time.Unix(l.BlockedUntil, 0).UTC()
+	}
+	return attempts
+}
+
+// Get returns the record for the email or IP; one with no failures if there
+// is none, or it has expired.
+func (lr *LoginAttemptRepositoryDDB) Get(ctx context.Context, kind, key string) (*models.LoginAttempts, error) {
+	out, err := lr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(lr.TableName),
+		Key:       attemptsKey(kind, key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login attempts: %w", err)
+	}
+
+	var item LoginAttemptsDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal login attempts: %w", err)
+	}
+	if item.ExpiresAt > 0 && item.ExpiresAt <= time.Now().Unix() {
+		return &models.LoginAttempts{Kind: kind, Key: key}, nil
+	}
+	return item.toModel(kind, key), nil
+}
+
+// RecordFailure counts one more failed login and keeps the record until
+// expiresAt.
+func (lr *LoginAttemptRepositoryDDB) RecordFailure(ctx context.Context, kind, key string, expiresAt time.Time) (*models.LoginAttempts, error) {
+	out, err := lr.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(lr.TableName),
+		Key:              attemptsKey(kind, key),
+		UpdateExpression: aws.String("SET expires_at = :exp ADD failures :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":exp": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	var item LoginAttemptsDDB
+	if err := attributevalue.UnmarshalMap(out.Attributes, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal login attempts: %w", err)
+	}
+	return item.toModel(kind, key), nil
+}
+
+// Block refuses logins for the email or IP until the given time.
+func (lr *LoginAttemptRepositoryDDB) Block(ctx context.Context, kind, key string, until time.Time) error {
+	_, err := lr.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(lr.TableName),
+		Key:              attemptsKey(kind, key),
+		UpdateExpression: aws.String("SET blocked_until = :until"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":until": &types.AttributeValueMemberN{Value: strconv.FormatInt(until.Unix(), 10)},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to block logins: %w", err)
+	}
+	return nil
+}
+
+// Reset forgets the failures of the email or IP, lifting any block.
+func (lr *LoginAttemptRepositoryDDB) Reset(ctx context.Context, kind, key string) error {
+	_, err := lr.db.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(lr.TableName),
+		Key:       attemptsKey(kind, key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
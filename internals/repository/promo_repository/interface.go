@@ -0,0 +1,13 @@
+package promorepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/promo_repository_mock.go -package=mocks -source=interface.go
+type PromoRepositoryI interface {
+	Create(ctx context.Context, promo *models.PromoCode) error
+	GetByCode(ctx context.Context, code string) (*models.PromoCode, error)
+	GetUserRedemptions(ctx context.Context, code, userID string) (int, error)
+}
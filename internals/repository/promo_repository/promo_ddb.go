@@ -0,0 +1,200 @@
+package promorepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type PromoRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewPromoRepositoryDDB(db *dynamodb.Client, tableName string) *PromoRepositoryDDB {
+	return &PromoRepositoryDDB{db: db, TableName: tableName}
+}
+
+// PromoDDB keeps the validity window as unix seconds so it can be compared
+// in condition expressions.
+type PromoDDB struct {
+	PK             string  `dynamodbav:"pk"`
+	SK             string  `dynamodbav:"sk"`
+	DiscountType   string  `dynamodbav:"discount_type"`
+	DiscountValue  float64 `dynamodbav:"discount_value"`
+	ValidFrom      int64   `dynamodbav:"valid_from"`
+	ValidUntil     int64   `dynamodbav:"valid_until"`
+	MaxRedemptions int     `dynamodbav:"max_redemptions"`
+	PerUserLimit   int     `dynamodbav:"per_user_limit"`
+	Redemptions    int     `dynamodbav:"redemptions"`
+	ScopeType      string  `dynamodbav:"scope_type"`
+	ScopeID        string  `dynamodbav:"scope_id"`
+	CreatedBy      string  `dynamodbav:"created_by"`
+}
+
+func promoKey(code string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "PROMO#" + code},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+func redemptionKey(code, userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "PROMO#" + code},
+		"sk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+	}
+}
+
+func (r *PromoRepositoryDDB) Create(ctx context.Context, promo *models.PromoCode) error {
+	item, err := attributevalue.MarshalMap(PromoDDB{
+		PK:             "PROMO#" + promo.Code,
+		SK:             "DETAILS",
+		DiscountType:   string(promo.DiscountType),
+		DiscountValue:  promo.DiscountValue,
+		ValidFrom:      promo.ValidFrom.Unix(),
+		ValidUntil:     promo.ValidUntil.Unix(),
+		MaxRedemptions: promo.MaxRedemptions,
+		PerUserLimit:   promo.PerUserLimit,
+		ScopeType:      string(promo.ScopeType),
+		ScopeID:        promo.ScopeID,
+		CreatedBy:      promo.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal promo: %w", err)
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.TableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return fmt.Errorf("promo code %s already exists", promo.Code)
+		}
+		return fmt.Errorf("put promo failed: %w", err)
+	}
+	return nil
+}
+
+func (r *PromoRepositoryDDB) GetByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       promoKey(code),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var p PromoDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal promo: %w", err)
+	}
+
+	return &models.PromoCode{
+		Code:           code,
+		DiscountType:   models.DiscountType(p.DiscountType),
+		DiscountValue:  p.DiscountValue,
+		ValidFrom:      time.Unix(p.ValidFrom, 0).UTC(),
+		ValidUntil:     time.Unix(p.ValidUntil, 0).UTC(),
+		MaxRedemptions: p.MaxRedemptions,
+		PerUserLimit:   p.PerUserLimit,
+		Redemptions:    p.Redemptions,
+		ScopeType:      models.PromoScope(p.ScopeType),
+		ScopeID:        p.ScopeID,
+		CreatedBy:      p.CreatedBy,
+	}, nil
+}
+
+func (r *PromoRepositoryDDB) GetUserRedemptions(ctx context.Context, code, userID string) (int, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       redemptionKey(code, userID),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get promo redemptions: %w", err)
+	}
+	if out.Item == nil {
+		return 0, nil
+	}
+
+	var rec struct {
+		Redemptions int `dynamodbav:"redemptions"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &rec); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal promo redemptions: %w", err)
+	}
+	return rec.Redemptions, nil
+}
+
+// RedemptionItems returns the transaction items that count one redemption of
+// promo by userID. They fail if the code has expired or either the total or
+// the per-user limit has been reached, so they can be written together with
+// the booking they discount.
+func RedemptionItems(tableName string, promo *models.PromoCode, userID string, now time.Time) []types.TransactWriteItem {
+	nowAV := &types.AttributeValueMemberN{Value: fmt.Sprint(now.Unix())}
+	one := &types.AttributeValueMemberN{Value: "1"}
+	zero := &types.AttributeValueMemberN{Value: "0"}
+
+	total := &types.Update{
+		TableName:           aws.String(tableName),
+		Key:                 promoKey(promo.Code),
+		UpdateExpression:    aws.String("SET redemptions = if_not_exists(redemptions, :zero) + :one"),
+		ConditionExpression: aws.String("attribute_exists(pk) AND valid_from <= :now AND valid_until >= :now AND (max_redemptions = :zero OR redemptions < max_redemptions)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":  nowAV,
+			":one":  one,
+			":zero": zero,
+		},
+	}
+
+	perUser := &types.Update{
+		TableName:        aws.String(tableName),
+		Key:              redemptionKey(promo.Code, userID),
+		UpdateExpression: aws.String("SET redemptions = if_not_exists(redemptions, :zero) + :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":  one,
+			":zero": zero,
+		},
+	}
+	if promo.PerUserLimit > 0 {
+		perUser.ConditionExpression = aws.String("attribute_not_exists(redemptions) OR redemptions < :limit")
+		perUser.ExpressionAttributeValues[":limit"] = &types.AttributeValueMemberN{Value: fmt.Sprint(promo.PerUserLimit)}
+	}
+
+	return []types.TransactWriteItem{{Update: total}, {Update: perUser}}
+}
+
+// ReleaseItems returns the transaction items that give back one redemption of
+// code by userID, for a booking that was cancelled or never paid for.
+func ReleaseItems(tableName, code, userID string) []types.TransactWriteItem {
+	values := map[string]types.AttributeValue{
+		":minus": &types.AttributeValueMemberN{Value: "-1"},
+	}
+	return []types.TransactWriteItem{
+		{Update: &types.Update{
+			TableName:                 aws.String(tableName),
+			Key:                       promoKey(code),
+			UpdateExpression:          aws.String("ADD redemptions :minus"),
+			ExpressionAttributeValues: values,
+		}},
+		{Update: &types.Update{
+			TableName:                 aws.String(tableName),
+			Key:                       redemptionKey(code, userID),
+			UpdateExpression:          aws.String("ADD redemptions :minus"),
+			ExpressionAttributeValues: values,
+		}},
+	}
+}
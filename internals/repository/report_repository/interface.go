@@ -0,0 +1,11 @@
+package reportrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/report_repository_mock.go -package=mocks -source=interface.go
+type ReportRepositoryI interface {
+	ListShowSales(ctx context.Context, hostID string) ([]models.ShowSales, error)
+}
@@ -0,0 +1,281 @@
+package reportrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Sales are kept as counters alongside the host's HOST#<host> / EVENT#<event>
+// items: a STATS#SHOW#<show> item per show with its details, seats sold,
+// revenue and refunds, and a STATS#SHOW#<show>#DAY#<date> item for every day
+// the show sold tickets. The counters are bumped with ADD in the same
+// transactions that write the bookings, so a single query on the host's
+// partition returns everything a report needs. Unlike the show items they
+// carry no TTL, so past shows stay in the report.
+
+type ReportRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewReportRepositoryDDB(db *dynamodb.Client, tableName string) *ReportRepositoryDDB {
+	return &ReportRepositoryDDB{db: db, TableName: tableName}
+}
+
+type ShowStatsDDB struct {
+	PK             string  `dynamodbav:"pk"`
+	SK             string  `dynamodbav:"sk"`
+	ShowID         string  `dynamodbav:"show_id"`
+	EventID        string  `dynamodbav:"event_id"`
+	EventName      string  `dynamodbav:"event_name"`
+	VenueID        string  `dynamodbav:"venue_id"`
+	VenueName      string  `dynamodbav:"venue_name"`
+	ShowDateTime   string  `dynamodbav:"show_date_time"`
+	Status         string  `dynamodbav:"status"`
+	Capacity       int     `dynamodbav:"capacity"`
+	Bookings       int     `dynamodbav:"bookings"`
+	SeatsSold      int     `dynamodbav:"seats_sold"`
+	CancelledSeats int     `dynamodbav:"cancelled_seats"`
+	GrossRevenue   float64 `dynamodbav:"gross_revenue"`
+	Refunds        float64 `dynamodbav:"refunds"`
+}
+
+type DayStatsDDB struct {
+	PK        string  `dynamodbav:"pk"`
+	SK        string  `dynamodbav:"sk"`
+	ShowID    string  `dynamodbav:"show_id"`
+	Date      string  `dynamodbav:"date"`
+	Bookings  int     `dynamodbav:"bookings"`
+	SeatsSold int     `dynamodbav:"seats_sold"`
+	Revenue   float64 `dynamodbav:"revenue"`
+}
+
+func showStatsKey(hostID, showID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "HOST#" + hostID},
+		"sk": &types.AttributeValueMemberS{Value: "STATS#SHOW#" + showID},
+	}
+}
+
+func dayStatsKey(hostID, showID, date string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "HOST#" + hostID},
+		"sk": &types.AttributeValueMemberS{Value: "STATS#SHOW#" + showID + "#DAY#" + date},
+	}
+}
+
+// ShowDetailsUpdate sets descriptive fields (event_name, status, ...) on the
+// show's stats item, creating it if needed.
+func ShowDetailsUpdate(tableName, hostID, showID string, details map[string]any) (*types.Update, error) {
+	sets := []string{"show_id = :show_id"}
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{
+		":show_id": &types.AttributeValueMemberS{Value: showID},
+	}
+	fields := make([]string, 0, len(details))
+	for field := range details {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for i, field := range fields {
+		av, err := attributevalue.Marshal(details[field])
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", field, err)
+		}
+		name, value := fmt.Sprintf("#f%d", i), fmt.Sprintf(":f%d", i)
+		sets = append(sets, name+" = "+value)
+		names[name] = field
+		values[value] = av
+	}
+
+	return &types.Update{
+		TableName:                 aws.String(tableName),
+		Key:                       showStatsKey(hostID, showID),
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}, nil
+}
+
+// SaleDate is the day bucket a sale made at the given time is counted in.
+func SaleDate(at time.Time) string {
+	return at.UTC().Format("2006-01-02")
+}
+
+// SaleItems counts a confirmed booking of seats for amount towards the show
+// and towards the day it was made.
+func SaleItems(tableName, hostID, showID string, at time.Time, seats int, amount float64) []types.TransactWriteItem {
+	date := SaleDate(at)
+	values := map[string]types.AttributeValue{
+		":one":    &types.AttributeValueMemberN{Value: "1"},
+		":seats":  &types.AttributeValueMemberN{Value: fmt.Sprint(seats)},
+		":amount": &types.AttributeValueMemberN{Value: fmt.Sprint(amount)},
+		":sid":    &types.AttributeValueMemberS{Value: showID},
+	}
+	dayValues := map[string]types.AttributeValue{":date": &types.AttributeValueMemberS{Value: date}}
+	for k, v := range values {
+		dayValues[k] = v
+	}
+
+	return []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                 aws.String(tableName),
+				Key:                       showStatsKey(hostID, showID),
+				UpdateExpression:          aws.String("SET show_id = :sid ADD bookings :one, seats_sold :seats, gross_revenue :amount"),
+				ExpressionAttributeValues: values,
+			},
+		},
+		{
+			Update: &types.Update{
+				TableName:                 aws.String(tableName),
+				Key:                       dayStatsKey(hostID, showID, date),
+				UpdateExpression:          aws.String("SET show_id = :sid, #date = :date ADD bookings :one, seats_sold :seats, revenue :amount"),
+				ExpressionAttributeNames:  map[string]string{"#date": "date"},
+				ExpressionAttributeValues: dayValues,
+			},
+		},
+	}
+}
+
+// CancellationItems takes cancelled seats off the show's seats sold and adds
+// the refund paid for them. The seats and the refund also come off the day
+// the booking was sold on, when it is known, so daily sales stay net of
+// cancellations.
+func CancellationItems(tableName, hostID, showID, saleDate string, seats int, refund float64) []types.TransactWriteItem {
+	values := map[string]types.AttributeValue{
+		":sid":    &types.AttributeValueMemberS{Value: showID},
+		":sold":   &types.AttributeValueMemberN{Value: fmt.Sprint(-seats)},
+		":seats":  &types.AttributeValueMemberN{Value: fmt.Sprint(seats)},
+		":refund": &types.AttributeValueMemberN{Value: fmt.Sprint(refund)},
+	}
+	items := []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName:                 aws.String(tableName),
+				Key:                       showStatsKey(hostID, showID),
+				UpdateExpression:          aws.String("SET show_id = :sid ADD seats_sold :sold, cancelled_seats :seats, refunds :refund"),
+				ExpressionAttributeValues: values,
+			},
+		},
+	}
+	if saleDate == "" {
+		return items
+	}
+
+	return append(items, types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:        aws.String(tableName),
+			Key:              dayStatsKey(hostID, showID, saleDate),
+			UpdateExpression: aws.String("SET show_id = :sid, #date = :date ADD seats_sold :sold, revenue :revenue"),
+			ExpressionAttributeNames: map[string]string{
+				"#date": "date",
+			},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":sid":     values[":sid"],
+				":date":    &types.AttributeValueMemberS{Value: saleDate},
+				":sold":    values[":sold"],
+				":revenue": &types.AttributeValueMemberN{Value: fmt.Sprint(-refund)},
+			},
+		},
+	})
+}
+
+// ListShowSales returns the sales of every show the host has created, with
+// its daily sales in date order.
+func (rr *ReportRepositoryDDB) ListShowSales(ctx context.Context, hostID string) ([]models.ShowSales, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(rr.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :sk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: "HOST#" + hostID},
+			":sk": &types.AttributeValueMemberS{Value: "STATS#SHOW#"},
+		},
+	}
+
+	var shows []models.ShowSales
+	index := map[string]int{}
+	daily := map[string][]models.DailySales{}
+	for {
+		out, err := rr.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query sales: %w", err)
+		}
+
+		for _, item := range out.Items {
+			var sk struct {
+				SK string `dynamodbav:"sk"`
+			}
+			if err := attributevalue.UnmarshalMap(item, &sk); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal sales: %w", err)
+			}
+
+			if strings.Contains(sk.SK, "#DAY#") {
+				var d DayStatsDDB
+				if err := attributevalue.UnmarshalMap(item, &d); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal daily sales: %w", err)
+				}
+				daily[d.ShowID] = append(daily[d.ShowID], models.DailySales{
+					Date:      d.Date,
+					Bookings:  d.Bookings,
+					SeatsSold: d.SeatsSold,
+					Revenue:   d.Revenue,
+				})
+				continue
+			}
+
+			var s ShowStatsDDB
+			if err := attributevalue.UnmarshalMap(item, &s); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal show sales: %w", err)
+			}
+			index[s.ShowID] = len(shows)
+			shows = append(shows, models.ShowSales{
+				ShowID:       s.ShowID,
+				EventID:      s.EventID,
+				EventName:    s.EventName,
+				VenueID:      s.VenueID,
+				VenueName:    s.VenueName,
+				ShowDateTime: s.ShowDateTime,
+				Status:       s.Status,
+				SalesTotals: models.SalesTotals{
+					Shows:          1,
+					Capacity:       s.Capacity,
+					SeatsSold:      s.SeatsSold,
+					CancelledSeats: s.CancelledSeats,
+					Bookings:       s.Bookings,
+					GrossRevenue:   s.GrossRevenue,
+					Refunds:        s.Refunds,
+				},
+			})
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	for showID, days := range daily {
+		i, ok := index[showID]
+		if !ok {
+			continue
+		}
+		sort.Slice(days, func(a, b int) bool { return days[a].Date < days[b].Date })
+		shows[i].Daily = days
+	}
+	for i := range shows {
+		if shows[i].Daily == nil {
+			shows[i].Daily = []models.DailySales{}
+		}
+	}
+	return shows, nil
+}
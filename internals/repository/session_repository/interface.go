@@ -0,0 +1,15 @@
+package sessionrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/session_repository_mock.go -package=mocks -source=interface.go
+type SessionRepositoryI interface {
+	Create(ctx context.Context, session *models.Session, tokenHash string) error
+	Get(ctx context.Context, sessionID string) (*models.Session, error)
+	Rotate(ctx context.Context, sessionID, oldHash, newHash string, expiresAt time.Time) (*models.Session, error)
+	Revoke(ctx context.Context, sessionID string) error
+}
@@ -0,0 +1,181 @@
+package sessionrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Each session is a SESSION#<id> / DETAILS item holding a hash of its current
+// refresh token and of every token it replaced. expires_at is the table's TTL
+// attribute and moves forward on every refresh, so idle sessions disappear
+// on their own. Refresh tokens themselves are never stored.
+
+type SessionRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewSessionRepositoryDDB(db *dynamodb.Client, tableName string) *SessionRepositoryDDB {
+	return &SessionRepositoryDDB{db: db, TableName: tableName}
+}
+
+type SessionDDB struct {
+	PK           string   `dynamodbav:"pk"`
+	SK           string   `dynamodbav:"sk"`
+	UserEmail    string   `dynamodbav:"user_email"`
+	TokenVersion int      `dynamodbav:"token_version"`
+	TokenHash    string   `dynamodbav:"token_hash"`
+	UsedHashes   []string `dynamodbav:"used_hashes,stringset,omitempty"`
+	Revoked      bool     `dynamodbav:"revoked"`
+	TwoFactor    bool     `dynamodbav:"two_factor"`
+	CreatedAt    string   `dynamodbav:"created_at"`
+	ExpiresAt    int64    `dynamodbav:"expires_at"`
+}
+
+func sessionKey(sessionID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SESSION#" + sessionID},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+func (s SessionDDB) toModel(sessionID string) models.Session {
+	createdAt, _ := time.Parse(time.RFC3339, s.CreatedAt)
+	return models.Session{
+		SessionID:    sessionID,
+		UserEmail:    s.UserEmail,
+		TokenVersion: s.TokenVersion,
+		CreatedAt:    createdAt,
+		ExpiresAt:    time.Unix(s.ExpiresAt, 0).UTC(),
+		Revoked:      s.Revoked,
+		TwoFactor:    s.TwoFactor,
+	}
+}
+
+func (sr *SessionRepositoryDDB) Create(ctx context.Context, session *models.Session, tokenHash string) error {
+	item, err := attributevalue.MarshalMap(SessionDDB{
+		PK:           "SESSION#" + session.SessionID,
+		SK:           "DETAILS",
+		UserEmail:    session.UserEmail,
+		TokenVersion: session.TokenVersion,
+		TokenHash:    tokenHash,
+		TwoFactor:    session.TwoFactor,
+		CreatedAt:    session.CreatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:    session.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = sr.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(sr.TableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// Get returns the session, or nil if it does not exist.
+func (sr *SessionRepositoryDDB) Get(ctx context.Context, sessionID string) (*models.Session, error) {
+	out, err := sr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(sr.TableName),
+		Key:       sessionKey(sessionID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+
+	var item SessionDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	session := item.toModel(sessionID)
+	return &session, nil
+}
+
+// Rotate swaps the session's refresh token hash from oldHash to newHash and
+// extends it to expiresAt. If oldHash is a token the session already
+// replaced it returns ErrRefreshTokenReused and leaves revoking the session
+// to the caller.
+func (sr *SessionRepositoryDDB) Rotate(ctx context.Context, sessionID, oldHash, newHash string, expiresAt time.Time) (*models.Session, error) {
+	out, err := sr.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(sr.TableName),
+		Key:                 sessionKey(sessionID),
+		UpdateExpression:    aws.String("SET token_hash = :new, expires_at = :exp ADD used_hashes :used"),
+		ConditionExpression: aws.String("token_hash = :old AND revoked = :false AND expires_at > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":new":   &types.AttributeValueMemberS{Value: newHash},
+			":old":   &types.AttributeValueMemberS{Value: oldHash},
+			":used":  &types.AttributeValueMemberSS{Value: []string{oldHash}},
+			":exp":   &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+			":now":   &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+			":false": &types.AttributeValueMemberBOOL{Value: false},
+		},
+		ReturnValues:                        types.ReturnValueAllNew,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if !errors.As(err, &cce) {
+			return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+		if len(cce.Item) == 0 {
+			return nil, models.ErrInvalidRefreshToken
+		}
+		var current SessionDDB
+		if err := attributevalue.UnmarshalMap(cce.Item, &current); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		switch {
+		case current.Revoked || current.ExpiresAt <= time.Now().Unix():
+			return nil, models.ErrSessionRevoked
+		case slices.Contains(current.UsedHashes, oldHash):
+			return nil, models.ErrRefreshTokenReused
+		}
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	var item SessionDDB
+	if err := attributevalue.UnmarshalMap(out.Attributes, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	session := item.toModel(sessionID)
+	return &session, nil
+}
+
+// Revoke ends the session; its refresh token and access tokens stop working.
+func (sr *SessionRepositoryDDB) Revoke(ctx context.Context, sessionID string) error {
+	_, err := sr.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(sr.TableName),
+		Key:                 sessionKey(sessionID),
+		UpdateExpression:    aws.String("SET revoked = :true"),
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return models.ErrSessionRevoked
+		}
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
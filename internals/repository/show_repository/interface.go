@@ -3,13 +3,23 @@ package showrepository
 import (
 	"context"
 	"eventro_aws/internals/models"
+	"time"
 )
 
 //go:generate mockgen -destination=../../mocks/show_repository_mock.go -package=mocks -source=interface.go
 type ShowRepositoryI interface {
 	Create(ctx context.Context, show *models.Show) error
+	CreateBatch(ctx context.Context, shows []*models.Show) error
 	GetByID(ctx context.Context, id string) (*models.ShowDTO, error)
 	ListByEvent(ctx context.Context, eventID, city, date, venueID, hostID string) ([]models.ShowDTO, error)
+	ListByVenue(ctx context.Context, venueID string) ([]models.ShowDTO, error)
 	Update(ctx context.Context, showID string, isBlocked bool) error
-	UpdateShowBooking(ctx context.Context, booking models.Booking) error
+	Cancel(ctx context.Context, show *models.ShowDTO, reason, cancelledBy string) error
+	Reschedule(ctx context.Context, show *models.ShowDTO, newShowDateTime string, endsAt time.Time, scheduleVersion int64) error
+	GetVenueSchedule(ctx context.Context, venueID string, from, to time.Time) (*models.VenueSchedule, error)
+	BackfillVenueSlots(ctx context.Context, now time.Time) (int, error)
+	UpdatePrice(ctx context.Context, show *models.ShowDTO, price float64, tiers []models.PriceTier) error
+	CreateSeries(ctx context.Context, series *models.ShowSeries) error
+	GetSeries(ctx context.Context, seriesID string) (*models.ShowSeries, error)
+	UpdateSeries(ctx context.Context, series *models.ShowSeries) error
 }
@@ -0,0 +1,230 @@
+package showrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SeriesDDB is the SERIES#<id> item. Occurrences maps each show created
+// from the series to the show_date_time it was created at.
+type SeriesDDB struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	HostID    string `dynamodbav:"host_id"`
+	Status    string `dynamodbav:"status"`
+	CreatedAt string `dynamodbav:"created_at"`
+	models.SeriesSpec
+
+	Occurrences map[string]string `dynamodbav:"occurrences"`
+}
+
+func seriesKey(seriesID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SERIES#" + seriesID},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+// seriesOccurrencesUpdate adds shows to the series' occurrences map.
+func seriesOccurrencesUpdate(tableName, seriesID string, occurrences map[string]types.AttributeValue) *types.Update {
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	var sets []string
+
+	ids := make([]string, 0, len(occurrences))
+	for id := range occurrences {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		name, value := fmt.Sprintf("#s%d", i), fmt.Sprintf(":s%d", i)
+		names[name] = id
+		values[value] = occurrences[id]
+		sets = append(sets, "occurrences."+name+" = "+value)
+	}
+
+	return &types.Update{
+		TableName:                 aws.String(tableName),
+		Key:                       seriesKey(seriesID),
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ")),
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+}
+
+// CreateSeries stores the series with no occurrences; CreateBatch adds them
+// as their shows are written.
+func (r *ShowRepositoryDDB) CreateSeries(ctx context.Context, series *models.ShowSeries) error {
+	item, err := attributevalue.MarshalMap(SeriesDDB{
+		PK:          "SERIES#" + series.ID,
+		SK:          "DETAILS",
+		HostID:      series.HostID,
+		Status:      series.Status,
+		CreatedAt:   series.CreatedAt,
+		SeriesSpec:  series.SeriesSpec,
+		Occurrences: map[string]string{},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal series: %w", err)
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(r.TableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create series: %w", err)
+	}
+	return nil
+}
+
+// GetSeries returns the series with its occurrences ordered by the time they
+// were created at. Occurrence status is left for the caller to fill in from
+// the shows themselves.
+func (r *ShowRepositoryDDB) GetSeries(ctx context.Context, seriesID string) (*models.ShowSeries, error) {
+	if seriesID == "" {
+		return nil, errors.New("series id is required")
+	}
+
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       seriesKey(seriesID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var row SeriesDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &row); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal series: %w", err)
+	}
+
+	series := &models.ShowSeries{
+		ID:          seriesID,
+		HostID:      row.HostID,
+		Status:      row.Status,
+		CreatedAt:   row.CreatedAt,
+		SeriesSpec:  row.SeriesSpec,
+		Occurrences: make([]models.SeriesOccurrence, 0, len(row.Occurrences)),
+	}
+	for showID, showDateTime := range row.Occurrences {
+		startsAt, _ := time.ParseInLocation("2006-01-02T15:04", showDateTime, time.UTC)
+		series.Occurrences = append(series.Occurrences, models.SeriesOccurrence{
+			ShowID:   showID,
+			StartsAt: startsAt,
+		})
+	}
+	sort.Slice(series.Occurrences, func(i, j int) bool {
+		return series.Occurrences[i].StartsAt.Before(series.Occurrences[j].StartsAt)
+	})
+	return series, nil
+}
+
+// UpdateSeries saves the series' pricing and status. Occurrences are only
+// changed through CreateBatch.
+func (r *ShowRepositoryDDB) UpdateSeries(ctx context.Context, series *models.ShowSeries) error {
+	values := map[string]types.AttributeValue{
+		":price":  &types.AttributeValueMemberN{Value: fmt.Sprint(series.Price)},
+		":status": &types.AttributeValueMemberS{Value: series.Status},
+	}
+	update := "SET price = :price, #status = :status"
+	if len(series.PriceTiers) > 0 {
+		tiers, err := attributevalue.Marshal(series.PriceTiers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal price tiers: %w", err)
+		}
+		values[":tiers"] = tiers
+		update += ", price_tiers = :tiers"
+	} else {
+		update += " REMOVE price_tiers"
+	}
+
+	_, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.TableName),
+		Key:                 seriesKey(series.ID),
+		UpdateExpression:    aws.String(update),
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+	return nil
+}
+
+// UpdatePrice reprices a show that has not been cancelled, along with its
+// event/city index item used in listings.
+func (r *ShowRepositoryDDB) UpdatePrice(ctx context.Context, show *models.ShowDTO, price float64, tiers []models.PriceTier) error {
+	values := map[string]types.AttributeValue{
+		":price":     &types.AttributeValueMemberN{Value: fmt.Sprint(price)},
+		":cancelled": &types.AttributeValueMemberS{Value: models.ShowCancelled},
+	}
+	update := "SET price = :price"
+	if len(tiers) > 0 {
+		av, err := attributevalue.Marshal(tiers)
+		if err != nil {
+			return fmt.Errorf("failed to marshal price tiers: %w", err)
+		}
+		values[":tiers"] = av
+		update += ", price_tiers = :tiers"
+	} else {
+		update += " REMOVE price_tiers"
+	}
+
+	_, err := r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.TableName),
+					Key:                 showKey(show.ID),
+					UpdateExpression:    aws.String(update),
+					ConditionExpression: aws.String("attribute_exists(pk) AND (attribute_not_exists(#status) OR #status <> :cancelled)"),
+					ExpressionAttributeNames: map[string]string{
+						"#status": "status",
+					},
+					ExpressionAttributeValues: values,
+				},
+			},
+			{
+				Update: &types.Update{
+					TableName:        aws.String(r.TableName),
+					Key:              eventDateKey(show, show.ShowDateTime()),
+					UpdateExpression: aws.String("SET price = :price"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":price": values[":price"],
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		var tce *types.TransactionCanceledException
+		if errors.As(err, &tce) && len(tce.CancellationReasons) > 0 &&
+			aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return errors.New("cannot reprice a cancelled show")
+		}
+		return fmt.Errorf("failed to update show price: %w", err)
+	}
+
+	show.Price = price
+	show.PriceTiers = tiers
+	return nil
+}
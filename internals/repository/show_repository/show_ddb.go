@@ -5,6 +5,7 @@ import (
 
 	"errors"
 	"eventro_aws/internals/models"
+	reportrepository "eventro_aws/internals/repository/report_repository"
 	venuerepository "eventro_aws/internals/repository/venue_repository"
 	"fmt"
 	"strings"
@@ -16,6 +17,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// MaxShowsPerBatch keeps CreateBatch within DynamoDB's 100 item transaction
+// limit: four items per show plus four shared ones.
+const MaxShowsPerBatch = 24
+
 type ShowRepositoryDDB struct {
 	db        *dynamodb.Client
 	TableName string
@@ -37,42 +42,36 @@ type ShowDDB struct {
 	BookedSeats  []string `dynamodbav:"booked_seats"`
 	IsBlocked    bool     `dynamodbav:"is_blocked"`
 	HostID       string   `dynamodbav:"host_id"`
+	Status       string   `dynamodbav:"status"`
+	CancelReason string   `dynamodbav:"cancel_reason"`
+	CancelledAt  string   `dynamodbav:"cancelled_at"`
+	SeriesID     string   `dynamodbav:"series_id"`
+
+	CancellationPolicy *models.CancellationPolicy `dynamodbav:"cancellation_policy,omitempty"`
+	PriceTiers         []models.PriceTier         `dynamodbav:"price_tiers,omitempty"`
 }
 
 func (r *ShowRepositoryDDB) Create(ctx context.Context, show *models.Show) error {
+	return r.CreateBatch(ctx, []*models.Show{show})
+}
 
-	createdAt := show.CreatedAt.Format(time.RFC3339)
-	showDateTime := show.ShowDate.Format("2006-01-02") + "T" + show.ShowTime
-
-	venueRepo := venuerepository.NewVenueRepositoryDDB(r.db, r.TableName)
-	venue, _ := venueRepo.GetByID(ctx, show.VenueID)
-	city := venue.City
-
-	layout := "2006-01-02T15:04"
-	t, err := time.ParseInLocation(layout, showDateTime, time.UTC)
-	if err != nil {
-		return fmt.Errorf("error parsing time: %v", err)
+// CreateBatch writes shows of the same event, venue and host in a single
+// transaction, checked against the first show's ScheduleVersion. Shows that
+// belong to a series are added to the series' occurrences as well.
+func (r *ShowRepositoryDDB) CreateBatch(ctx context.Context, shows []*models.Show) error {
+	if len(shows) == 0 {
+		return nil
 	}
-	expires_at := t.Unix()
-
-	showItem := map[string]any{
-		"pk":             "SHOW#" + show.ID,
-		"sk":             "DETAILS",
-		"city":           city,
-		"venue_id":       show.VenueID,
-		"event_id":       show.EventID,
-		"created_at":     createdAt,
-		"price":          show.Price,
-		"show_date_time": showDateTime,
-		"booked_seats":   show.BookedSeats,
-		"is_blocked":     show.IsBlocked,
-		"host_id":        show.HostID,
-		"expires_at":     expires_at,
+	if len(shows) > MaxShowsPerBatch {
+		return fmt.Errorf("cannot create more than %d shows at once", MaxShowsPerBatch)
 	}
+	first := shows[0]
 
-	avShow, _ := attributevalue.MarshalMap(showItem)
+	venueRepo := venuerepository.NewVenueRepositoryDDB(r.db, r.TableName)
+	venue, _ := venueRepo.GetByID(ctx, first.VenueID)
+	city := venue.City
 
-	eventPK := "EVENT#" + show.EventID
+	eventPK := "EVENT#" + first.EventID
 	evtOut, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(r.TableName),
 		Key: map[string]types.AttributeValue{
@@ -84,7 +83,7 @@ func (r *ShowRepositoryDDB) Create(ctx context.Context, show *models.Show) error
 		return err
 	}
 	if evtOut.Item == nil {
-		return fmt.Errorf("event does not exist: %s", show.EventID)
+		return fmt.Errorf("event does not exist: %s", first.EventID)
 	}
 
 	var eventRec struct {
@@ -97,75 +96,293 @@ func (r *ShowRepositoryDDB) Create(ctx context.Context, show *models.Show) error
 	}
 	attributevalue.UnmarshalMap(evtOut.Item, &eventRec)
 
-	cityEventItem := map[string]any{
-		"pk":          "CITY#" + city,
-		"sk":          "EVENT#" + show.EventID,
-		"event_name":  eventRec.EventName,
-		"description": eventRec.Description,
-		"duration":    eventRec.Duration,
-		"category":    eventRec.Category,
-		"is_blocked":  eventRec.IsBlocked,
-		"artist_ids":  eventRec.ArtistIDs,
-		"expires_at":  expires_at,
+	seatLayout := models.DefaultSeatLayout()
+	if venue.SeatLayout != nil {
+		seatLayout = *venue.SeatLayout
 	}
-	avCityEvent, _ := attributevalue.MarshalMap(cityEventItem)
+	capacity := len(seatLayout.Seats())
 
-	pk3 := "EVENT#" + show.EventID + "#CITY#" + city
-	sk3 := "DATE#" + showDateTime + "#VENUE#" + show.VenueID + "#SHOW#" + show.ID
+	layout := "2006-01-02T15:04"
+	var txItems []types.TransactWriteItem
+	var latest int64
+	occurrences := map[string]types.AttributeValue{}
 
-	eventDateItem := map[string]any{
-		"pk":         pk3,
-		"sk":         sk3,
-		"is_blocked": show.IsBlocked,
-		"price":      show.Price,
-		"expires_at": expires_at,
-	}
-	avEventDate, _ := attributevalue.MarshalMap(eventDateItem)
+	for _, show := range shows {
+		createdAt := show.CreatedAt.Format(time.RFC3339)
+		showDateTime := show.ShowDate.Format("2006-01-02") + "T" + show.ShowTime
 
-	hostItem := map[string]any{
-		"pk":         "HOST#" + show.HostID,
-		"sk":         "EVENT#" + show.EventID,
-		"expires_at": expires_at,
-	}
-	avHost, _ := attributevalue.MarshalMap(hostItem)
+		t, err := time.ParseInLocation(layout, showDateTime, time.UTC)
+		if err != nil {
+			return fmt.Errorf("error parsing time: %v", err)
+		}
+		expires_at := t.Unix()
+		if expires_at > latest {
+			latest = expires_at
+		}
 
-	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
-		TransactItems: []types.TransactWriteItem{
-			{
+		showItem := map[string]any{
+			"pk":             "SHOW#" + show.ID,
+			"sk":             "DETAILS",
+			"city":           city,
+			"venue_id":       show.VenueID,
+			"event_id":       show.EventID,
+			"created_at":     createdAt,
+			"price":          show.Price,
+			"show_date_time": showDateTime,
+			"booked_seats":   show.BookedSeats,
+			"is_blocked":     show.IsBlocked,
+			"host_id":        show.HostID,
+			"status":         models.ShowScheduled,
+			"expires_at":     expires_at,
+		}
+		if show.CancellationPolicy != nil {
+			showItem["cancellation_policy"] = show.CancellationPolicy
+		}
+		if len(show.PriceTiers) > 0 {
+			showItem["price_tiers"] = show.PriceTiers
+		}
+		if show.SeriesID != "" {
+			showItem["series_id"] = show.SeriesID
+			occurrences[show.ID] = &types.AttributeValueMemberS{Value: showDateTime}
+		}
+
+		avShow, _ := attributevalue.MarshalMap(showItem)
+
+		pk3 := "EVENT#" + show.EventID + "#CITY#" + city
+		sk3 := "DATE#" + showDateTime + "#VENUE#" + show.VenueID + "#SHOW#" + show.ID
+
+		eventDateItem := map[string]any{
+			"pk":         pk3,
+			"sk":         sk3,
+			"is_blocked": show.IsBlocked,
+			"price":      show.Price,
+			"expires_at": expires_at,
+		}
+		avEventDate, _ := attributevalue.MarshalMap(eventDateItem)
+
+		venueSlot, err := venueSlotItem(show.VenueID, show.ID, t, show.EndsAt)
+		if err != nil {
+			return err
+		}
+
+		statsUpdate, err := reportrepository.ShowDetailsUpdate(r.TableName, show.HostID, show.ID, map[string]any{
+			"event_id":       show.EventID,
+			"event_name":     eventRec.EventName,
+			"venue_id":       show.VenueID,
+			"venue_name":     venue.Name,
+			"show_date_time": showDateTime,
+			"status":         models.ShowScheduled,
+			"capacity":       capacity,
+		})
+		if err != nil {
+			return err
+		}
+
+		txItems = append(txItems,
+			types.TransactWriteItem{
 				Put: &types.Put{
 					TableName:           aws.String(r.TableName),
 					Item:                avShow,
 					ConditionExpression: aws.String("attribute_not_exists(pk)"),
 				},
 			},
-			{
-				Put: &types.Put{
-					TableName: aws.String(r.TableName),
-					Item:      avCityEvent,
-				},
-			},
-			{
+			types.TransactWriteItem{
 				Put: &types.Put{
 					TableName: aws.String(r.TableName),
 					Item:      avEventDate,
 				},
 			},
-			{
+			types.TransactWriteItem{
 				Put: &types.Put{
 					TableName: aws.String(r.TableName),
-					Item:      avHost,
+					Item:      venueSlot,
 				},
 			},
+			types.TransactWriteItem{Update: statsUpdate},
+		)
+	}
+
+	// the city and host items are shared by every show in the batch, so they
+	// are written once with the latest expiry
+	cityEventItem := map[string]any{
+		"pk":          "CITY#" + city,
+		"sk":          "EVENT#" + first.EventID,
+		"event_name":  eventRec.EventName,
+		"description": eventRec.Description,
+		"duration":    eventRec.Duration,
+		"category":    eventRec.Category,
+		"is_blocked":  eventRec.IsBlocked,
+		"artist_ids":  eventRec.ArtistIDs,
+		"expires_at":  latest,
+	}
+	avCityEvent, _ := attributevalue.MarshalMap(cityEventItem)
+
+	hostItem := map[string]any{
+		"pk":         "HOST#" + first.HostID,
+		"sk":         "EVENT#" + first.EventID,
+		"expires_at": latest,
+	}
+	avHost, _ := attributevalue.MarshalMap(hostItem)
+
+	txItems = append(txItems,
+		types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(r.TableName),
+				Item:      avCityEvent,
+			},
+		},
+		types.TransactWriteItem{
+			Put: &types.Put{
+				TableName: aws.String(r.TableName),
+				Item:      avHost,
+			},
 		},
+	)
+	versionIdx := len(txItems)
+	txItems = append(txItems, types.TransactWriteItem{Update: r.scheduleVersionUpdate(first.VenueID, first.ScheduleVersion)})
+
+	if len(occurrences) > 0 {
+		txItems = append(txItems, types.TransactWriteItem{Update: seriesOccurrencesUpdate(r.TableName, first.SeriesID, occurrences)})
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: txItems,
 	})
 
 	if err != nil {
+		if scheduleChanged(err, versionIdx) {
+			return models.ErrScheduleChanged
+		}
 		return fmt.Errorf("transaction failed: %w", err)
 	}
 
 	return nil
 }
 
+// Each show also occupies a SHOW#<start>#<show> slot under its venue, and the
+// venue's SCHEDULE item carries a version bumped whenever a slot is added or
+// moved. Writers check the version they read, so two hosts cannot both book
+// the same free slot.
+
+type VenueSlotDDB struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	ShowID    string `dynamodbav:"show_id"`
+	StartsAt  int64  `dynamodbav:"starts_at"`
+	EndsAt    int64  `dynamodbav:"ends_at"`
+	ExpiresAt int64  `dynamodbav:"expires_at"`
+}
+
+func venueSlotKey(venueID, showDateTime, showID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+		"sk": &types.AttributeValueMemberS{Value: "SHOW#" + showDateTime + "#" + showID},
+	}
+}
+
+func venueSlotItem(venueID, showID string, startsAt, endsAt time.Time) (map[string]types.AttributeValue, error) {
+	if endsAt.Before(startsAt) {
+		endsAt = startsAt
+	}
+	return attributevalue.MarshalMap(VenueSlotDDB{
+		PK:        "VENUE#" + venueID,
+		SK:        "SHOW#" + startsAt.UTC().Format("2006-01-02T15:04") + "#" + showID,
+		ShowID:    showID,
+		StartsAt:  startsAt.Unix(),
+		EndsAt:    endsAt.Unix(),
+		ExpiresAt: endsAt.Unix(),
+	})
+}
+
+func (r *ShowRepositoryDDB) scheduleVersionUpdate(venueID string, version int64) *types.Update {
+	values := map[string]types.AttributeValue{
+		":next": &types.AttributeValueMemberN{Value: fmt.Sprint(version + 1)},
+	}
+	condition := "attribute_not_exists(pk)"
+	if version > 0 {
+		condition = "version = :v"
+		values[":v"] = &types.AttributeValueMemberN{Value: fmt.Sprint(version)}
+	}
+
+	return &types.Update{
+		TableName: aws.String(r.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+			"sk": &types.AttributeValueMemberS{Value: "SCHEDULE"},
+		},
+		UpdateExpression:          aws.String("SET version = :next"),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+	}
+}
+
+func scheduleChanged(err error, idx int) bool {
+	var tce *types.TransactionCanceledException
+	return errors.As(err, &tce) && len(tce.CancellationReasons) > idx &&
+		aws.ToString(tce.CancellationReasons[idx].Code) == "ConditionalCheckFailed"
+}
+
+// GetVenueSchedule returns the venue's slots starting between from and to,
+// with the schedule version they were read at.
+func (r *ShowRepositoryDDB) GetVenueSchedule(ctx context.Context, venueID string, from, to time.Time) (*models.VenueSchedule, error) {
+	versionOut, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+			"sk": &types.AttributeValueMemberS{Value: "SCHEDULE"},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch venue schedule: %w", err)
+	}
+	var version struct {
+		Version int64 `dynamodbav:"version"`
+	}
+	if versionOut.Item != nil {
+		if err := attributevalue.UnmarshalMap(versionOut.Item, &version); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal venue schedule: %w", err)
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :from AND :to"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":   &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+			":from": &types.AttributeValueMemberS{Value: "SHOW#" + from.UTC().Format("2006-01-02T15:04")},
+			":to":   &types.AttributeValueMemberS{Value: "SHOW#" + to.UTC().Format("2006-01-02T15:04") + "#~"},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	schedule := &models.VenueSchedule{Version: version.Version}
+	for {
+		out, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query venue schedule: %w", err)
+		}
+		var rows []VenueSlotDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal venue schedule: %w", err)
+		}
+		for _, row := range rows {
+			schedule.Slots = append(schedule.Slots, models.VenueSlot{
+				ShowID:   row.ShowID,
+				VenueID:  venueID,
+				StartsAt: time.Unix(row.StartsAt, 0).UTC(),
+				EndsAt:   time.Unix(row.EndsAt, 0).UTC(),
+			})
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	return schedule, nil
+}
+
 func (r *ShowRepositoryDDB) GetByID(ctx context.Context, id string) (*models.ShowDTO, error) {
 	if id == "" {
 		return nil, errors.New("id is required")
@@ -197,24 +414,97 @@ func (r *ShowRepositoryDDB) GetByID(ctx context.Context, id string) (*models.Sho
 	date, _ := time.Parse("2006-01-02", parts[0])
 	timeStr := parts[1]
 
-	venueDTO, err := r.getVenueDTO(ctx, showDDB.VenueID)
+	venueDTO, layout, err := r.getVenueDTO(ctx, showDDB.VenueID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch venue: %w", err)
 	}
 
+	heldSeats, err := r.getHeldSeats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := models.DefaultCancellationPolicy()
+	if showDDB.CancellationPolicy != nil {
+		policy = *showDDB.CancellationPolicy
+	}
+
+	status := showDDB.Status
+	if status == "" {
+		status = models.ShowScheduled
+	}
+
 	return &models.ShowDTO{
-		ID:          id,
-		EventID:     showDDB.EventID,
-		Price:       float64(showDDB.Price),
-		ShowDate:    date,
-		ShowTime:    timeStr,
-		BookedSeats: showDDB.BookedSeats,
-		Venue:       *venueDTO,
-		IsBlocked:   showDDB.IsBlocked,
-		HostID:      showDDB.HostID,
+		ID:             id,
+		EventID:        showDDB.EventID,
+		Price:          float64(showDDB.Price),
+		ShowDate:       date,
+		ShowTime:       timeStr,
+		BookedSeats:    showDDB.BookedSeats,
+		HeldSeats:      heldSeats,
+		AvailableSeats: freeSeats(layout.Seats(), showDDB.BookedSeats, heldSeats),
+		Venue:          *venueDTO,
+		IsBlocked:      showDDB.IsBlocked,
+		HostID:         showDDB.HostID,
+		Status:         status,
+		CancelReason:   showDDB.CancelReason,
+		CancelledAt:    showDDB.CancelledAt,
+		SeriesID:       showDDB.SeriesID,
+
+		CancellationPolicy: policy,
+		PriceTiers:         showDDB.PriceTiers,
+		SeatLayout:         layout,
 	}, nil
 }
 
+// getHeldSeats returns the seats under an unexpired hold. Expired hold items
+// may linger until the table TTL removes them, so they are filtered here.
+func (r *ShowRepositoryDDB) getHeldSeats(ctx context.Context, showID string) ([]string, error) {
+	out, err := r.db.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :sk)"),
+		FilterExpression:       aws.String("expires_at >= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			":sk":  &types.AttributeValueMemberS{Value: "HOLD_SEAT#"},
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(time.Now().Unix())},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query held seats: %w", err)
+	}
+
+	held := make([]string, 0, len(out.Items))
+	for _, item := range out.Items {
+		var row struct {
+			SK string `dynamodbav:"sk"`
+		}
+		if err := attributevalue.UnmarshalMap(item, &row); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal held seat: %w", err)
+		}
+		held = append(held, strings.TrimPrefix(row.SK, "HOLD_SEAT#"))
+	}
+	return held, nil
+}
+
+func freeSeats(all, booked, held []string) []string {
+	taken := make(map[string]bool, len(booked)+len(held))
+	for _, s := range booked {
+		taken[s] = true
+	}
+	for _, s := range held {
+		taken[s] = true
+	}
+
+	free := make([]string, 0, len(all))
+	for _, s := range all {
+		if !taken[s] {
+			free = append(free, s)
+		}
+	}
+	return free
+}
+
 func (r *ShowRepositoryDDB) ListByEvent(ctx context.Context, eventID, city, date, venueID, hostID string) ([]models.ShowDTO, error) {
 	if eventID == "" || city == "" {
 		return nil, errors.New("eventID and city are required")
@@ -266,7 +556,8 @@ func (r *ShowRepositoryDDB) ListByEvent(ctx context.Context, eventID, city, date
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch show details: %w", err)
 		}
-		if fullShow == nil {
+		// shows cancelled before their index item was removed on cancel
+		if fullShow == nil || fullShow.Status == models.ShowCancelled {
 			continue
 		}
 
@@ -278,7 +569,58 @@ func (r *ShowRepositoryDDB) ListByEvent(ctx context.Context, eventID, city, date
 	return shows, nil
 }
 
-func (r *ShowRepositoryDDB) getVenueDTO(ctx context.Context, VenueID string) (*models.VenueDTO, error) {
+// ListByVenue returns every show at the venue. Shows created before venue
+// schedules were kept have no item under the venue, so the table is scanned;
+// it is only used for rare changes such as editing a venue's layout.
+func (r *ShowRepositoryDDB) ListByVenue(ctx context.Context, venueID string) ([]models.ShowDTO, error) {
+	if venueID == "" {
+		return nil, errors.New("venueID is required")
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.TableName),
+		FilterExpression: aws.String("begins_with(pk, :pk) AND sk = :sk AND venue_id = :venue"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: "SHOW#"},
+			":sk":    &types.AttributeValueMemberS{Value: "DETAILS"},
+			":venue": &types.AttributeValueMemberS{Value: venueID},
+		},
+		ProjectionExpression: aws.String("pk"),
+	}
+
+	var shows []models.ShowDTO
+	for {
+		out, err := r.db.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shows: %w", err)
+		}
+		for _, item := range out.Items {
+			var row struct {
+				PK string `dynamodbav:"pk"`
+			}
+			if err := attributevalue.UnmarshalMap(item, &row); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal row: %w", err)
+			}
+			show, err := r.GetByID(ctx, strings.TrimPrefix(row.PK, "SHOW#"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch show details: %w", err)
+			}
+			if show != nil {
+				shows = append(shows, *show)
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	return shows, nil
+}
+
+// getVenueDTO also returns the venue's seat layout, falling back to the
+// default grid for venues without one.
+func (r *ShowRepositoryDDB) getVenueDTO(ctx context.Context, VenueID string) (*models.VenueDTO, models.SeatLayout, error) {
 	venuePK := "VENUE#" + VenueID
 	venueOut, err := r.db.Query(ctx, &dynamodb.QueryInput{
 		TableName:              aws.String(r.TableName),
@@ -290,18 +632,30 @@ func (r *ShowRepositoryDDB) getVenueDTO(ctx context.Context, VenueID string) (*m
 		Limit: aws.Int32(1),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch venue: %w", err)
+		return nil, models.SeatLayout{}, fmt.Errorf("failed to fetch venue: %w", err)
 	}
 
 	if len(venueOut.Items) == 0 {
-		return nil, fmt.Errorf("venue not found: %s", VenueID)
+		return nil, models.SeatLayout{}, fmt.Errorf("venue not found: %s", VenueID)
 	}
 	var venueDDB models.VenueDTO
 	if err := attributevalue.UnmarshalMap(venueOut.Items[0], &venueDDB); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal venue: %w", err)
+		return nil, models.SeatLayout{}, fmt.Errorf("failed to unmarshal venue: %w", err)
 	}
 	venueDDB.ID = VenueID
-	return &venueDDB, nil
+
+	var layoutRec struct {
+		SeatLayout *models.SeatLayout `dynamodbav:"seat_layout"`
+	}
+	if err := attributevalue.UnmarshalMap(venueOut.Items[0], &layoutRec); err != nil {
+		return nil, models.SeatLayout{}, fmt.Errorf("failed to unmarshal seat layout: %w", err)
+	}
+	layout := models.DefaultSeatLayout()
+	if layoutRec.SeatLayout != nil {
+		layout = *layoutRec.SeatLayout
+	}
+
+	return &venueDDB, layout, nil
 }
 
 func (r *ShowRepositoryDDB) Update(ctx context.Context, showID string, isBlocked bool) error {
@@ -329,39 +683,216 @@ func (r *ShowRepositoryDDB) Update(ctx context.Context, showID string, isBlocked
 	return nil
 }
 
-func (br *ShowRepositoryDDB) UpdateShowBooking(ctx context.Context, booking models.Booking) error {
-	updateInput := &dynamodb.UpdateItemInput{
-		TableName: aws.String(br.TableName),
-		Key: map[string]types.AttributeValue{
-			"pk": &types.AttributeValueMemberS{Value: "SHOW#" + booking.ShowID},
-			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
-		},
-		UpdateExpression: aws.String(
-			"SET booked_seats = list_append(if_not_exists(booked_seats, :empty), :newSeats)",
-		),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":newSeats": &types.AttributeValueMemberL{
-				Value: toAVList(booking.Seats),
+func showKey(showID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+		"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+	}
+}
+
+// eventDateKey is the EVENT#<event>#CITY#<city> index item written for the
+// show in Create.
+func eventDateKey(show *models.ShowDTO, showDateTime string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "EVENT#" + show.EventID + "#CITY#" + show.Venue.City},
+		"sk": &types.AttributeValueMemberS{Value: "DATE#" + showDateTime + "#VENUE#" + show.Venue.ID + "#SHOW#" + show.ID},
+	}
+}
+
+// Cancel marks the show cancelled and blocked, removes its event/city index
+// item so it drops out of listings, and frees its venue slot.
+func (r *ShowRepositoryDDB) Cancel(ctx context.Context, show *models.ShowDTO, reason, cancelledBy string) error {
+	cancelledAt := time.Now().Format(time.RFC3339)
+
+	statsUpdate, err := reportrepository.ShowDetailsUpdate(r.TableName, show.HostID, show.ID, map[string]any{
+		"status": models.ShowCancelled,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.TableName),
+					Key:                 showKey(show.ID),
+					UpdateExpression:    aws.String("SET #status = :cancelled, is_blocked = :true, cancel_reason = :reason, cancelled_at = :at, cancelled_by = :by"),
+					ConditionExpression: aws.String("attribute_exists(pk) AND (attribute_not_exists(#status) OR #status <> :cancelled)"),
+					ExpressionAttributeNames: map[string]string{
+						"#status": "status",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":cancelled": &types.AttributeValueMemberS{Value: models.ShowCancelled},
+						":true":      &types.AttributeValueMemberBOOL{Value: true},
+						":reason":    &types.AttributeValueMemberS{Value: reason},
+						":at":        &types.AttributeValueMemberS{Value: cancelledAt},
+						":by":        &types.AttributeValueMemberS{Value: cancelledBy},
+					},
+				},
+			},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(r.TableName),
+					Key:       venueSlotKey(show.Venue.ID, show.ShowDateTime(), show.ID),
+				},
 			},
-			":empty": &types.AttributeValueMemberL{
-				Value: []types.AttributeValue{},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(r.TableName),
+					Key:       eventDateKey(show, show.ShowDateTime()),
+				},
 			},
+			{Update: statsUpdate},
 		},
-		ReturnValues: types.ReturnValueNone,
+	})
+	if err != nil {
+		var tce *types.TransactionCanceledException
+		if errors.As(err, &tce) && len(tce.CancellationReasons) > 0 &&
+			aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return errors.New("show is already cancelled")
+		}
+		return fmt.Errorf("failed to cancel show: %w", err)
 	}
 
-	_, err := br.db.UpdateItem(ctx, updateInput)
+	show.Status = models.ShowCancelled
+	show.CancelReason = reason
+	show.CancelledAt = cancelledAt
+	return nil
+}
+
+// Reschedule moves the show to newShowDateTime. The event/city index item and
+// the venue slot are keyed on the date, so they are re-created under the new
+// key in the same transaction, provided the venue schedule is still at
+// scheduleVersion.
+func (r *ShowRepositoryDDB) Reschedule(ctx context.Context, show *models.ShowDTO, newShowDateTime string, endsAt time.Time, scheduleVersion int64) error {
+	t, err := time.ParseInLocation("2006-01-02T15:04", newShowDateTime, time.UTC)
 	if err != nil {
-		return fmt.Errorf("failed to update show booked seats: %w", err)
+		return fmt.Errorf("error parsing time: %v", err)
 	}
-	return nil
+	expiresAt := &types.AttributeValueMemberN{Value: fmt.Sprint(t.Unix())}
 
-}
+	oldShowDateTime := show.ShowDateTime()
+	oldIndexKey := eventDateKey(show, oldShowDateTime)
+
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       oldIndexKey,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch show index: %w", err)
+	}
+	indexItem := out.Item
+	if indexItem == nil {
+		indexItem, _ = attributevalue.MarshalMap(map[string]any{
+			"is_blocked": show.IsBlocked,
+			"price":      show.Price,
+		})
+	}
+	for k, v := range eventDateKey(show, newShowDateTime) {
+		indexItem[k] = v
+	}
+	indexItem["expires_at"] = expiresAt
 
-func toAVList(strs []string) []types.AttributeValue {
-	avs := make([]types.AttributeValue, 0, len(strs))
-	for _, s := range strs {
-		avs = append(avs, &types.AttributeValueMemberS{Value: s})
+	venueSlot, err := venueSlotItem(show.Venue.ID, show.ID, t, endsAt)
+	if err != nil {
+		return err
 	}
-	return avs
+
+	statsUpdate, err := reportrepository.ShowDetailsUpdate(r.TableName, show.HostID, show.ID, map[string]any{
+		"show_date_time": newShowDateTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Update: &types.Update{
+					TableName:           aws.String(r.TableName),
+					Key:                 showKey(show.ID),
+					UpdateExpression:    aws.String("SET show_date_time = :new, expires_at = :exp"),
+					ConditionExpression: aws.String("show_date_time = :old AND (attribute_not_exists(#status) OR #status <> :cancelled)"),
+					ExpressionAttributeNames: map[string]string{
+						"#status": "status",
+					},
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":new":       &types.AttributeValueMemberS{Value: newShowDateTime},
+						":old":       &types.AttributeValueMemberS{Value: oldShowDateTime},
+						":exp":       expiresAt,
+						":cancelled": &types.AttributeValueMemberS{Value: models.ShowCancelled},
+					},
+				},
+			},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(r.TableName),
+					Key:       oldIndexKey,
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(r.TableName),
+					Item:      indexItem,
+				},
+			},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(r.TableName),
+					Key:       venueSlotKey(show.Venue.ID, oldShowDateTime, show.ID),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(r.TableName),
+					Item:      venueSlot,
+				},
+			},
+			{Update: r.scheduleVersionUpdate(show.Venue.ID, scheduleVersion)},
+			{Update: statsUpdate},
+		},
+	})
+	if err != nil {
+		if scheduleChanged(err, 5) {
+			return models.ErrScheduleChanged
+		}
+		var tce *types.TransactionCanceledException
+		if errors.As(err, &tce) && len(tce.CancellationReasons) > 0 &&
+			aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return errors.New("show was cancelled or changed while rescheduling")
+		}
+		return fmt.Errorf("failed to reschedule show: %w", err)
+	}
+
+	// the host and city event items are shared by all of the event's shows;
+	// only push their expiry out so a later show keeps the event listed
+	for _, key := range []map[string]types.AttributeValue{
+		{
+			"pk": &types.AttributeValueMemberS{Value: "HOST#" + show.HostID},
+			"sk": &types.AttributeValueMemberS{Value: "EVENT#" + show.EventID},
+		},
+		{
+			"pk": &types.AttributeValueMemberS{Value: "CITY#" + show.Venue.City},
+			"sk": &types.AttributeValueMemberS{Value: "EVENT#" + show.EventID},
+		},
+	} {
+		_, err := r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(r.TableName),
+			Key:                       key,
+			UpdateExpression:          aws.String("SET expires_at = :exp"),
+			ConditionExpression:       aws.String("attribute_exists(pk) AND expires_at < :exp"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{":exp": expiresAt},
+		})
+		if err != nil {
+			var cce *types.ConditionalCheckFailedException
+			if !errors.As(err, &cce) {
+				return fmt.Errorf("failed to extend event listing: %w", err)
+			}
+		}
+	}
+
+	show.ShowDate = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	show.ShowTime = t.Format("15:04")
+	return nil
 }
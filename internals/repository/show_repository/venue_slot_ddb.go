@@ -0,0 +1,173 @@
+package showrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Shows created before venues kept a schedule have no slot under their venue,
+// so new shows are not checked against them. BackfillVenueSlots writes the
+// missing slots of shows still to come and then a marker.
+
+func venueSlotMarkerKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "MIGRATION#VENUE_SLOTS"},
+		"sk": &types.AttributeValueMemberS{Value: "DONE"},
+	}
+}
+
+// BackfillVenueSlots adds the venue slot of every upcoming show that lacks
+// one and returns how many were written. Once it has finished it does
+// nothing, so it is safe to run on a schedule.
+func (r *ShowRepositoryDDB) BackfillVenueSlots(ctx context.Context, now time.Time) (int, error) {
+	marker, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key:       venueSlotMarkerKey(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check venue slots: %w", err)
+	}
+	if marker.Item != nil {
+		return 0, nil
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.TableName),
+		FilterExpression: aws.String("begins_with(pk, :show) AND sk = :details AND show_date_time >= :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":show":    &types.AttributeValueMemberS{Value: "SHOW#"},
+			":details": &types.AttributeValueMemberS{Value: "DETAILS"},
+			":now":     &types.AttributeValueMemberS{Value: now.UTC().Format("2006-01-02T15:04")},
+		},
+	}
+
+	durations := map[string]time.Duration{}
+	written := 0
+	for {
+		out, err := r.db.Scan(ctx, input)
+		if err != nil {
+			return written, fmt.Errorf("failed to scan shows: %w", err)
+		}
+		var page []ShowDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return written, fmt.Errorf("failed to unmarshal shows: %w", err)
+		}
+		for _, show := range page {
+			if show.Status == models.ShowCancelled || show.VenueID == "" {
+				continue
+			}
+			showID := show.PK[len("SHOW#"):]
+			startsAt, err := time.ParseInLocation("2006-01-02T15:04", show.ShowDateTime, time.UTC)
+			if err != nil {
+				return written, fmt.Errorf("invalid time for show %s: %w", showID, err)
+			}
+
+			duration, ok := durations[show.EventID]
+			if !ok {
+				if duration, err = r.eventDuration(ctx, show.EventID); err != nil {
+					return written, err
+				}
+				durations[show.EventID] = duration
+			}
+
+			added, err := r.addVenueSlot(ctx, show.VenueID, showID, startsAt, startsAt.Add(duration))
+			if err != nil {
+				return written, err
+			}
+			if added {
+				written++
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.TableName),
+		Item:      venueSlotMarkerKey(),
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to mark venue slots complete: %w", err)
+	}
+	return written, nil
+}
+
+// eventDuration reads how long the event's shows run, falling back to the
+// default for events whose duration cannot be parsed.
+func (r *ShowRepositoryDDB) eventDuration(ctx context.Context, eventID string) (time.Duration, error) {
+	out, err := r.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(r.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "EVENT#" + eventID},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		ProjectionExpression: aws.String("#duration"),
+		ExpressionAttributeNames: map[string]string{
+			"#duration": "duration",
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get event %s: %w", eventID, err)
+	}
+	var event struct {
+		Duration string `dynamodbav:"duration"`
+	}
+	if err := attributevalue.UnmarshalMap(out.Item, &event); err != nil {
+		return 0, err
+	}
+	duration, err := models.ParseEventDuration(event.Duration)
+	if err != nil {
+		return models.DefaultEventDuration, nil
+	}
+	return duration, nil
+}
+
+// addVenueSlot writes the slot unless it already exists, bumping the venue's
+// schedule version so a host checking the schedule at the same time retries.
+func (r *ShowRepositoryDDB) addVenueSlot(ctx context.Context, venueID, showID string, startsAt, endsAt time.Time) (bool, error) {
+	slot, err := venueSlotItem(venueID, showID, startsAt, endsAt)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(r.TableName),
+					Item:                slot,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+			{
+				Update: &types.Update{
+					TableName: aws.String(r.TableName),
+					Key: map[string]types.AttributeValue{
+						"pk": &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+						"sk": &types.AttributeValueMemberS{Value: "SCHEDULE"},
+					},
+					UpdateExpression: aws.String("ADD version :one"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{
+						":one": &types.AttributeValueMemberN{Value: "1"},
+					},
+				},
+			},
+		},
+	})
+	if scheduleChanged(err, 0) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to add slot for show %s: %w", showID, err)
+	}
+	return true, nil
+}
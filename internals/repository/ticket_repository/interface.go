@@ -0,0 +1,14 @@
+package ticketrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/ticket_repository_mock.go -package=mocks -source=interface.go
+type TicketRepositoryI interface {
+	Admit(ctx context.Context, admission *models.Admission, expiresAt time.Time) error
+	ListAdmissions(ctx context.Context, showID string) ([]models.Admission, error)
+	RecordDuplicate(ctx context.Context, duplicate *models.DuplicateAdmission, expiresAt time.Time) error
+}
@@ -0,0 +1,134 @@
+package ticketrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type TicketRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewTicketRepositoryDDB(db *dynamodb.Client, tableName string) *TicketRepositoryDDB {
+	return &TicketRepositoryDDB{db: db, TableName: tableName}
+}
+
+// AdmissionDDB is the ADMIT#<seat> item under the show's partition. There is
+// one per seat, so a seat can only be admitted once whichever ticket
+// version is scanned.
+type AdmissionDDB struct {
+	PK string `dynamodbav:"pk"`
+	SK string `dynamodbav:"sk"`
+	models.Admission
+	ExpiresAt int64 `dynamodbav:"expires_at"`
+}
+
+// Admit records the admission unless the seat was already admitted, in which
+// case the first admission is returned in an AlreadyAdmittedError.
+func (r *TicketRepositoryDDB) Admit(ctx context.Context, admission *models.Admission, expiresAt time.Time) error {
+	item, err := attributevalue.MarshalMap(AdmissionDDB{
+		PK:        "SHOW#" + admission.ShowID,
+		SK:        "ADMIT#" + admission.Seat,
+		Admission: *admission,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal admission: %w", err)
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                           aws.String(r.TableName),
+		Item:                                item,
+		ConditionExpression:                 aws.String("attribute_not_exists(pk)"),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	if err == nil {
+		return nil
+	}
+
+	var cce *types.ConditionalCheckFailedException
+	if !errors.As(err, &cce) {
+		return fmt.Errorf("failed to record admission: %w", err)
+	}
+	var existing AdmissionDDB
+	if err := attributevalue.UnmarshalMap(cce.Item, &existing); err != nil {
+		return fmt.Errorf("failed to unmarshal admission: %w", err)
+	}
+	return &models.AlreadyAdmittedError{Admission: existing.Admission}
+}
+
+// ListAdmissions returns every seat admitted to the show so far.
+func (r *TicketRepositoryDDB) ListAdmissions(ctx context.Context, showID string) ([]models.Admission, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			":prefix": &types.AttributeValueMemberS{Value: "ADMIT#"},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var admissions []models.Admission
+	for {
+		out, err := r.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query admissions: %w", err)
+		}
+		var rows []AdmissionDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal admissions: %w", err)
+		}
+		for _, row := range rows {
+			admissions = append(admissions, row.Admission)
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return admissions, nil
+}
+
+// DuplicateAdmissionDDB keeps a flagged second scan under the show as
+// ADMIT_DUP#<seat>#<scan time>#<device>, so re-syncing the same log does not
+// add it twice.
+type DuplicateAdmissionDDB struct {
+	PK        string           `dynamodbav:"pk"`
+	SK        string           `dynamodbav:"sk"`
+	Scan      models.Admission `dynamodbav:"scan"`
+	First     models.Admission `dynamodbav:"first"`
+	ExpiresAt int64            `dynamodbav:"expires_at"`
+}
+
+func (r *TicketRepositoryDDB) RecordDuplicate(ctx context.Context, duplicate *models.DuplicateAdmission, expiresAt time.Time) error {
+	scan := duplicate.Scan
+	item, err := attributevalue.MarshalMap(DuplicateAdmissionDDB{
+		PK:        "SHOW#" + scan.ShowID,
+		SK:        "ADMIT_DUP#" + scan.Seat + "#" + scan.AdmittedAt + "#" + scan.Device,
+		Scan:      scan,
+		First:     duplicate.First,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal duplicate admission: %w", err)
+	}
+
+	_, err = r.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(r.TableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record duplicate admission: %w", err)
+	}
+	return nil
+}
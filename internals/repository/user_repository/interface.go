@@ -1,9 +1,22 @@
 package userrepository
 
-import "eventro_aws/internals/models"
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
 
 //go:generate mockgen -destination=../../mocks/user_repository_mock.go -package=mocks -source=interface.go
 type UserRepositoryI interface {
 	Create(user *models.User) error
 	GetByEmail(email string) (*models.User, error)
+	List(ctx context.Context, query string, role models.Role, limit int32, cursor string) (*models.UserPage, error)
+	BackfillIndex(ctx context.Context) (int, error)
+	Update(ctx context.Context, email string, req models.UpdateUserRequest) (*models.User, error)
+	SetPassword(ctx context.Context, email, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, email string) error
+	SetPendingTOTP(ctx context.Context, email, secret string) error
+	EnableTOTP(ctx context.Context, email, secret string, step int64, recoveryHashes []string) error
+	UseTOTPStep(ctx context.Context, email string, step int64) error
+	UseRecoveryCode(ctx context.Context, email, codeHash string) error
+	DisableTOTP(ctx context.Context, email string) error
 }
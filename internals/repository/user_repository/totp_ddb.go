@@ -0,0 +1,87 @@
+package userrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// updateTOTP applies a two-factor change to the user if condition holds.
+func (ur UserRepositoryDDB) updateTOTP(ctx context.Context, email, update, condition string, values map[string]types.AttributeValue) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(ur.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USER#" + email},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: values,
+	}
+	if condition != "" {
+		input.ConditionExpression = aws.String("attribute_exists(pk) AND " + condition)
+	}
+
+	if _, err := ur.db.UpdateItem(ctx, input); err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return models.ErrTwoFactorConflict
+		}
+		return fmt.Errorf("failed to update two-factor settings: %w", err)
+	}
+	return nil
+}
+
+// SetPendingTOTP stores a secret that is waiting to be confirmed with a code.
+func (ur UserRepositoryDDB) SetPendingTOTP(ctx context.Context, email, secret string) error {
+	return ur.updateTOTP(ctx, email, "SET totp_pending_secret = :secret", "", map[string]types.AttributeValue{
+		":secret": &types.AttributeValueMemberS{Value: secret},
+	})
+}
+
+// EnableTOTP turns 2FA on with the pending secret, whose code was confirmed
+// at step, and replaces the recovery codes.
+func (ur UserRepositoryDDB) EnableTOTP(ctx context.Context, email, secret string, step int64, recoveryHashes []string) error {
+	return ur.updateTOTP(ctx, email,
+		"SET totp_enabled = :true, totp_secret = :secret, totp_last_step = :step, recovery_codes = :codes REMOVE totp_pending_secret",
+		"totp_pending_secret = :secret",
+		map[string]types.AttributeValue{
+			":true":   &types.AttributeValueMemberBOOL{Value: true},
+			":secret": &types.AttributeValueMemberS{Value: secret},
+			":step":   &types.AttributeValueMemberN{Value: strconv.FormatInt(step, 10)},
+			":codes":  &types.AttributeValueMemberSS{Value: recoveryHashes},
+		})
+}
+
+// UseTOTPStep records that the code for step was used. It fails if a code
+// for that step or a later one was already accepted.
+func (ur UserRepositoryDDB) UseTOTPStep(ctx context.Context, email string, step int64) error {
+	return ur.updateTOTP(ctx, email, "SET totp_last_step = :step",
+		"(attribute_not_exists(totp_last_step) OR totp_last_step < :step)",
+		map[string]types.AttributeValue{
+			":step": &types.AttributeValueMemberN{Value: strconv.FormatInt(step, 10)},
+		})
+}
+
+// UseRecoveryCode removes a recovery code, failing if it was already used.
+func (ur UserRepositoryDDB) UseRecoveryCode(ctx context.Context, email, codeHash string) error {
+	return ur.updateTOTP(ctx, email, "DELETE recovery_codes :codes", "contains(recovery_codes, :code)",
+		map[string]types.AttributeValue{
+			":codes": &types.AttributeValueMemberSS{Value: []string{codeHash}},
+			":code":  &types.AttributeValueMemberS{Value: codeHash},
+		})
+}
+
+func (ur UserRepositoryDDB) DisableTOTP(ctx context.Context, email string) error {
+	return ur.updateTOTP(ctx, email,
+		"SET totp_enabled = :false REMOVE totp_secret, totp_pending_secret, totp_last_step, recovery_codes", "",
+		map[string]types.AttributeValue{
+			":false": &types.AttributeValueMemberBOOL{Value: false},
+		})
+}
@@ -2,8 +2,10 @@ package userrepository
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"eventro_aws/internals/models"
+	"fmt"
 	"log"
 	"strings"
 
@@ -13,6 +15,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// ErrUserNotFound is returned when no account has the given email.
+var ErrUserNotFound = errors.New("no user found")
+
 type UserDDB struct {
 	UserID      string      `dynamodbav:"user_id"`
 	Username    string      `dynamodbav:"username"`
@@ -34,6 +39,32 @@ func NewUserRepoDDB(db *dynamodb.Client, tableName string) *UserRepositoryDDB {
 	return &UserRepositoryDDB{db: db, TableName: tableName}
 }
 
+// Every user also has a USER#<email> item under the USERS partition holding
+// the fields admins list and search on, so users can be paged through
+// without scanning the table.
+
+type UserIndexDDB struct {
+	PK     string `dynamodbav:"pk"`
+	SK     string `dynamodbav:"sk"`
+	Search string `dynamodbav:"search"`
+	models.UserSummary
+}
+
+func userIndexItem(user *models.User) (map[string]types.AttributeValue, error) {
+	return attributevalue.MarshalMap(UserIndexDDB{
+		PK:     "USERS",
+		SK:     "USER#" + user.Email,
+		Search: strings.ToLower(user.Username + " " + user.Email),
+		UserSummary: models.UserSummary{
+			Email:       user.Email,
+			Username:    user.Username,
+			PhoneNumber: user.PhoneNumber,
+			Role:        user.Role,
+			IsBlocked:   user.IsBlocked,
+		},
+	})
+}
+
 func (ur UserRepositoryDDB) Create(user *models.User) error {
 
 	item, err := attributevalue.MarshalMap(user)
@@ -51,12 +82,36 @@ func (ur UserRepositoryDDB) Create(user *models.User) error {
 		return err
 	}
 
+	indexItem, err := userIndexItem(user)
+	if err != nil {
+		return err
+	}
+
 	ctx := context.Background()
-	_, err = ur.db.PutItem(ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(ur.TableName), Item: item,
+	_, err = ur.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(ur.TableName),
+					Item:                item,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(ur.TableName),
+					Item:      indexItem,
+				},
+			},
+		},
 	})
 	if err != nil {
 		log.Printf("could not add item to table. err: %v", err)
+		var tce *types.TransactionCanceledException
+		if errors.As(err, &tce) && len(tce.CancellationReasons) > 0 &&
+			aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return errors.New("an account with this email already exists")
+		}
 	}
 	return err
 }
@@ -76,13 +131,12 @@ func (ur UserRepositoryDDB) GetByEmail(email string) (*models.User, error) {
 	response, err := ur.db.GetItem(ctx, &dynamodb.GetItemInput{
 		Key: keyMap, TableName: aws.String(ur.TableName),
 	})
-	if len(response.Item) == 0 {
-		return nil, errors.New("no user found")
-	}
-
 	if err != nil {
 		log.Printf("Couldn't get info. Here's why: %v\n", err)
 		return nil, err
+	}
+	if len(response.Item) == 0 {
+		return nil, ErrUserNotFound
 	} else {
 		err = attributevalue.UnmarshalMap(response.Item, &user)
 		if err != nil {
@@ -94,3 +148,224 @@ func (ur UserRepositoryDDB) GetByEmail(email string) (*models.User, error) {
 
 	return &user, nil
 }
+
+// List pages through users in email order. query matches part of the
+// username or email and role limits the page to one role; cursor is the
+// NextCursor of the previous page.
+func (ur UserRepositoryDDB) List(ctx context.Context, query string, role models.Role, limit int32, cursor string) (*models.UserPage, error) {
+	if complete, err := ur.indexComplete(ctx); err != nil {
+		return nil, err
+	} else if !complete {
+		return ur.listScanned(ctx, query, role, limit, cursor)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ur.TableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: "USERS"},
+		},
+		Limit: aws.Int32(limit),
+	}
+	var filters []string
+	if query != "" {
+		filters = append(filters, "contains(search, :q)")
+		input.ExpressionAttributeValues[":q"] = &types.AttributeValueMemberS{Value: strings.ToLower(query)}
+	}
+	if role != "" {
+		filters = append(filters, "#role = :role")
+		input.ExpressionAttributeNames = map[string]string{"#role": "role"}
+		input.ExpressionAttributeValues[":role"] = &types.AttributeValueMemberS{Value: string(role)}
+	}
+	if len(filters) > 0 {
+		input.FilterExpression = aws.String(strings.Join(filters, " AND "))
+	}
+	if cursor != "" {
+		email, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USERS"},
+			"sk": &types.AttributeValueMemberS{Value: "USER#" + string(email)},
+		}
+	}
+
+	page := &models.UserPage{Users: []models.UserSummary{}}
+	for {
+		out, err := ur.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for _, item := range out.Items {
+			var u UserIndexDDB
+			if err := attributevalue.UnmarshalMap(item, &u); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+			}
+			page.Users = append(page.Users, u.UserSummary)
+			if int32(len(page.Users)) == limit {
+				// the filter can leave more matches in this page, so carry on
+				// from the last user returned rather than from the page's end
+				page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(u.Email))
+				return page, nil
+			}
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return page, nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// Update applies the admin's changes to the user. Any change of role or
+// block status bumps token_version so tokens issued before stop working.
+func (ur UserRepositoryDDB) Update(ctx context.Context, email string, req models.UpdateUserRequest) (*models.User, error) {
+	sets := []string{}
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{
+		":one": &types.AttributeValueMemberN{Value: "1"},
+	}
+	if req.IsBlocked != nil {
+		sets = append(sets, "is_blocked = :blocked")
+		values[":blocked"] = &types.AttributeValueMemberBOOL{Value: *req.IsBlocked}
+	}
+	if req.Role != nil {
+		sets = append(sets, "#role = :role")
+		names["#role"] = "role"
+		values[":role"] = &types.AttributeValueMemberS{Value: *req.Role}
+	}
+	if len(sets) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(ur.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USER#" + email},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		UpdateExpression:          aws.String("SET " + strings.Join(sets, ", ") + " ADD token_version :one"),
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	}
+	if len(names) > 0 {
+		input.ExpressionAttributeNames = names
+	}
+	out, err := ur.db.UpdateItem(ctx, input)
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	var user models.User
+	if err := attributevalue.UnmarshalMap(out.Attributes, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	user.Email = strings.TrimPrefix(user.Email, "USER#")
+
+	indexItem, err := userIndexItem(&user)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ur.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(ur.TableName),
+		Item:      indexItem,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update user index: %w", err)
+	}
+	return &user, nil
+}
+
+// RoleChangeItems moves user to role as part of another transaction: it
+// updates the user, bumping token_version like Update does, and rewrites
+// their index item. The update fails if the user's role is no longer the
+// one in user.
+func RoleChangeItems(tableName string, user *models.User, role models.Role) ([]types.TransactWriteItem, error) {
+	changed := *user
+	changed.Role = role
+	indexItem, err := userIndexItem(&changed)
+	if err != nil {
+		return nil, err
+	}
+
+	return []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName: aws.String(tableName),
+				Key: map[string]types.AttributeValue{
+					"pk": &types.AttributeValueMemberS{Value: "USER#" + user.Email},
+					"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+				},
+				UpdateExpression:         aws.String("SET #role = :role ADD token_version :one"),
+				ConditionExpression:      aws.String("#role = :current"),
+				ExpressionAttributeNames: map[string]string{"#role": "role"},
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":role":    &types.AttributeValueMemberS{Value: string(role)},
+					":current": &types.AttributeValueMemberS{Value: string(user.Role)},
+					":one":     &types.AttributeValueMemberN{Value: "1"},
+				},
+			},
+		},
+		{
+			Put: &types.Put{
+				TableName: aws.String(tableName),
+				Item:      indexItem,
+			},
+		},
+	}, nil
+}
+
+// SetPassword replaces the user's password hash and bumps token_version, so
+// every existing session has to log in again.
+func (ur UserRepositoryDDB) SetPassword(ctx context.Context, email, passwordHash string) error {
+	_, err := ur.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(ur.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USER#" + email},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		UpdateExpression:    aws.String("SET password = :password ADD token_version :one"),
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":password": &types.AttributeValueMemberS{Value: passwordHash},
+			":one":      &types.AttributeValueMemberN{Value: "1"},
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+func (ur UserRepositoryDDB) MarkEmailVerified(ctx context.Context, email string) error {
+	_, err := ur.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(ur.TableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "USER#" + email},
+			"sk": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+		UpdateExpression:    aws.String("SET email_verified = :true"),
+		ConditionExpression: aws.String("attribute_exists(pk)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":true": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package userrepository
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Users who signed up before the USERS index existed have no item in it.
+// BackfillIndex writes the missing ones and then a marker; until the marker
+// exists, List scans the users' own items instead of reading the index.
+
+func userIndexMarkerKey() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "MIGRATION#USER_INDEX"},
+		"sk": &types.AttributeValueMemberS{Value: "DONE"},
+	}
+}
+
+func (ur UserRepositoryDDB) indexComplete(ctx context.Context) (bool, error) {
+	out, err := ur.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(ur.TableName),
+		Key:       userIndexMarkerKey(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check user index: %w", err)
+	}
+	return out.Item != nil, nil
+}
+
+// scanUsers calls fn with every user's own item.
+func (ur UserRepositoryDDB) scanUsers(ctx context.Context, fn func(user *models.User) error) error {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(ur.TableName),
+		FilterExpression: aws.String("begins_with(pk, :user) AND sk = :details"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user":    &types.AttributeValueMemberS{Value: "USER#"},
+			":details": &types.AttributeValueMemberS{Value: "DETAILS"},
+		},
+	}
+	for {
+		out, err := ur.db.Scan(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to scan users: %w", err)
+		}
+		var users []models.User
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &users); err != nil {
+			return fmt.Errorf("failed to unmarshal users: %w", err)
+		}
+		for i := range users {
+			users[i].Email = strings.TrimPrefix(users[i].Email, "USER#")
+			if err := fn(&users[i]); err != nil {
+				return err
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// listScanned is List for a table whose index is not backfilled yet. It reads
+// every user, so it is only meant to bridge the time until the backfill runs.
+func (ur UserRepositoryDDB) listScanned(ctx context.Context, query string, role models.Role, limit int32, cursor string) (*models.UserPage, error) {
+	var after string
+	if cursor != "" {
+		email, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		after = string(email)
+	}
+	query = strings.ToLower(query)
+
+	var matches []models.UserSummary
+	err := ur.scanUsers(ctx, func(user *models.User) error {
+		if user.Email <= after && after != "" {
+			return nil
+		}
+		if query != "" && !strings.Contains(strings.ToLower(user.Username+" "+user.Email), query) {
+			return nil
+		}
+		if role != "" && user.Role != role {
+			return nil
+		}
+		matches = append(matches, models.UserSummary{
+			Email:       user.Email,
+			Username:    user.Username,
+			PhoneNumber: user.PhoneNumber,
+			Role:        user.Role,
+			IsBlocked:   user.IsBlocked,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Email < matches[j].Email })
+
+	page := &models.UserPage{Users: []models.UserSummary{}}
+	if int32(len(matches)) > limit {
+		matches = matches[:limit]
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(matches[limit-1].Email))
+	}
+	page.Users = append(page.Users, matches...)
+	return page, nil
+}
+
+// BackfillIndex adds the USERS index item of every user who lacks one and
+// returns how many were written. Once it has finished it does nothing, so it
+// is safe to run on a schedule.
+func (ur UserRepositoryDDB) BackfillIndex(ctx context.Context) (int, error) {
+	if complete, err := ur.indexComplete(ctx); err != nil || complete {
+		return 0, err
+	}
+
+	written := 0
+	err := ur.scanUsers(ctx, func(user *models.User) error {
+		item, err := userIndexItem(user)
+		if err != nil {
+			return err
+		}
+		// an existing item is newer, e.g. written by an admin's change
+		_, err = ur.db.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(ur.TableName),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(pk)"),
+		})
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to index user %s: %w", user.Email, err)
+		}
+		written++
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+
+	_, err = ur.db.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(ur.TableName),
+		Item:      userIndexMarkerKey(),
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to mark user index complete: %w", err)
+	}
+	return written, nil
+}
@@ -11,5 +11,6 @@ type VenueRepositoryI interface {
 	GetByID(ctx context.Context, id string) (*models.VenueResponse, error)
 	ListByHost(ctx context.Context, hostID string) ([]models.VenueResponse, error)
 	Update(ctx context.Context, venueID string, isBlocked bool) error
+	UpdateSeatLayout(ctx context.Context, venueID, hostID string, layout *models.SeatLayout) error
 	Delete(ctx context.Context, id string) error
 }
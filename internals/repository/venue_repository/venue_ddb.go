@@ -34,6 +34,9 @@ func (r *VenueRepositoryDDB) Create(ctx context.Context, venue *models.Venue) er
 		"venue_city":  venue.City,
 		"venue_state": venue.State,
 	}
+	if venue.SeatLayout != nil {
+		venueItem["seat_layout"] = venue.SeatLayout
+	}
 
 	itemAV, err := attributevalue.MarshalMap(venueItem)
 	if err != nil {
@@ -215,6 +218,32 @@ func (r *VenueRepositoryDDB) Update(ctx context.Context, venueID string, isBlock
 	return nil
 }
 
+func (r *VenueRepositoryDDB) UpdateSeatLayout(ctx context.Context, venueID, hostID string, layout *models.SeatLayout) error {
+	layoutAV, err := attributevalue.Marshal(layout)
+	if err != nil {
+		return fmt.Errorf("marshal seat layout: %w", err)
+	}
+
+	_, err = r.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.tableName),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "VENUE#" + venueID},
+			"sk": &types.AttributeValueMemberS{Value: "HOST#" + hostID},
+		},
+		UpdateExpression:          aws.String("SET seat_layout = :l"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":l": layoutAV},
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return fmt.Errorf("venue not found")
+		}
+		return fmt.Errorf("update seat layout failed: %w", err)
+	}
+	return nil
+}
+
 func (r *VenueRepositoryDDB) Delete(ctx context.Context, id string) error {
 	pk := "VENUE#" + id
 
@@ -0,0 +1,18 @@
+package waitlistrepository
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+	"time"
+)
+
+//go:generate mockgen -destination=../../mocks/waitlist_repository_mock.go -package=mocks -source=interface.go
+type WaitlistRepositoryI interface {
+	Join(ctx context.Context, entry *models.WaitlistEntry, expiresAt time.Time) error
+	Get(ctx context.Context, showID, userID string) (*models.WaitlistEntry, error)
+	ListByShow(ctx context.Context, showID string) ([]models.WaitlistEntry, error)
+	ListByUser(ctx context.Context, userID string) ([]models.WaitlistEntry, error)
+	MarkOffered(ctx context.Context, entry *models.WaitlistEntry, hold *models.SeatHold) error
+	Remove(ctx context.Context, entry *models.WaitlistEntry) error
+	ListWaitlistedShows(ctx context.Context) ([]string, error)
+}
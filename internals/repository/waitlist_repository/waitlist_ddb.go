@@ -0,0 +1,364 @@
+package waitlistrepository
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Each entry is a WAITLIST#<joined_at>#<user> item under the show's partition,
+// so a query returns the queue in the order people joined. A WAITLIST#<show>
+// pointer under the user's partition keeps one entry per user and show, and
+// a SHOW#<show> item under the WAITLIST partition lets the sweeper find shows
+// with a queue. All three expire when the show starts.
+
+// joinedAtLayout is fixed width so entry keys sort by time.
+const joinedAtLayout = "2006-01-02T15:04:05.000000000Z"
+
+type WaitlistRepositoryDDB struct {
+	db        *dynamodb.Client
+	TableName string
+}
+
+func NewWaitlistRepositoryDDB(db *dynamodb.Client, tableName string) *WaitlistRepositoryDDB {
+	return &WaitlistRepositoryDDB{db: db, TableName: tableName}
+}
+
+type WaitlistEntryDDB struct {
+	PK             string   `dynamodbav:"pk"`
+	SK             string   `dynamodbav:"sk"`
+	ShowID         string   `dynamodbav:"show_id"`
+	UserID         string   `dynamodbav:"user_id"`
+	Seats          int      `dynamodbav:"seats"`
+	JoinedAt       string   `dynamodbav:"joined_at"`
+	Status         string   `dynamodbav:"status"`
+	OfferHoldID    string   `dynamodbav:"offer_hold_id,omitempty"`
+	OfferedSeats   []string `dynamodbav:"offered_seats,omitempty"`
+	OfferExpiresAt int64    `dynamodbav:"offer_expires_at,omitempty"`
+	ExpiresAt      int64    `dynamodbav:"expires_at"`
+}
+
+type WaitlistPointerDDB struct {
+	PK        string `dynamodbav:"pk"`
+	SK        string `dynamodbav:"sk"`
+	ShowID    string `dynamodbav:"show_id"`
+	EntrySK   string `dynamodbav:"entry_sk"`
+	ExpiresAt int64  `dynamodbav:"expires_at"`
+}
+
+func entrySK(entry *models.WaitlistEntry) string {
+	return "WAITLIST#" + entry.JoinedAt.UTC().Format(joinedAtLayout) + "#" + entry.UserID
+}
+
+func entryKey(showID, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+		"sk": &types.AttributeValueMemberS{Value: sk},
+	}
+}
+
+func pointerKey(userID, showID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#" + userID},
+		"sk": &types.AttributeValueMemberS{Value: "WAITLIST#" + showID},
+	}
+}
+
+func (w WaitlistEntryDDB) toModel() models.WaitlistEntry {
+	joinedAt, _ := time.Parse(joinedAtLayout, w.JoinedAt)
+	entry := models.WaitlistEntry{
+		ShowID:       w.ShowID,
+		UserID:       w.UserID,
+		Seats:        w.Seats,
+		JoinedAt:     joinedAt,
+		Status:       w.Status,
+		OfferHoldID:  w.OfferHoldID,
+		OfferedSeats: w.OfferedSeats,
+	}
+	if w.OfferExpiresAt > 0 {
+		t := time.Unix(w.OfferExpiresAt, 0).UTC()
+		entry.OfferExpiresAt = &t
+	}
+	return entry
+}
+
+// Join adds the entry to the back of the show's queue. expiresAt is when the
+// show starts, after which the entry is of no use.
+func (wr *WaitlistRepositoryDDB) Join(ctx context.Context, entry *models.WaitlistEntry, expiresAt time.Time) error {
+	sk := entrySK(entry)
+
+	entryItem, err := attributevalue.MarshalMap(WaitlistEntryDDB{
+		PK:        "SHOW#" + entry.ShowID,
+		SK:        sk,
+		ShowID:    entry.ShowID,
+		UserID:    entry.UserID,
+		Seats:     entry.Seats,
+		JoinedAt:  entry.JoinedAt.UTC().Format(joinedAtLayout),
+		Status:    entry.Status,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	pointerItem, err := attributevalue.MarshalMap(WaitlistPointerDDB{
+		PK:        "USER#" + entry.UserID,
+		SK:        "WAITLIST#" + entry.ShowID,
+		ShowID:    entry.ShowID,
+		EntrySK:   sk,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	showItem, err := attributevalue.MarshalMap(map[string]any{
+		"pk":         "WAITLIST",
+		"sk":         "SHOW#" + entry.ShowID,
+		"show_id":    entry.ShowID,
+		"expires_at": expiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Put: &types.Put{
+					TableName:           aws.String(wr.TableName),
+					Item:                pointerItem,
+					ConditionExpression: aws.String("attribute_not_exists(pk)"),
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(wr.TableName),
+					Item:      entryItem,
+				},
+			},
+			{
+				Put: &types.Put{
+					TableName: aws.String(wr.TableName),
+					Item:      showItem,
+				},
+			},
+		},
+	})
+	if err != nil {
+		var tce *types.TransactionCanceledException
+		if errors.As(err, &tce) && len(tce.CancellationReasons) > 0 &&
+			aws.ToString(tce.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return models.ErrAlreadyWaitlisted
+		}
+		return fmt.Errorf("failed to join waitlist: %w", err)
+	}
+	return nil
+}
+
+func (wr *WaitlistRepositoryDDB) getEntry(ctx context.Context, showID, sk string) (*models.WaitlistEntry, error) {
+	out, err := wr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(wr.TableName),
+		Key:            entryKey(showID, sk),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var row WaitlistEntryDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &row); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal waitlist entry: %w", err)
+	}
+	entry := row.toModel()
+	return &entry, nil
+}
+
+func (wr *WaitlistRepositoryDDB) Get(ctx context.Context, showID, userID string) (*models.WaitlistEntry, error) {
+	out, err := wr.db.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(wr.TableName),
+		Key:            pointerKey(userID, showID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waitlist entry: %w", err)
+	}
+	if out.Item == nil {
+		return nil, nil
+	}
+
+	var pointer WaitlistPointerDDB
+	if err := attributevalue.UnmarshalMap(out.Item, &pointer); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal waitlist entry: %w", err)
+	}
+	return wr.getEntry(ctx, showID, pointer.EntrySK)
+}
+
+// ListByShow returns the show's queue in the order people joined.
+func (wr *WaitlistRepositoryDDB) ListByShow(ctx context.Context, showID string) ([]models.WaitlistEntry, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(wr.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: "SHOW#" + showID},
+			":prefix": &types.AttributeValueMemberS{Value: "WAITLIST#"},
+		},
+		ConsistentRead: aws.Bool(true),
+	}
+
+	var entries []models.WaitlistEntry
+	for {
+		out, err := wr.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query waitlist: %w", err)
+		}
+		var rows []WaitlistEntryDDB
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal waitlist: %w", err)
+		}
+		for _, row := range rows {
+			entries = append(entries, row.toModel())
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return entries, nil
+}
+
+// ListByUser returns every waitlist the user is on.
+func (wr *WaitlistRepositoryDDB) ListByUser(ctx context.Context, userID string) ([]models.WaitlistEntry, error) {
+	out, err := wr.db.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(wr.TableName),
+		KeyConditionExpression: aws.String("pk = :pk AND begins_with(sk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":     &types.AttributeValueMemberS{Value: "USER#" + userID},
+			":prefix": &types.AttributeValueMemberS{Value: "WAITLIST#"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query waitlists: %w", err)
+	}
+
+	var pointers []WaitlistPointerDDB
+	if err := attributevalue.UnmarshalListOfMaps(out.Items, &pointers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal waitlists: %w", err)
+	}
+
+	entries := make([]models.WaitlistEntry, 0, len(pointers))
+	for _, p := range pointers {
+		entry, err := wr.getEntry(ctx, p.ShowID, p.EntrySK)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+// MarkOffered records the hold offered to a waiting entry. It fails if the
+// entry has left the queue or already had an offer.
+func (wr *WaitlistRepositoryDDB) MarkOffered(ctx context.Context, entry *models.WaitlistEntry, hold *models.SeatHold) error {
+	seats, err := attributevalue.Marshal(hold.Seats)
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.db.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(wr.TableName),
+		Key:                 entryKey(entry.ShowID, entrySK(entry)),
+		UpdateExpression:    aws.String("SET #status = :offered, offer_hold_id = :hid, offered_seats = :seats, offer_expires_at = :exp"),
+		ConditionExpression: aws.String("#status = :waiting"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":offered": &types.AttributeValueMemberS{Value: models.WaitlistOffered},
+			":waiting": &types.AttributeValueMemberS{Value: models.WaitlistWaiting},
+			":hid":     &types.AttributeValueMemberS{Value: hold.HoldID},
+			":seats":   seats,
+			":exp":     &types.AttributeValueMemberN{Value: fmt.Sprint(hold.ExpiresAt.Unix())},
+		},
+	})
+	if err != nil {
+		var cce *types.ConditionalCheckFailedException
+		if errors.As(err, &cce) {
+			return models.ErrNotWaitlisted
+		}
+		return fmt.Errorf("failed to record waitlist offer: %w", err)
+	}
+
+	expiresAt := hold.ExpiresAt
+	entry.Status = models.WaitlistOffered
+	entry.OfferHoldID = hold.HoldID
+	entry.OfferedSeats = hold.Seats
+	entry.OfferExpiresAt = &expiresAt
+	return nil
+}
+
+func (wr *WaitlistRepositoryDDB) Remove(ctx context.Context, entry *models.WaitlistEntry) error {
+	_, err := wr.db.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(wr.TableName),
+					Key:       entryKey(entry.ShowID, entrySK(entry)),
+				},
+			},
+			{
+				Delete: &types.Delete{
+					TableName: aws.String(wr.TableName),
+					Key:       pointerKey(entry.UserID, entry.ShowID),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to leave waitlist: %w", err)
+	}
+	return nil
+}
+
+// ListWaitlistedShows returns the shows that have had a waitlist and have not
+// started yet.
+func (wr *WaitlistRepositoryDDB) ListWaitlistedShows(ctx context.Context) ([]string, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(wr.TableName),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		FilterExpression:       aws.String("expires_at > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":  &types.AttributeValueMemberS{Value: "WAITLIST"},
+			":now": &types.AttributeValueMemberN{Value: fmt.Sprint(time.Now().Unix())},
+		},
+	}
+
+	var showIDs []string
+	for {
+		out, err := wr.db.Query(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query waitlisted shows: %w", err)
+		}
+		for _, item := range out.Items {
+			if sk, ok := item["sk"].(*types.AttributeValueMemberS); ok {
+				showIDs = append(showIDs, strings.TrimPrefix(sk.Value, "SHOW#"))
+			}
+		}
+		if len(out.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+	return showIDs, nil
+}
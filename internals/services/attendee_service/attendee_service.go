@@ -0,0 +1,160 @@
+package attendeeservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	ticketrepository "eventro_aws/internals/repository/ticket_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type AttendeeService struct {
+	BookingRepo bookingrepository.BookingRepositoryI
+	ShowRepo    showrepository.ShowRepositoryI
+	TicketRepo  ticketrepository.TicketRepositoryI
+	UserRepo    userrepository.UserRepositoryI
+}
+
+func NewAttendeeService(bRepo bookingrepository.BookingRepositoryI,
+	sRepo showrepository.ShowRepositoryI,
+	tRepo ticketrepository.TicketRepositoryI,
+	uRepo userrepository.UserRepositoryI) *AttendeeService {
+	return &AttendeeService{
+		BookingRepo: bRepo,
+		ShowRepo:    sRepo,
+		TicketRepo:  tRepo,
+		UserRepo:    uRepo,
+	}
+}
+
+// ListAttendees returns every booking for the show with who made it and how
+// many of its seats have been checked in. Only the show's host and admins
+// can see it.
+func (as *AttendeeService) ListAttendees(ctx context.Context, userID, userRole, showID string) (*models.AttendeeList, error) {
+	show, err := as.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching show: %w", err)
+	}
+	if show == nil {
+		return nil, errors.New("show not found")
+	}
+	switch strings.ToLower(userRole) {
+	case "admin":
+	case "host":
+		if show.HostID != userID {
+			return nil, errors.New("forbidden: cannot view attendees of another host's show")
+		}
+	default:
+		return nil, errors.New("only the show's host or an admin can view attendees")
+	}
+
+	bookings, err := as.BookingRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching show bookings: %w", err)
+	}
+	admissions, err := as.TicketRepo.ListAdmissions(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching admissions: %w", err)
+	}
+	admitted := make(map[string]bool, len(admissions))
+	for _, a := range admissions {
+		admitted[a.TicketID] = true
+	}
+
+	names := map[string]string{}
+	list := &models.AttendeeList{
+		ShowID:       showID,
+		ShowDateTime: show.ShowDateTime(),
+		Attendees:    make([]models.Attendee, 0, len(bookings)),
+	}
+	for _, ref := range bookings {
+		booking := ref.Booking
+
+		name, ok := names[ref.UserID]
+		if !ok {
+			// a deleted account still has its bookings listed, just without a name
+			if user, err := as.UserRepo.GetByEmail(ref.UserID); err == nil && user != nil {
+				name = user.Username
+			}
+			names[ref.UserID] = name
+		}
+
+		attendee := models.Attendee{
+			BookingID:      booking.BookingID,
+			Name:           name,
+			Email:          ref.UserID,
+			Seats:          booking.Seats,
+			BookedAt:       booking.TimeBooked,
+			Status:         booking.Status,
+			CheckedInSeats: []string{},
+		}
+		for _, seat := range booking.Seats {
+			if admitted[models.TicketIDFor(booking.BookingID, seat)] {
+				attendee.CheckedInSeats = append(attendee.CheckedInSeats, seat)
+			}
+		}
+		switch len(attendee.CheckedInSeats) {
+		case 0:
+			attendee.CheckInStatus = models.CheckInNone
+		case len(booking.Seats):
+			attendee.CheckInStatus = models.CheckInAll
+		default:
+			attendee.CheckInStatus = models.CheckInPartial
+		}
+		list.Attendees = append(list.Attendees, attendee)
+	}
+
+	sort.SliceStable(list.Attendees, func(i, j int) bool {
+		a, b := list.Attendees[i], list.Attendees[j]
+		if !strings.EqualFold(a.Name, b.Name) {
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.Email < b.Email
+	})
+	return list, nil
+}
+
+// ExportCSV returns the attendee list as CSV, one row per booking with its
+// seats separated by spaces.
+func (as *AttendeeService) ExportCSV(ctx context.Context, userID, userRole, showID string) ([]byte, error) {
+	list, err := as.ListAttendees(ctx, userID, userRole, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"booking_id", "name", "email", "seats", "booked_at", "status", "check_in_status", "checked_in_seats"}}
+	for _, a := range list.Attendees {
+		rows = append(rows, []string{
+			a.BookingID,
+			csvSafe(a.Name),
+			csvSafe(a.Email),
+			strings.Join(a.Seats, " "),
+			a.BookedAt,
+			a.Status,
+			a.CheckInStatus,
+			strings.Join(a.CheckedInSeats, " "),
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("error writing csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvSafe stops user-supplied text from being read as a formula when the
+// export is opened in a spreadsheet.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,12 @@
+package attendeeservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/attendee_service_mock.go -package=mocks -source=interface.go
+type AttendeeServiceI interface {
+	ListAttendees(ctx context.Context, userID, userRole, showID string) (*models.AttendeeList, error)
+	ExportCSV(ctx context.Context, userID, userRole, showID string) ([]byte, error)
+}
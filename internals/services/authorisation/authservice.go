@@ -4,37 +4,83 @@ import (
 	"context"
 	"errors"
 	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	authtokenrepository "eventro_aws/internals/repository/authtoken_repository"
+	loginattemptrepository "eventro_aws/internals/repository/loginattempt_repository"
+	sessionrepository "eventro_aws/internals/repository/session_repository"
 	userrepository "eventro_aws/internals/repository/user_repository"
+	"log"
 	"net/mail"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	UserRepo userrepository.UserRepositoryI
+	UserRepo         userrepository.UserRepositoryI
+	SessionRepo      sessionrepository.SessionRepositoryI
+	AuthTokenRepo    authtokenrepository.AuthTokenRepositoryI
+	LoginAttemptRepo loginattemptrepository.LoginAttemptRepositoryI
+	Notifier         notifications.Notifier
+
+	// AppURL is the web app's base URL, which the links in password reset
+	// and verification mails point to.
+	AppURL string
 }
 
-func NewAuthService(userRepo userrepository.UserRepositoryI) *AuthService {
+func NewAuthService(userRepo userrepository.UserRepositoryI,
+	sessionRepo sessionrepository.SessionRepositoryI,
+	authTokenRepo authtokenrepository.AuthTokenRepositoryI,
+	loginAttemptRepo loginattemptrepository.LoginAttemptRepositoryI,
+	notifier notifications.Notifier) *AuthService {
 	return &AuthService{
-		UserRepo: userRepo,
+		UserRepo:         userRepo,
+		SessionRepo:      sessionRepo,
+		AuthTokenRepo:    authTokenRepo,
+		LoginAttemptRepo: loginAttemptRepo,
+		Notifier:         notifier,
 	}
 }
 
-func (a *AuthService) ValidateLogin(ctx context.Context, email, password string) (models.User, error) {
+// dummyHash is compared against when the email has no account, so a
+// missing user takes as long to reject as a wrong password.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+var errInvalidLogin = errors.New("invalid email or password")
+
+// ValidateLogin checks the user's password. Failures are counted per email
+// and per sourceIP, which are slowed down and then locked out as they add
+// up. Unknown emails and wrong passwords get the same error.
+func (a *AuthService) ValidateLogin(ctx context.Context, email, password, sourceIP string) (models.User, error) {
+	now := time.Now()
+	if err := a.checkThrottle(ctx, email, sourceIP, now); err != nil {
+		return models.User{}, err
+	}
+
 	user, err := a.UserRepo.GetByEmail(email)
 	if err != nil {
-		return models.User{}, err
+		if !errors.Is(err, userrepository.ErrUserNotFound) {
+			return models.User{}, err
+		}
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		a.recordFailure(ctx, email, sourceIP, now)
+		return models.User{}, errInvalidLogin
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		a.recordFailure(ctx, email, sourceIP, now)
+		return models.User{}, errInvalidLogin
 	}
 	if user.IsBlocked {
 		return models.User{}, errors.New("user account is blocked, please contact admin")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return models.User{}, errors.New("invalid email or password")
+	if err := a.LoginAttemptRepo.Reset(ctx, models.LoginAttemptEmail, strings.ToLower(strings.TrimSpace(email))); err != nil {
+		log.Printf("failed to reset login attempts for %s: %v", email, err)
 	}
-
 	return *user, nil
 }
 
@@ -83,18 +129,26 @@ func (a *AuthService) Signup(ctx context.Context, username, email, phoneNumber,
 		return models.User{}, errors.New("invalid phone number format")
 	}
 
+	verified := false
 	newUser := models.User{
-		UserID:      uuid.New().String(),
-		Username:    username,
-		Email:       email,
-		PhoneNumber: phoneNumber,
-		Password:    hashedPassword,
-		Role:        models.Customer,
+		UserID:        uuid.New().String(),
+		Username:      username,
+		Email:         email,
+		PhoneNumber:   phoneNumber,
+		Password:      hashedPassword,
+		Role:          models.Customer,
+		EmailVerified: &verified,
 	}
 
 	if err := a.UserRepo.Create(&newUser); err != nil {
 		return models.User{}, err
 	}
 
+	// the account is usable without verifying, and the user can ask for
+	// another link, so a failed mail does not fail the signup
+	if err := a.SendVerification(ctx, newUser); err != nil {
+		log.Printf("failed to send verification mail to %s: %v", newUser.Email, err)
+	}
+
 	return newUser, nil
 }
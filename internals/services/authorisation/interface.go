@@ -8,6 +8,20 @@ import (
 //go:generate mockgen -destination=../../mocks/auth_service_mock.go -package=mocks -source=interface.go
 
 type AuthServiceI interface {
-	ValidateLogin(ctx context.Context, email, password string) (models.User, error)
+	ValidateLogin(ctx context.Context, email, password, sourceIP string) (models.User, error)
 	Signup(ctx context.Context, username, email, phoneNumber, password string) (models.User, error)
+	StartSession(ctx context.Context, user models.User, twoFactor bool) (*models.LoginResponse, error)
+	CompleteLogin(ctx context.Context, user models.User) (*models.LoginResponse, error)
+	VerifyLoginChallenge(ctx context.Context, challengeToken, code, sourceIP string) (*models.LoginResponse, error)
+	EnrollTwoFactor(ctx context.Context, email string) (*models.TwoFactorEnrollment, error)
+	EnrollTwoFactorForLogin(ctx context.Context, challengeToken string) (*models.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, email, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, email, role, code string) error
+	Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error)
+	Logout(ctx context.Context, sessionID string) error
+	ResendVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	UnlockLogin(ctx context.Context, callerRole, email string) error
 }
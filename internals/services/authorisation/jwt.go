@@ -1,45 +1,161 @@
 package authorisation
 
 import (
+	"context"
 	"errors"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("your-secret-key")
+// AccessTokenTTL is how long an access token lasts. Clients exchange their
+// refresh token for a new one rather than logging in again.
+const AccessTokenTTL = 15 * time.Minute
+
+const (
+	defaultIssuer   = "eventro"
+	defaultAudience = "eventro-api"
+
+	// keySetTTL is how long a container keeps the key set before asking the
+	// provider again, so rotated keys are picked up without a redeploy.
+	keySetTTL = 5 * time.Minute
+	// keySetMinRefresh stops tokens with unknown kids from hammering the
+	// provider.
+	keySetMinRefresh = 30 * time.Second
+)
+
+var signingMethods = map[string]jwt.SigningMethod{
+	AlgHS256: jwt.SigningMethodHS256,
+	AlgRS256: jwt.SigningMethodRS256,
+	AlgES256: jwt.SigningMethodES256,
+}
+
+var (
+	keysMu       sync.Mutex
+	keyProvider  KeyProvider = NewEnvKeyProvider()
+	keySet       *KeySet
+	keySetLoaded time.Time
+)
+
+// SetKeyProvider replaces where signing keys come from, e.g. with one backed
+// by a secrets manager. The cached key set is dropped.
+func SetKeyProvider(p KeyProvider) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keyProvider = p
+	keySet = nil
+}
+
+// CurrentKeySet returns the cached key set, loading it when it is older than
+// maxAge.
+func CurrentKeySet(ctx context.Context, maxAge time.Duration) (*KeySet, error) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	if keySet != nil && time.Since(keySetLoaded) < maxAge {
+		return keySet, nil
+	}
+	ks, err := keyProvider.KeySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keySet, keySetLoaded = ks, time.Now()
+	return ks, nil
+}
+
+func issuer() string {
+	if iss := os.Getenv("JWT_ISSUER"); iss != "" {
+		return iss
+	}
+	return defaultIssuer
+}
+
+func audience() string {
+	if aud := os.Getenv("JWT_AUDIENCE"); aud != "" {
+		return aud
+	}
+	return defaultAudience
+}
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	TokenVersion int    `json:"tv"`
+	SessionID    string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID, email, role string) (string, error) {
+// GenerateJWT issues an access token for the user's session at their
+// current token version; bumping the version on the user or ending the
+// session invalidates it. It is signed with the active key, named in the
+// kid header.
+func GenerateJWT(userID, email, role string, tokenVersion int, sessionID string) (string, error) {
+	ks, err := CurrentKeySet(context.Background(), keySetTTL)
+	if err != nil {
+		return "", err
+	}
+	key, _ := ks.Key(ks.ActiveKeyID)
+
+	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+		SessionID:    sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        uuid.New().String(),
+			Issuer:    issuer(),
+			Audience:  jwt.ClaimStrings{audience()},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(signingMethods[key.Algorithm], claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
 }
 
+// ValidateJWT checks the token against the key named in its kid header, whose
+// algorithm it must use, as well as its issuer, audience and expiry.
 func ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key id")
+		}
+
+		ks, err := CurrentKeySet(context.Background(), keySetTTL)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := ks.Key(kid)
+		if !ok {
+			// the key may have been added since the set was cached
+			if ks, err = CurrentKeySet(context.Background(), keySetMinRefresh); err != nil {
+				return nil, err
+			}
+			if key, ok = ks.Key(kid); !ok {
+				return nil, errors.New("unknown signing key")
+			}
+		}
 
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if token.Method.Alg() != key.Algorithm {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtSecret, nil
-	})
+		return key.Public, nil
+	},
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgES256}),
+		jwt.WithIssuer(issuer()),
+		jwt.WithAudience(audience()),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,259 @@
+package authorisation
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+const (
+	minSecretLength = 32
+	minRSABits      = 2048
+)
+
+// SigningKey is one key of the JWT key set. Private is the secret for HS256,
+// an *rsa.PrivateKey for RS256 or an *ecdsa.PrivateKey for ES256, and Public
+// is what verifies its tokens. Retired keys have no Private part: they keep
+// verifying tokens issued before a rotation but never sign new ones.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   any
+	Public    any
+}
+
+// KeySet holds every key tokens may be signed with. New tokens are signed
+// with ActiveKeyID and carry it in their kid header.
+type KeySet struct {
+	ActiveKeyID string
+	Keys        []SigningKey
+}
+
+func (ks *KeySet) Key(id string) (*SigningKey, bool) {
+	for i := range ks.Keys {
+		if ks.Keys[i].ID == id {
+			return &ks.Keys[i], true
+		}
+	}
+	return nil, false
+}
+
+//go:generate mockgen -destination=../../mocks/key_provider_mock.go -package=mocks -source=keys.go
+type KeyProvider interface {
+	KeySet(ctx context.Context) (*KeySet, error)
+}
+
+// KeyConfig is how a key is written down in JWT_SIGNING_KEYS or a secret.
+// HS256 keys take a Secret; RS256 and ES256 keys take a PEM PrivateKey, or
+// only a PublicKey once they are retired.
+type KeyConfig struct {
+	ID         string `json:"kid"`
+	Algorithm  string `json:"alg"`
+	Secret     string `json:"secret,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+}
+
+// ParseKeySet reads a JSON array of KeyConfig. activeKeyID picks the signing
+// key; when empty the first key that can sign is used.
+func ParseKeySet(data []byte, activeKeyID string) (*KeySet, error) {
+	var configs []KeyConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid JWT key set: %w", err)
+	}
+	return NewKeySet(configs, activeKeyID)
+}
+
+func NewKeySet(configs []KeyConfig, activeKeyID string) (*KeySet, error) {
+	ks := &KeySet{ActiveKeyID: activeKeyID}
+	for _, c := range configs {
+		if _, dup := ks.Key(c.ID); dup {
+			return nil, fmt.Errorf("JWT key %q is listed twice", c.ID)
+		}
+		key, err := parseKey(c)
+		if err != nil {
+			return nil, err
+		}
+		ks.Keys = append(ks.Keys, *key)
+		if ks.ActiveKeyID == "" && key.Private != nil {
+			ks.ActiveKeyID = key.ID
+		}
+	}
+
+	active, ok := ks.Key(ks.ActiveKeyID)
+	if !ok {
+		return nil, errors.New("no active JWT signing key configured")
+	}
+	if active.Private == nil {
+		return nil, fmt.Errorf("active JWT key %q has no private key", active.ID)
+	}
+	return ks, nil
+}
+
+func parseKey(c KeyConfig) (*SigningKey, error) {
+	if c.ID == "" {
+		return nil, errors.New("every JWT key needs a kid")
+	}
+	key := &SigningKey{ID: c.ID, Algorithm: c.Algorithm}
+
+	switch c.Algorithm {
+	case AlgHS256:
+		if len(c.Secret) < minSecretLength {
+			return nil, fmt.Errorf("JWT key %q: HS256 secrets must be at least %d bytes", c.ID, minSecretLength)
+		}
+		key.Private = []byte(c.Secret)
+		key.Public = key.Private
+		return key, nil
+
+	case AlgRS256, AlgES256:
+		if c.PrivateKey != "" {
+			private, err := parsePrivateKey(c.PrivateKey)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %q: %w", c.ID, err)
+			}
+			key.Private = private
+			key.Public = private.Public()
+		} else if c.PublicKey != "" {
+			public, err := parsePublicKey(c.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %q: %w", c.ID, err)
+			}
+			key.Public = public
+		} else {
+			return nil, fmt.Errorf("JWT key %q needs a private_key or public_key", c.ID)
+		}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			if c.Algorithm != AlgRS256 {
+				return nil, fmt.Errorf("JWT key %q: RSA keys can only be used with %s", c.ID, AlgRS256)
+			}
+			if pub.N.BitLen() < minRSABits {
+				return nil, fmt.Errorf("JWT key %q: RSA keys must be at least %d bits", c.ID, minRSABits)
+			}
+		case *ecdsa.PublicKey:
+			if c.Algorithm != AlgES256 || pub.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("JWT key %q: EC keys must be P-256 and used with %s", c.ID, AlgES256)
+			}
+		default:
+			return nil, fmt.Errorf("JWT key %q: unsupported key type", c.ID)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("JWT key %q: algorithm must be %s, %s or %s", c.ID, AlgHS256, AlgRS256, AlgES256)
+}
+
+func parsePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if pk, ok := key.(crypto.Signer); ok {
+			return pk, nil
+		}
+		return nil, errors.New("unsupported private key type")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("could not parse private_key")
+}
+
+func parsePublicKey(data string) (any, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("public_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("could not parse public_key")
+}
+
+// EnvKeyProvider reads the key set from JWT_SIGNING_KEYS, a JSON array of
+// KeyConfig, with JWT_ACTIVE_KID choosing the signing key. A deployment that
+// only needs one shared secret can set JWT_SECRET instead. There is no
+// built in secret: with neither set every token is refused.
+type EnvKeyProvider struct{}
+
+func NewEnvKeyProvider() *EnvKeyProvider {
+	return &EnvKeyProvider{}
+}
+
+func (EnvKeyProvider) KeySet(ctx context.Context) (*KeySet, error) {
+	if keys := strings.TrimSpace(os.Getenv("JWT_SIGNING_KEYS")); keys != "" {
+		return ParseKeySet([]byte(keys), os.Getenv("JWT_ACTIVE_KID"))
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return NewKeySet([]KeyConfig{{ID: "default", Algorithm: AlgHS256, Secret: secret}}, "")
+	}
+	return nil, errors.New("no JWT signing keys configured, set JWT_SIGNING_KEYS or JWT_SECRET")
+}
+
+// JSONWebKey is the public half of an asymmetric key as published in the
+// JWKS document.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS lists the public keys other services can verify our tokens with,
+// including retired keys whose tokens may still be live. HS256 secrets are
+// never published.
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	b64 := base64.RawURLEncoding
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range ks.Keys {
+		jwk := JSONWebKey{Use: "sig", Algorithm: key.Algorithm, KeyID: key.ID}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = b64.EncodeToString(pub.N.Bytes())
+			jwk.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.KeyType = "EC"
+			jwk.Curve = "P-256"
+			jwk.X = b64.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+			jwk.Y = b64.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
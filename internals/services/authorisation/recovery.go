@@ -0,0 +1,120 @@
+package authorisation
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	PasswordResetTTL     = time.Hour
+	EmailVerificationTTL = 48 * time.Hour
+)
+
+// issueToken stores a new single-use token for the user and returns the link
+// that carries it.
+func (a *AuthService) issueToken(ctx context.Context, purpose, email, path string, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	if err := a.AuthTokenRepo.Create(ctx, purpose, hashToken(token), email, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(a.AppURL, "/") + path + "?token=" + url.QueryEscape(token), nil
+}
+
+// SendVerification mails the user a link that verifies their address.
+func (a *AuthService) SendVerification(ctx context.Context, user models.User) error {
+	link, err := a.issueToken(ctx, models.AuthTokenEmailVerification, user.Email, "/verify-email", EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	return a.Notifier.Notify(ctx, notifications.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address to start booking: %s\n\nThe link expires in %d hours.",
+			user.Username, link, int(EmailVerificationTTL.Hours())),
+	})
+}
+
+// ResendVerification sends a fresh verification link to a user who has not
+// verified yet.
+func (a *AuthService) ResendVerification(ctx context.Context, email string) error {
+	user, err := a.UserRepo.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return errors.New("email is already verified")
+	}
+	return a.SendVerification(ctx, *user)
+}
+
+func (a *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	email, err := a.AuthTokenRepo.Consume(ctx, models.AuthTokenEmailVerification, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		return err
+	}
+	return a.UserRepo.MarkEmailVerified(ctx, email)
+}
+
+// RequestPasswordReset mails a reset link if the email belongs to an active
+// account. It reports success either way so callers cannot probe for
+// accounts.
+func (a *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := a.UserRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil || user.IsBlocked {
+		return nil
+	}
+
+	link, err := a.issueToken(ctx, models.AuthTokenPasswordReset, user.Email, "/reset-password", PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	if err := a.Notifier.Notify(ctx, notifications.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this link to choose a new password: %s\n\nThe link expires in %d minutes. If you did not ask for it, you can ignore this mail.",
+			user.Username, link, int(PasswordResetTTL.Minutes())),
+	}); err != nil {
+		log.Printf("failed to send password reset mail to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token, which is used up.
+// Every session of the user is signed out and any login lockout lifted.
+// Following the link also proves the user owns the address, so it is marked
+// verified.
+func (a *AuthService) ResetPassword(ctx context.Context, token, password string) error {
+	// checked first so a weak password does not use up the token
+	if !a.IsValidPassword(password) {
+		return errors.New("password must be at least 12 characters long, and include uppercase, lowercase, number, and symbol")
+	}
+
+	email, err := a.AuthTokenRepo.Consume(ctx, models.AuthTokenPasswordReset, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		return err
+	}
+	hashed, err := a.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := a.UserRepo.SetPassword(ctx, email, hashed); err != nil {
+		return err
+	}
+	if err := a.UserRepo.MarkEmailVerified(ctx, email); err != nil {
+		log.Printf("failed to mark %s verified: %v", email, err)
+	}
+	if err := a.LoginAttemptRepo.Reset(ctx, models.LoginAttemptEmail, strings.ToLower(email)); err != nil {
+		log.Printf("failed to reset login attempts for %s: %v", email, err)
+	}
+	return nil
+}
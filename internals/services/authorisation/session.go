@@ -0,0 +1,124 @@
+package authorisation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenTTL is how long a session survives without being refreshed.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Refresh tokens are "<session id>.<secret>", the secret being 32 random
+// bytes in unpadded base64url. Only a hash of the token is stored.
+
+func newRefreshToken(sessionID string) (string, error) {
+	secret, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	return sessionID + "." + secret, nil
+}
+
+func randomToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (a *AuthService) tokenResponse(user models.User, sessionID, refreshToken string) (*models.LoginResponse, error) {
+	token, err := GenerateJWT(user.UserID, user.Email, string(user.Role), user.TokenVersion, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	return &models.LoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// StartSession opens a new session for a user who has just logged in or
+// signed up and returns its first pair of tokens. twoFactor records whether
+// the login passed a second factor.
+func (a *AuthService) StartSession(ctx context.Context, user models.User, twoFactor bool) (*models.LoginResponse, error) {
+	now := time.Now().UTC()
+	session := &models.Session{
+		SessionID:    uuid.New().String(),
+		UserEmail:    user.Email,
+		TokenVersion: user.TokenVersion,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(RefreshTokenTTL),
+		TwoFactor:    twoFactor,
+	}
+	refreshToken, err := newRefreshToken(session.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.SessionRepo.Create(ctx, session, hashToken(refreshToken)); err != nil {
+		return nil, err
+	}
+	return a.tokenResponse(user, session.SessionID, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. A token that was already exchanged ends the session, as does a
+// change to the user's access since the session began. Admin sessions that
+// did not pass 2FA, such as those opened before it was mandatory, are ended
+// too, so admins have to log in again with their second factor.
+func (a *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error) {
+	sessionID, _, ok := strings.Cut(strings.TrimSpace(refreshToken), ".")
+	if !ok || uuid.Validate(sessionID) != nil {
+		return nil, models.ErrInvalidRefreshToken
+	}
+
+	next, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session, err := a.SessionRepo.Rotate(ctx, sessionID, hashToken(refreshToken), hashToken(next), time.Now().Add(RefreshTokenTTL))
+	if err != nil {
+		if errors.Is(err, models.ErrRefreshTokenReused) {
+			if err := a.SessionRepo.Revoke(ctx, sessionID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	user, err := a.UserRepo.GetByEmail(session.UserEmail)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsBlocked || user.TokenVersion != session.TokenVersion ||
+		(user.Role == models.Admin && !session.TwoFactor) {
+		if err := a.SessionRepo.Revoke(ctx, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, models.ErrSessionRevoked
+	}
+
+	return a.tokenResponse(*user, sessionID, next)
+}
+
+// Logout ends the session; its refresh token and access tokens stop working
+// straight away.
+func (a *AuthService) Logout(ctx context.Context, sessionID string) error {
+	return a.SessionRepo.Revoke(ctx, sessionID)
+}
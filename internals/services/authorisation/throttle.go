@@ -0,0 +1,100 @@
+package authorisation
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// loginPolicy decides how failed logins are slowed down. After backoffAfter
+// failures each further attempt has to wait twice as long as the last, from
+// backoffBase up to backoffMax, and after lockoutAfter failures logins are
+// refused for lockout.
+type loginPolicy struct {
+	backoffAfter int
+	lockoutAfter int
+}
+
+var (
+	// emailPolicy protects a single account.
+	emailPolicy = loginPolicy{backoffAfter: 3, lockoutAfter: 10}
+	// ipPolicy is looser since many users can share an address.
+	ipPolicy = loginPolicy{backoffAfter: 20, lockoutAfter: 100}
+)
+
+const (
+	backoffBase = time.Second
+	backoffMax  = 5 * time.Minute
+	lockout     = 15 * time.Minute
+
+	// failureWindow is how long failures are remembered after the last one.
+	failureWindow = 24 * time.Hour
+)
+
+func (p loginPolicy) blockedUntil(failures int, now time.Time) time.Time {
+	switch {
+	case failures >= p.lockoutAfter:
+		return now.Add(lockout)
+	case failures >= p.backoffAfter:
+		wait := backoffBase << min(failures-p.backoffAfter, 20)
+		return now.Add(min(wait, backoffMax))
+	}
+	return time.Time{}
+}
+
+type loginSource struct {
+	kind   string
+	key    string
+	policy loginPolicy
+}
+
+func loginSources(email, sourceIP string) []loginSource {
+	sources := []loginSource{{models.LoginAttemptEmail, strings.ToLower(strings.TrimSpace(email)), emailPolicy}}
+	if sourceIP != "" {
+		sources = append(sources, loginSource{models.LoginAttemptIP, sourceIP, ipPolicy})
+	}
+	return sources
+}
+
+// checkThrottle refuses the login while the email or the IP is blocked.
+func (a *AuthService) checkThrottle(ctx context.Context, email, sourceIP string, now time.Time) error {
+	for _, src := range loginSources(email, sourceIP) {
+		attempts, err := a.LoginAttemptRepo.Get(ctx, src.kind, src.key)
+		if err != nil {
+			return err
+		}
+		if now.Before(attempts.BlockedUntil) {
+			return &models.LoginThrottledError{RetryAfter: attempts.BlockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// recordFailure counts a failed login against the email and the IP and
+// blocks either once its policy says so. Errors are only logged so the
+// caller still gets the login error.
+func (a *AuthService) recordFailure(ctx context.Context, email, sourceIP string, now time.Time) {
+	for _, src := range loginSources(email, sourceIP) {
+		attempts, err := a.LoginAttemptRepo.RecordFailure(ctx, src.kind, src.key, now.Add(failureWindow))
+		if err != nil {
+			log.Printf("failed to record login failure for %s %s: %v", src.kind, src.key, err)
+			continue
+		}
+		if until := src.policy.blockedUntil(attempts.Failures, now); !until.IsZero() {
+			if err := a.LoginAttemptRepo.Block(ctx, src.kind, src.key, until); err != nil {
+				log.Printf("failed to block logins for %s %s: %v", src.kind, src.key, err)
+			}
+		}
+	}
+}
+
+// UnlockLogin lets an admin lift a lockout on an account before it runs out.
+func (a *AuthService) UnlockLogin(ctx context.Context, callerRole, email string) error {
+	if strings.ToLower(callerRole) != "admin" {
+		return errors.New("forbidden: only admins can unlock accounts")
+	}
+	return a.LoginAttemptRepo.Reset(ctx, models.LoginAttemptEmail, strings.ToLower(strings.TrimSpace(email)))
+}
@@ -0,0 +1,230 @@
+package authorisation
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"eventro_aws/internals/models"
+	"strings"
+	"time"
+)
+
+const (
+	// ChallengeTTL is how long a user has to enter their code after the
+	// password step of a login.
+	ChallengeTTL = 5 * time.Minute
+
+	recoveryCodeCount = 10
+)
+
+// recoveryAlphabet leaves out characters that are easily confused.
+const recoveryAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// requiresTwoFactor reports whether the user must pass a second factor to
+// log in. It is mandatory for admins, who have to set it up on their next
+// login if they have not yet.
+func requiresTwoFactor(user models.User) bool {
+	return user.TOTPEnabled || user.Role == models.Admin
+}
+
+// CompleteLogin follows a successful ValidateLogin. Users without 2FA get
+// their tokens straight away; the rest get a challenge token to present
+// with their code.
+func (a *AuthService) CompleteLogin(ctx context.Context, user models.User) (*models.LoginResponse, error) {
+	if !requiresTwoFactor(user) {
+		return a.StartSession(ctx, user, false)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.AuthTokenRepo.Create(ctx, models.AuthTokenLoginChallenge, hashToken(token), user.Email, time.Now().Add(ChallengeTTL)); err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		TwoFactorRequired:      true,
+		TwoFactorSetupRequired: !user.TOTPEnabled,
+		ChallengeToken:         token,
+	}, nil
+}
+
+func (a *AuthService) challengeUser(ctx context.Context, challengeToken string) (*models.User, error) {
+	email, err := a.AuthTokenRepo.Get(ctx, models.AuthTokenLoginChallenge, hashToken(strings.TrimSpace(challengeToken)))
+	if err != nil {
+		return nil, err
+	}
+	return a.UserRepo.GetByEmail(email)
+}
+
+// EnrollTwoFactor issues a new secret for the user. It is not used for
+// logins until ConfirmTwoFactor is called with a code generated from it.
+func (a *AuthService) EnrollTwoFactor(ctx context.Context, email string) (*models.TwoFactorEnrollment, error) {
+	user, err := a.UserRepo.GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.UserRepo.SetPendingTOTP(ctx, user.Email, secret); err != nil {
+		return nil, err
+	}
+	return &models.TwoFactorEnrollment{Secret: secret, OTPAuthURI: totpURI(secret, user.Email)}, nil
+}
+
+// EnrollTwoFactorForLogin starts enrollment for a user who was told at login
+// that they must set up 2FA. The code is then sent to VerifyLoginChallenge.
+func (a *AuthService) EnrollTwoFactorForLogin(ctx context.Context, challengeToken string) (*models.TwoFactorEnrollment, error) {
+	user, err := a.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return a.EnrollTwoFactor(ctx, user.Email)
+}
+
+// ConfirmTwoFactor switches 2FA on once the user proves their app holds the
+// pending secret. It returns the recovery codes, which are not shown again.
+func (a *AuthService) ConfirmTwoFactor(ctx context.Context, email, code string) ([]string, error) {
+	user, err := a.UserRepo.GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	return a.enableTwoFactor(ctx, user, code, time.Now())
+}
+
+func (a *AuthService) enableTwoFactor(ctx context.Context, user *models.User, code string, now time.Time) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TOTPPendingSecret == "" {
+		return nil, errors.New("set up two-factor authentication first")
+	}
+	step, ok := matchTOTP(user.TOTPPendingSecret, code, now)
+	if !ok {
+		return nil, models.ErrInvalidTwoFactor
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.UserRepo.EnableTOTP(ctx, user.Email, user.TOTPPendingSecret, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkSecondFactor accepts a current TOTP code or an unused recovery code,
+// using it up either way.
+func (a *AuthService) checkSecondFactor(ctx context.Context, user *models.User, code string, now time.Time) error {
+	if step, ok := matchTOTP(user.TOTPSecret, code, now); ok {
+		if err := a.UserRepo.UseTOTPStep(ctx, user.Email, step); err != nil {
+			if errors.Is(err, models.ErrTwoFactorConflict) {
+				return models.ErrInvalidTwoFactor
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := a.UserRepo.UseRecoveryCode(ctx, user.Email, hashRecoveryCode(code)); err != nil {
+		if errors.Is(err, models.ErrTwoFactorConflict) {
+			return models.ErrInvalidTwoFactor
+		}
+		return err
+	}
+	return nil
+}
+
+// VerifyLoginChallenge finishes a two-step login. Users who had to set up
+// 2FA confirm it here, and get their recovery codes with the tokens. Wrong
+// codes count as failed logins, so guessing is throttled like passwords.
+func (a *AuthService) VerifyLoginChallenge(ctx context.Context, challengeToken, code, sourceIP string) (*models.LoginResponse, error) {
+	user, err := a.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := a.checkThrottle(ctx, user.Email, sourceIP, now); err != nil {
+		return nil, err
+	}
+
+	var recoveryCodes []string
+	if user.TOTPEnabled {
+		err = a.checkSecondFactor(ctx, user, code, now)
+	} else {
+		recoveryCodes, err = a.enableTwoFactor(ctx, user, code, now)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidTwoFactor) {
+			a.recordFailure(ctx, user.Email, sourceIP, now)
+		}
+		return nil, err
+	}
+
+	// the challenge is only used up once it succeeds, so a mistyped code can
+	// be retried until it expires
+	if _, err := a.AuthTokenRepo.Consume(ctx, models.AuthTokenLoginChallenge, hashToken(strings.TrimSpace(challengeToken))); err != nil {
+		return nil, err
+	}
+
+	resp, err := a.StartSession(ctx, *user, true)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = recoveryCodes
+	return resp, nil
+}
+
+// DisableTwoFactor turns 2FA off after checking a code. Admins cannot turn
+// it off.
+func (a *AuthService) DisableTwoFactor(ctx context.Context, email, role, code string) error {
+	if strings.ToLower(role) == "admin" {
+		return errors.New("forbidden: two-factor authentication is mandatory for admins")
+	}
+	user, err := a.UserRepo.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if err := a.checkSecondFactor(ctx, user, code, time.Now()); err != nil {
+		return err
+	}
+	return a.UserRepo.DisableTOTP(ctx, user.Email)
+}
+
+// newRecoveryCodes returns codes formatted XXXXX-XXXXX along with the hashes
+// that are stored.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+		}
+		codes[i] = sb.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return hashToken(code)
+}
@@ -4,26 +4,56 @@ import (
 	"context"
 	"errors"
 	"eventro_aws/internals/models"
+	"eventro_aws/internals/payments"
 	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
 	"fmt"
-	"regexp"
+	"log"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const DefaultHoldDuration = 10 * time.Minute
+
+// PaymentTimeout is how long a booking may wait for its payment before
+// ExpirePendingPayments fails it and frees its seats.
+const PaymentTimeout = 15 * time.Minute
+
+// RefundClaimTimeout is how long a refund being paid is left alone before
+// RetryRefunds may try it again.
+const RefundClaimTimeout = 5 * time.Minute
+
+// SeatReleaseListener is told when seats of a show may have become free, so
+// they can be offered to anyone waiting for them.
+type SeatReleaseListener interface {
+	OnSeatsReleased(ctx context.Context, showID string) error
+}
+
 type BookingService struct {
-	BookingRepo bookingrepository.BookingRepositoryI
-	ShowRepo    showrepository.ShowRepositoryI
+	BookingRepo  bookingrepository.BookingRepositoryI
+	ShowRepo     showrepository.ShowRepositoryI
+	PromoRepo    promorepository.PromoRepositoryI
+	Payments     payments.PaymentProvider
+	HoldDuration time.Duration
+
+	// SeatReleaseListener is optional.
+	SeatReleaseListener SeatReleaseListener
 }
 
 func NewBookingService(bRepo bookingrepository.BookingRepositoryI,
-	sRepo showrepository.ShowRepositoryI) *BookingService {
+	sRepo showrepository.ShowRepositoryI,
+	pRepo promorepository.PromoRepositoryI,
+	provider payments.PaymentProvider) *BookingService {
 	return &BookingService{
-		BookingRepo: bRepo,
-		ShowRepo:    sRepo,
+		BookingRepo:  bRepo,
+		ShowRepo:     sRepo,
+		PromoRepo:    pRepo,
+		Payments:     provider,
+		HoldDuration: DefaultHoldDuration,
 	}
 }
 
@@ -32,70 +62,428 @@ func (bs *BookingService) AddBooking(
 	userID string,
 	showID string,
 	requestedSeats []string,
+	promoCode string,
+	paymentMethod string,
 ) (*models.UserBookingDTO, error) {
+	show, err := bs.getBookableShow(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats, err := normaliseSeats(requestedSeats)
+	if err != nil {
+		return nil, err
+	}
+	if err := bs.checkSeats(show, seats, nil); err != nil {
+		return nil, err
+	}
+
+	return bs.createBooking(ctx, userID, show, seats, "", promoCode, paymentMethod)
+}
+
+// HoldSeats reserves seats for the user for HoldDuration so they can pay
+// without someone else booking them in the meantime.
+func (bs *BookingService) HoldSeats(ctx context.Context, userID, showID string, requestedSeats []string) (*models.SeatHold, error) {
+	show, err := bs.getBookableShow(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats, err := normaliseSeats(requestedSeats)
+	if err != nil {
+		return nil, err
+	}
+	if err := bs.checkSeats(show, seats, nil); err != nil {
+		return nil, err
+	}
+
+	hold := &models.SeatHold{
+		HoldID:    uuid.New().String(),
+		ShowID:    showID,
+		UserID:    userID,
+		Seats:     seats,
+		ExpiresAt: time.Now().Add(bs.HoldDuration).UTC(),
+	}
+
+	if err := bs.BookingRepo.CreateHold(ctx, hold); err != nil {
+		var conflict *models.SeatConflictError
+		if errors.As(err, &conflict) {
+			return nil, conflict
+		}
+		return nil, fmt.Errorf("error holding seats: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ConfirmHold turns an unexpired hold into a booking for the held seats.
+func (bs *BookingService) ConfirmHold(ctx context.Context, userID, holdID, promoCode, paymentMethod string) (*models.UserBookingDTO, error) {
+	hold, err := bs.getActiveHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	show, err := bs.getBookableShow(ctx, hold.ShowID)
+	if err != nil {
+		return nil, err
+	}
+	if err := bs.checkSeats(show, hold.Seats, hold.Seats); err != nil {
+		return nil, err
+	}
+
+	return bs.createBooking(ctx, userID, show, hold.Seats, hold.HoldID, promoCode, paymentMethod)
+}
+
+func (bs *BookingService) ReleaseHold(ctx context.Context, userID, holdID string) error {
+	hold, err := bs.BookingRepo.GetHold(ctx, userID, holdID)
+	if err != nil {
+		return fmt.Errorf("error fetching hold: %w", err)
+	}
+	if hold == nil {
+		return errors.New("hold not found")
+	}
+
+	if err := bs.BookingRepo.ReleaseHold(ctx, hold); err != nil {
+		return fmt.Errorf("error releasing hold: %w", err)
+	}
+	bs.seatsReleased(ctx, hold.ShowID)
+	return nil
+}
+
+// seatsReleased notifies the SeatReleaseListener. The seats are free either
+// way, so a listener failure is only logged.
+func (bs *BookingService) seatsReleased(ctx context.Context, showID string) {
+	if bs.SeatReleaseListener == nil {
+		return
+	}
+	if err := bs.SeatReleaseListener.OnSeatsReleased(ctx, showID); err != nil {
+		log.Printf("seat release listener failed for show %s: %v", showID, err)
+	}
+}
+
+func (bs *BookingService) getActiveHold(ctx context.Context, userID, holdID string) (*models.SeatHold, error) {
+	hold, err := bs.BookingRepo.GetHold(ctx, userID, holdID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching hold: %w", err)
+	}
+	if hold == nil {
+		return nil, errors.New("hold not found")
+	}
+	if time.Now().After(hold.ExpiresAt) {
+		return nil, models.ErrHoldExpired
+	}
+	return hold, nil
+}
+
+func (bs *BookingService) getBookableShow(ctx context.Context, showID string) (*models.ShowDTO, error) {
 	show, err := bs.ShowRepo.GetByID(ctx, showID)
 	if err != nil {
 		return nil, fmt.Errorf("show not found: %w", err)
 	}
+	if show == nil {
+		return nil, errors.New("show not found")
+	}
+	if show.Status == models.ShowCancelled {
+		return nil, errors.New("cannot book tickets for a cancelled show")
+	}
 	if show.IsBlocked {
 		return nil, errors.New("cannot book tickets for a blocked show")
 	}
+	return show, nil
+}
+
+// checkSeats validates the seats against the show's current state. Seats in
+// ownHeld are held by the caller and do not count as taken.
+func (bs *BookingService) checkSeats(show *models.ShowDTO, seats, ownHeld []string) error {
+	own := make(map[string]bool, len(ownHeld))
+	for _, s := range ownHeld {
+		own[s] = true
+	}
 
-	booked := make(map[string]bool)
+	taken := make(map[string]bool)
 	for _, s := range show.BookedSeats {
-		booked[s] = true
+		taken[s] = true
+	}
+	for _, s := range show.HeldSeats {
+		if !own[s] {
+			taken[s] = true
+		}
 	}
 
-	for _, seat := range requestedSeats {
-		if booked[seat] {
-			return nil, fmt.Errorf("seat %s is already booked", seat)
+	var conflicts []string
+	for _, seat := range seats {
+		if taken[seat] {
+			conflicts = append(conflicts, seat)
+			continue
+		}
+		if !bs.isValidTicket(show.SeatLayout, seat, show.BookedSeats) {
+			return fmt.Errorf("seat %s is not valid", seat)
 		}
-		if !bs.isValidTicket(seat, show.BookedSeats) {
-			return nil, fmt.Errorf("seat %s is not valid", seat)
+	}
+	if len(conflicts) > 0 {
+		return &models.SeatConflictError{Seats: conflicts}
+	}
+	return nil
+}
+
+func (bs *BookingService) createBooking(ctx context.Context, userID string, show *models.ShowDTO, seats []string, holdID, promoCode, paymentMethod string) (*models.UserBookingDTO, error) {
+	now := time.Now()
+
+	var promo *models.PromoCode
+	if promoCode != "" {
+		var err error
+		if promo, err = bs.getApplicablePromo(ctx, promoCode, userID, show, now); err != nil {
+			return nil, err
 		}
 	}
 
 	//add time booked
 	bookingID := uuid.New().String()
 
-	numTickets := len(requestedSeats)
-	totalPrice := float64(numTickets) * show.Price
+	numTickets := len(seats)
+	lineItems, originalPrice := models.PriceSeats(show.SeatLayout, show.PriceTiers, show.Price, seats)
+
+	var discount float64
+	if promo != nil {
+		discount = promo.Discount(originalPrice)
+	}
+	totalPrice := math.Round((originalPrice-discount)*100) / 100
+
+	// free bookings skip the payment step
+	status := models.BookingConfirmed
+	if totalPrice > 0 {
+		if bs.Payments == nil {
+			return nil, payments.ErrUnavailable
+		}
+		status = models.BookingPendingPayment
+	}
 
 	newBooking := &models.Booking{
 		UserID:            userID,
-		ShowID:            showID,
+		ShowID:            show.ID,
 		NumTickets:        numTickets,
 		TotalBookingPrice: totalPrice,
-		Seats:             requestedSeats,
+		Seats:             seats,
 		BookingID:         bookingID,
-		TimeBooked:        time.Now(),
+		TimeBooked:        now,
+		HoldID:            holdID,
+		LineItems:         lineItems,
+		Promo:             promo,
+		OriginalPrice:     originalPrice,
+		Discount:          discount,
+		Status:            status,
+	}
+	if status == models.BookingPendingPayment {
+		newBooking.PaymentDueAt = now.Add(PaymentTimeout)
 	}
 
+	// the booking row, the show's booked seats and any promo redemption are
+	// written in one transaction, so a seat taken or a code used up in the
+	// meantime fails the whole booking
 	if err := bs.BookingRepo.Create(ctx, newBooking); err != nil {
+		var conflict *models.SeatConflictError
+		if errors.As(err, &conflict) {
+			return nil, conflict
+		}
+		if errors.Is(err, models.ErrHoldExpired) || errors.Is(err, models.ErrPromoUnavailable) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error creating booking: %w", err)
 	}
-	if err := bs.ShowRepo.UpdateShowBooking(ctx, models.Booking{ShowID: showID, Seats: requestedSeats}); err != nil {
-		return nil, fmt.Errorf("failed to update show bookings: %w", err)
-	}
 
 	bookingDTO := models.UserBookingDTO{
 		BookingID:        newBooking.BookingID,
+		BookingDate:      newBooking.BookingDate,
 		UserEmail:        newBooking.UserID,
 		ShowID:           newBooking.ShowID,
 		TimeBooked:       newBooking.TimeBooked.String(),
 		NumTicketsBooked: newBooking.NumTickets,
+		OriginalPrice:    newBooking.OriginalPrice,
+		Discount:         newBooking.Discount,
 		TotalPrice:       newBooking.TotalBookingPrice,
 		Seats:            newBooking.Seats,
+		LineItems:        newBooking.LineItems,
+		Status:           newBooking.Status,
+	}
+	if promo != nil {
+		bookingDTO.PromoCode = promo.Code
+	}
+
+	if bookingDTO.Status == models.BookingPendingPayment {
+		if err := bs.collectPayment(ctx, &bookingDTO, paymentMethod); err != nil {
+			return nil, err
+		}
 	}
 
 	return &bookingDTO, nil
 }
 
-func (bs *BookingService) isValidTicket(userTicket string, bookedTickets []string) bool {
+// collectPayment authorizes and captures the booking's total. The seats are
+// already reserved by the pending booking; if the payment does not go through
+// the booking is marked failed and the seats are released.
+func (bs *BookingService) collectPayment(ctx context.Context, booking *models.UserBookingDTO, paymentMethod string) error {
+	payment, err := bs.Payments.Authorize(ctx, payments.AuthorizeRequest{
+		BookingID:     booking.BookingID,
+		UserID:        booking.UserEmail,
+		Amount:        booking.TotalPrice,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		return bs.failPayment(ctx, booking, "", err)
+	}
+
+	captured, err := bs.Payments.Capture(ctx, payment.ID, booking.TotalPrice)
+	if err != nil {
+		return bs.failPayment(ctx, booking, payment.ID, err)
+	}
+
+	if err := bs.BookingRepo.ConfirmPayment(ctx, booking.UserEmail, booking, captured.ID); err != nil {
+		// the money was taken but the booking could not be confirmed, so
+		// give it back rather than leave the user charged for nothing
+		if rerr := bs.Payments.Refund(ctx, captured.ID, booking.TotalPrice); rerr != nil {
+			return fmt.Errorf("error confirming booking: %w (refund failed: %v)", err, rerr)
+		}
+		return fmt.Errorf("error confirming booking: %w", err)
+	}
+
+	booking.Status = models.BookingConfirmed
+	booking.PaymentID = captured.ID
+	return nil
+}
+
+func (bs *BookingService) failPayment(ctx context.Context, booking *models.UserBookingDTO, paymentID string, cause error) error {
+	if err := bs.BookingRepo.FailPayment(ctx, booking.UserEmail, booking, paymentID); err != nil {
+		return fmt.Errorf("payment failed: %w (releasing seats failed: %v)", cause, err)
+	}
+	bs.seatsReleased(ctx, booking.ShowID)
+	booking.Status = models.BookingFailed
+	return fmt.Errorf("payment failed: %w", cause)
+}
+
+// refund gives amount of the payment back through the gateway.
+func (bs *BookingService) refund(ctx context.Context, paymentID string, amount float64) error {
+	if bs.Payments == nil {
+		return payments.ErrUnavailable
+	}
+	return bs.Payments.Refund(ctx, paymentID, amount)
+}
+
+// HandlePaymentWebhook applies a signed payment event to its booking. Events
+// for bookings no longer awaiting payment are ignored, so redelivered
+// webhooks are harmless.
+func (bs *BookingService) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	if bs.Payments == nil {
+		return payments.ErrUnavailable
+	}
+	event, err := bs.Payments.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	booking, err := bs.BookingRepo.GetByID(ctx, event.UserID, event.BookingID)
+	if err != nil {
+		return fmt.Errorf("error fetching booking: %w", err)
+	}
+	if booking == nil {
+		return errors.New("booking not found")
+	}
+	if booking.Status != models.BookingPendingPayment {
+		// a payment captured after the booking timed out bought nothing
+		if event.Type == payments.EventPaymentCaptured && booking.Status == models.BookingFailed {
+			return bs.refund(ctx, event.PaymentID, event.Amount)
+		}
+		return nil
+	}
+
+	switch event.Type {
+	case payments.EventPaymentCaptured:
+		return bs.BookingRepo.ConfirmPayment(ctx, event.UserID, booking, event.PaymentID)
+	case payments.EventPaymentFailed:
+		if err := bs.BookingRepo.FailPayment(ctx, event.UserID, booking, event.PaymentID); err != nil {
+			return err
+		}
+		bs.seatsReleased(ctx, booking.ShowID)
+	}
+	return nil
+}
+
+// ExpirePendingPayments fails bookings whose payment did not complete in
+// time, e.g. because the function died mid-payment or the webhook never
+// came, and frees their seats. It is run on a schedule.
+func (bs *BookingService) ExpirePendingPayments(ctx context.Context) error {
+	overdue, err := bs.BookingRepo.ListOverduePayments(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, pending := range overdue {
+		booking := pending.Booking
+		if booking.Status != models.BookingPendingPayment {
+			if err := bs.BookingRepo.DropPendingPayment(ctx, booking.BookingID); err != nil {
+				errs = append(errs, fmt.Errorf("booking %s: %w", booking.BookingID, err))
+			}
+			continue
+		}
+		if err := bs.BookingRepo.FailPayment(ctx, pending.UserID, &booking, booking.PaymentID); err != nil {
+			errs = append(errs, fmt.Errorf("booking %s: %w", booking.BookingID, err))
+			continue
+		}
+		bs.seatsReleased(ctx, booking.ShowID)
+	}
+	return errors.Join(errs...)
+}
+
+func (bs *BookingService) getApplicablePromo(ctx context.Context, code, userID string, show *models.ShowDTO, now time.Time) (*models.PromoCode, error) {
+	promo, err := bs.PromoRepo.GetByCode(ctx, models.NormalisePromoCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching promo code: %w", err)
+	}
+	if promo == nil {
+		return nil, errors.New("invalid promo code")
+	}
+	if err := promo.AppliesTo(show, now); err != nil {
+		return nil, err
+	}
+
+	if promo.PerUserLimit > 0 {
+		used, err := bs.PromoRepo.GetUserRedemptions(ctx, promo.Code, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching promo redemptions: %w", err)
+		}
+		if used >= promo.PerUserLimit {
+			return nil, errors.New("promo code redemption limit reached")
+		}
+	}
+	return promo, nil
+}
+
+// normaliseSeats upper-cases seat labels and rejects duplicates so the same
+// seat cannot be written twice into booked_seats. It also rejects more seats
+// than fit in the booking's transaction.
+func normaliseSeats(requested []string) ([]string, error) {
+	if len(requested) > bookingrepository.MaxSeatsPerBooking {
+		return nil, fmt.Errorf("cannot book more than %d seats at once", bookingrepository.MaxSeatsPerBooking)
+	}
+	seen := make(map[string]bool, len(requested))
+	seats := make([]string, 0, len(requested))
+	for _, seat := range requested {
+		seat = strings.ToUpper(strings.TrimSpace(seat))
+		if seen[seat] {
+			return nil, fmt.Errorf("seat %s requested more than once", seat)
+		}
+		seen[seat] = true
+		seats = append(seats, seat)
+	}
+	return seats, nil
+}
+
+// isValidTicket checks the seat exists in the venue's seat layout and is not
+// already booked.
+func (bs *BookingService) isValidTicket(layout models.SeatLayout, userTicket string, bookedTickets []string) bool {
 	userTicket = strings.ToUpper(userTicket)
 
-	matched, err := regexp.MatchString(`^[A-J](10|[1-9])$`, userTicket)
-	if err != nil || !matched {
+	if !layout.HasSeat(userTicket) {
 		return false
 	}
 
@@ -107,6 +495,279 @@ func (bs *BookingService) isValidTicket(userTicket string, bookedTickets []strin
 	return true
 }
 
+// getCancellableBooking fetches a confirmed booking of a show that has not
+// started yet, with its refund policy applied at now. A booking received by
+// transfer was paid for by its sender, so giving it up refunds nothing.
+func (bs *BookingService) getCancellableBooking(ctx context.Context, userID, bookingID string, now time.Time) (*models.UserBookingDTO, float64, error) {
+	booking, err := bs.BookingRepo.GetByID(ctx, userID, bookingID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching booking: %w", err)
+	}
+	if booking == nil {
+		return nil, 0, errors.New("booking not found")
+	}
+	if booking.Status == models.BookingCancelled {
+		return nil, 0, errors.New("booking is already cancelled")
+	}
+	if booking.Status != models.BookingConfirmed {
+		return nil, 0, errors.New("only confirmed bookings can be cancelled")
+	}
+
+	show, err := bs.ShowRepo.GetByID(ctx, booking.ShowID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching show: %w", err)
+	}
+	if show == nil {
+		return nil, 0, errors.New("show has already taken place")
+	}
+
+	startsAt, err := show.StartsAt()
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid show time: %w", err)
+	}
+	if !now.Before(startsAt) {
+		return nil, 0, errors.New("cannot cancel a booking after the show has started")
+	}
+
+	if booking.TransferredFrom != "" {
+		return booking, 0, nil
+	}
+	return booking, show.CancellationPolicy.RefundPercent(startsAt, now), nil
+}
+
+// CancelBooking cancels the user's booking, frees its seats and records the
+// refund owed under the show's cancellation policy. cancelledBy is the user
+// or admin performing the cancellation.
+func (bs *BookingService) CancelBooking(ctx context.Context, userID, bookingID, cancelledBy string) (*models.UserBookingDTO, error) {
+	now := time.Now()
+	booking, percent, err := bs.getCancellableBooking(ctx, userID, bookingID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := math.Round(booking.TotalPrice*percent) / 100
+	owed := owedRefund(userID, booking, refund, now)
+
+	if err := bs.BookingRepo.Cancel(ctx, userID, booking, refund, owed, cancelledBy, ""); err != nil {
+		return nil, fmt.Errorf("error cancelling booking: %w", err)
+	}
+	bs.seatsReleased(ctx, booking.ShowID)
+	if err := bs.payRefund(ctx, owed); err != nil {
+		log.Printf("refund for booking %s failed and will be retried: %v", booking.BookingID, err)
+		booking.RefundPending = true
+	}
+
+	booking.Status = models.BookingCancelled
+	booking.CancelledAt = now.Format(time.RFC3339)
+	booking.CancelledBy = cancelledBy
+	booking.RefundAmount = math.Round((booking.RefundAmount+refund)*100) / 100
+
+	return booking, nil
+}
+
+// CancelSeats cancels some of the seats of the user's booking. The booking
+// keeps its other seats with its price cut down to theirs, the cancelled
+// seats go back on sale and their share of the price is refunded under the
+// show's cancellation policy. Cancelling every seat cancels the booking.
+func (bs *BookingService) CancelSeats(ctx context.Context, userID, bookingID string, seats []string, cancelledBy string) (*models.UserBookingDTO, error) {
+	now := time.Now()
+	booking, percent, err := bs.getCancellableBooking(ctx, userID, bookingID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	kept, cancelled, err := booking.SplitSeats(seats)
+	if err != nil {
+		return nil, err
+	}
+	if len(kept.Seats) == 0 {
+		return bs.CancelBooking(ctx, userID, bookingID, cancelledBy)
+	}
+
+	refund := math.Round(cancelled.TotalPrice*percent) / 100
+	owed := owedRefund(userID, booking, refund, now)
+	if err := bs.BookingRepo.CancelSeats(ctx, userID, booking, &kept, cancelled.Seats, refund, owed); err != nil {
+		return nil, fmt.Errorf("error cancelling seats: %w", err)
+	}
+	bs.seatsReleased(ctx, booking.ShowID)
+	if err := bs.payRefund(ctx, owed); err != nil {
+		log.Printf("refund for booking %s failed and will be retried: %v", booking.BookingID, err)
+		kept.RefundPending = true
+	}
+
+	kept.TicketVersion++
+	kept.RefundAmount = math.Round((booking.RefundAmount+refund)*100) / 100
+	kept.CancelledSeats = append(kept.CancelledSeats, cancelled.Seats...)
+	return &kept, nil
+}
+
+// CancelShowBookings cancels every confirmed booking for a cancelled show with
+// a full refund, and fails bookings still awaiting payment so their seats are
+// not held for a show that will not happen. Bookings and refunds that fail
+// are reported in the summary rather than stopping the rest; calling it again
+// retries both.
+func (bs *BookingService) CancelShowBookings(ctx context.Context, showID, reason, cancelledBy string) (*models.ShowCancellationSummary, error) {
+	now := time.Now()
+	bookings, err := bs.BookingRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching show bookings: %w", err)
+	}
+
+	summary := &models.ShowCancellationSummary{ShowID: showID, Reason: reason}
+	tried := make(map[string]bool)
+	for i := range bookings {
+		userID, booking := bookings[i].UserID, &bookings[i].Booking
+		if booking.Status == models.BookingPendingPayment {
+			// a payment still captured for it is refunded when its webhook
+			// arrives
+			if err := bs.BookingRepo.FailPayment(ctx, userID, booking, booking.PaymentID); err != nil {
+				summary.FailedBookings = append(summary.FailedBookings, booking.BookingID)
+				continue
+			}
+			summary.PaymentsFailed++
+			continue
+		}
+		if booking.Status != models.BookingConfirmed {
+			continue
+		}
+
+		refund := booking.TotalPrice
+		owed := owedRefund(userID, booking, refund, now)
+		if err := bs.BookingRepo.Cancel(ctx, userID, booking, refund, owed, cancelledBy, reason); err != nil {
+			summary.FailedBookings = append(summary.FailedBookings, booking.BookingID)
+			continue
+		}
+		summary.BookingsCancelled++
+		if owed == nil {
+			continue
+		}
+		tried[owed.RefundID] = true
+		if err := bs.payRefund(ctx, owed); err != nil {
+			log.Printf("refund for booking %s failed and will be retried: %v", booking.BookingID, err)
+			summary.RefundsPending++
+			continue
+		}
+		summary.TotalRefunded += owed.Amount
+	}
+
+	// refunds that failed on an earlier call
+	owed, err := bs.BookingRepo.ListPendingRefunds(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pending refunds: %w", err)
+	}
+	for i := range owed {
+		if owed[i].ShowID != showID || tried[owed[i].RefundID] {
+			continue
+		}
+		paid, err := bs.retryRefund(ctx, &owed[i], now)
+		if err != nil {
+			log.Printf("refund for booking %s failed again: %v", owed[i].BookingID, err)
+			summary.RefundsPending++
+			continue
+		}
+		if paid {
+			summary.TotalRefunded += owed[i].Amount
+		}
+	}
+	summary.TotalRefunded = math.Round(summary.TotalRefunded*100) / 100
+
+	return summary, nil
+}
+
+// owedRefund is the refund for a cancellation still to be paid through the
+// gateway, or nil when there is nothing to pay. Seats received by transfer
+// are refunded to the sender who paid for them.
+func owedRefund(userID string, booking *models.UserBookingDTO, amount float64, now time.Time) *models.PendingRefund {
+	paymentID := booking.PaymentID
+	if paymentID == "" {
+		paymentID = booking.OriginalPaymentID
+	}
+	if amount <= 0 || paymentID == "" {
+		return nil
+	}
+	return &models.PendingRefund{
+		RefundID:  uuid.New().String(),
+		UserID:    userID,
+		BookingID: booking.BookingID,
+		ShowID:    booking.ShowID,
+		PaymentID: paymentID,
+		Amount:    amount,
+		RetryAt:   now.Add(RefundClaimTimeout),
+	}
+}
+
+// payRefund pays a refund queued by a cancellation and takes it off the
+// queue. A refund that fails is left queued for RetryRefunds.
+func (bs *BookingService) payRefund(ctx context.Context, owed *models.PendingRefund) error {
+	if owed == nil {
+		return nil
+	}
+	if err := bs.refund(ctx, owed.PaymentID, owed.Amount); err != nil {
+		if rerr := bs.BookingRepo.ReleaseRefund(ctx, owed.RefundID); rerr != nil {
+			log.Printf("failed to release refund %s: %v", owed.RefundID, rerr)
+		}
+		return err
+	}
+	return bs.BookingRepo.CompleteRefund(ctx, owed.RefundID)
+}
+
+// retryRefund pays a queued refund unless someone else is already paying
+// it, and reports whether it paid.
+func (bs *BookingService) retryRefund(ctx context.Context, owed *models.PendingRefund, now time.Time) (bool, error) {
+	claimed, err := bs.BookingRepo.ClaimRefund(ctx, owed.RefundID, now, now.Add(RefundClaimTimeout))
+	if err != nil || !claimed {
+		return false, err
+	}
+	if err := bs.payRefund(ctx, owed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RetryRefunds pays the refunds of cancelled bookings that could not be paid
+// when they were cancelled. It is run on a schedule.
+func (bs *BookingService) RetryRefunds(ctx context.Context) error {
+	now := time.Now()
+	owed, err := bs.BookingRepo.ListPendingRefunds(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range owed {
+		if _, err := bs.retryRefund(ctx, &owed[i], now); err != nil {
+			errs = append(errs, fmt.Errorf("refund %s: %w", owed[i].RefundID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MoveShowBookings re-keys every booking for a rescheduled show under its new
+// date and time.
+func (bs *BookingService) MoveShowBookings(ctx context.Context, showID, newShowDateTime string) error {
+	bookings, err := bs.BookingRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return fmt.Errorf("error fetching show bookings: %w", err)
+	}
+
+	var errs []error
+	for i := range bookings {
+		if err := bs.BookingRepo.MoveShowDate(ctx, bookings[i].UserID, &bookings[i].Booking, newShowDateTime); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error moving bookings: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// BackfillShowIndex indexes bookings made before the show booking index
+// under their show.
+func (bs *BookingService) BackfillShowIndex(ctx context.Context) (int, error) {
+	return bs.BookingRepo.BackfillShowIndex(ctx)
+}
+
 func (bs *BookingService) BrowseBookings(ctx context.Context, userID string) ([]models.UserBookingDTO, error) {
 	bookings, err := bs.BookingRepo.ListByUser(ctx, userID)
 	if err != nil {
@@ -12,6 +12,19 @@ type BookingServiceI interface {
 		userID string,
 		showID string,
 		requestedSeats []string,
+		promoCode string,
+		paymentMethod string,
 	) (*models.UserBookingDTO, error)
 	BrowseBookings(ctx context.Context, userID string) ([]models.UserBookingDTO, error)
+	CancelBooking(ctx context.Context, userID, bookingID, cancelledBy string) (*models.UserBookingDTO, error)
+	CancelSeats(ctx context.Context, userID, bookingID string, seats []string, cancelledBy string) (*models.UserBookingDTO, error)
+	HoldSeats(ctx context.Context, userID, showID string, requestedSeats []string) (*models.SeatHold, error)
+	ConfirmHold(ctx context.Context, userID, holdID, promoCode, paymentMethod string) (*models.UserBookingDTO, error)
+	ReleaseHold(ctx context.Context, userID, holdID string) error
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error
+	ExpirePendingPayments(ctx context.Context) error
+	RetryRefunds(ctx context.Context) error
+	CancelShowBookings(ctx context.Context, showID, reason, cancelledBy string) (*models.ShowCancellationSummary, error)
+	MoveShowBookings(ctx context.Context, showID, newShowDateTime string) error
+	BackfillShowIndex(ctx context.Context) (int, error)
 }
@@ -20,6 +20,10 @@ func NewEventService(eventRepo eventsrepository.EventRepositoryI) *EventService
 }
 
 func (e *EventService) CreateNewEvent(ctx context.Context, name, description, duration string, category models.EventCategory, artistIDs []string) (models.EventResponse, error) {
+	if _, err := models.ParseEventDuration(duration); err != nil {
+		return models.EventResponse{}, fmt.Errorf("invalid duration: %w", err)
+	}
+
 	name = strings.ToLower(name)
 	eventID := uuid.New().String()
 	event := models.Event{
@@ -130,7 +134,7 @@ func (e *EventService) GetHostEvents(ctx context.Context, hostID string) ([]*mod
 func (s *EventService) GetEventByID(ctx context.Context, id string) (*models.EventDTO, error) {
 	event, err := s.EventRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("from get by id : " + err.Error())
+		return nil, fmt.Errorf("from get by id : %w", err)
 	}
 
 	return event, nil
@@ -0,0 +1,154 @@
+package hostapplicationservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	"eventro_aws/internals/notifications"
+	hostapplicationrepository "eventro_aws/internals/repository/host_application_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type HostApplicationService struct {
+	HostApplicationRepo hostapplicationrepository.HostApplicationRepositoryI
+	UserRepo            userrepository.UserRepositoryI
+	Notifier            notifications.Notifier
+}
+
+func NewHostApplicationService(hRepo hostapplicationrepository.HostApplicationRepositoryI,
+	uRepo userrepository.UserRepositoryI,
+	notifier notifications.Notifier) *HostApplicationService {
+	return &HostApplicationService{
+		HostApplicationRepo: hRepo,
+		UserRepo:            uRepo,
+		Notifier:            notifier,
+	}
+}
+
+// Apply submits the customer's application to become a host. Only one
+// application can be pending at a time.
+func (hs *HostApplicationService) Apply(ctx context.Context, userEmail, userRole string, req models.HostApplicationRequest) (*models.HostApplication, error) {
+	if strings.ToLower(userRole) != "customer" {
+		return nil, errors.New("forbidden: only customers can apply to become hosts")
+	}
+
+	app := &models.HostApplication{
+		UserEmail:    userEmail,
+		BusinessName: strings.TrimSpace(req.BusinessName),
+		ContactPhone: strings.TrimSpace(req.ContactPhone),
+		Address:      strings.TrimSpace(req.Address),
+		Website:      strings.TrimSpace(req.Website),
+		Description:  strings.TrimSpace(req.Description),
+		Status:       models.HostApplicationPending,
+		SubmittedAt:  time.Now().UTC(),
+	}
+	switch {
+	case app.BusinessName == "":
+		return nil, errors.New("business_name is required")
+	case app.ContactPhone == "":
+		return nil, errors.New("contact_phone is required")
+	case app.Address == "":
+		return nil, errors.New("address is required")
+	case app.Description == "":
+		return nil, errors.New("description is required")
+	}
+
+	if err := hs.HostApplicationRepo.Submit(ctx, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// GetApplication returns the user's latest application.
+func (hs *HostApplicationService) GetApplication(ctx context.Context, userEmail string) (*models.HostApplication, error) {
+	app, err := hs.HostApplicationRepo.Get(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, models.ErrHostApplicationNotFound
+	}
+	return app, nil
+}
+
+// ListPending returns the review queue, oldest application first.
+func (hs *HostApplicationService) ListPending(ctx context.Context, callerRole string) ([]models.HostApplication, error) {
+	if strings.ToLower(callerRole) != "admin" {
+		return nil, errors.New("forbidden: only admins can review host applications")
+	}
+	return hs.HostApplicationRepo.ListPending(ctx)
+}
+
+// Review approves or rejects a pending application. Approving makes the user
+// a host, which signs them out so their next login carries the new role.
+// Either way the user is notified of the outcome.
+func (hs *HostApplicationService) Review(ctx context.Context, callerEmail, callerRole, userEmail string, review models.HostApplicationReview) (*models.HostApplication, error) {
+	if strings.ToLower(callerRole) != "admin" {
+		return nil, errors.New("forbidden: only admins can review host applications")
+	}
+	reason := strings.TrimSpace(review.Reason)
+	if !review.Approve && reason == "" {
+		return nil, errors.New("a reason is required when rejecting an application")
+	}
+
+	app, err := hs.HostApplicationRepo.Get(ctx, userEmail)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, models.ErrHostApplicationNotFound
+	}
+	if app.Status != models.HostApplicationPending {
+		return nil, models.ErrHostApplicationNotPending
+	}
+
+	now := time.Now().UTC()
+	app.ReviewedBy = callerEmail
+	app.ReviewedAt = &now
+	app.Reason = reason
+
+	var n notifications.Notification
+	if review.Approve {
+		user, err := hs.UserRepo.GetByEmail(userEmail)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching user: %w", err)
+		}
+		if user.Role != models.Customer {
+			return nil, fmt.Errorf("user is already a %s", strings.ToLower(string(user.Role)))
+		}
+
+		app.Status = models.HostApplicationApproved
+		if err := hs.HostApplicationRepo.Approve(ctx, app, user); err != nil {
+			return nil, err
+		}
+		n = notifications.Notification{
+			To:      userEmail,
+			Subject: "Your host application was approved",
+			Body:    fmt.Sprintf("%s can now create events. Please log in again to start hosting.", app.BusinessName),
+		}
+		if reason != "" {
+			n.Body += "\n" + reason
+		}
+	} else {
+		app.Status = models.HostApplicationRejected
+		if err := hs.HostApplicationRepo.Reject(ctx, app); err != nil {
+			return nil, err
+		}
+		n = notifications.Notification{
+			To:      userEmail,
+			Subject: "Your host application was not approved",
+			Body:    fmt.Sprintf("Your application for %s was rejected: %s. You may apply again.", app.BusinessName, reason),
+		}
+	}
+
+	// the decision is already recorded, so a failed notification is only
+	// logged
+	if err := hs.Notifier.Notify(ctx, n); err != nil {
+		log.Printf("failed to notify %s about their host application: %v", userEmail, err)
+	}
+	return app, nil
+}
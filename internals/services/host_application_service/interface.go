@@ -0,0 +1,14 @@
+package hostapplicationservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/host_application_service_mock.go -package=mocks -source=interface.go
+type HostApplicationServiceI interface {
+	Apply(ctx context.Context, userEmail, userRole string, req models.HostApplicationRequest) (*models.HostApplication, error)
+	GetApplication(ctx context.Context, userEmail string) (*models.HostApplication, error)
+	ListPending(ctx context.Context, callerRole string) ([]models.HostApplication, error)
+	Review(ctx context.Context, callerEmail, callerRole, userEmail string, review models.HostApplicationReview) (*models.HostApplication, error)
+}
@@ -0,0 +1,10 @@
+package promoservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+type PromoServiceI interface {
+	CreatePromo(ctx context.Context, creatorID, creatorRole string, promo models.PromoCode) (*models.PromoCode, error)
+}
@@ -0,0 +1,102 @@
+package promoservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	eventrepository "eventro_aws/internals/repository/event_repository"
+	promorepository "eventro_aws/internals/repository/promo_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
+	"fmt"
+	"strings"
+)
+
+type PromoService struct {
+	PromoRepo promorepository.PromoRepositoryI
+	EventRepo eventrepository.EventRepositoryI
+	ShowRepo  showrepository.ShowRepositoryI
+	VenueRepo venuerepository.VenueRepositoryI
+}
+
+func NewPromoService(pRepo promorepository.PromoRepositoryI,
+	eRepo eventrepository.EventRepositoryI,
+	sRepo showrepository.ShowRepositoryI,
+	vRepo venuerepository.VenueRepositoryI) *PromoService {
+	return &PromoService{
+		PromoRepo: pRepo,
+		EventRepo: eRepo,
+		ShowRepo:  sRepo,
+		VenueRepo: vRepo,
+	}
+}
+
+// CreatePromo stores a new promo code. Admins may create codes for any scope;
+// hosts may only create codes scoped to their own events, shows or venues.
+func (ps *PromoService) CreatePromo(ctx context.Context, creatorID, creatorRole string, promo models.PromoCode) (*models.PromoCode, error) {
+	promo.Code = models.NormalisePromoCode(promo.Code)
+	promo.Redemptions = 0
+	promo.CreatedBy = creatorID
+
+	if err := promo.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid promo code: %w", err)
+	}
+
+	switch strings.ToLower(creatorRole) {
+	case "admin":
+	case "host":
+		if err := ps.checkHostScope(ctx, creatorID, promo); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("only admins and hosts can create promo codes")
+	}
+
+	if err := ps.PromoRepo.Create(ctx, &promo); err != nil {
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return &promo, nil
+}
+
+func (ps *PromoService) checkHostScope(ctx context.Context, hostID string, promo models.PromoCode) error {
+	switch promo.ScopeType {
+	case models.ScopeAll:
+		return errors.New("hosts can only create promo codes scoped to their own events, shows or venues")
+
+	case models.ScopeHost:
+		if promo.ScopeID != hostID {
+			return errors.New("hosts can only create promo codes for themselves")
+		}
+
+	case models.ScopeVenue:
+		venue, err := ps.VenueRepo.GetByID(ctx, promo.ScopeID)
+		if err != nil || venue == nil {
+			return errors.New("venue not found")
+		}
+		if venue.HostID != hostID {
+			return errors.New("venue does not belong to host")
+		}
+
+	case models.ScopeShow:
+		show, err := ps.ShowRepo.GetByID(ctx, promo.ScopeID)
+		if err != nil || show == nil {
+			return errors.New("show not found")
+		}
+		if show.HostID != hostID {
+			return errors.New("show does not belong to host")
+		}
+
+	case models.ScopeEvent:
+		events, err := ps.EventRepo.GetEventsHostedByHost(ctx, hostID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch host events: %w", err)
+		}
+		for _, e := range events {
+			if strings.TrimPrefix(e.EventID, "EVENT#") == promo.ScopeID {
+				return nil
+			}
+		}
+		return errors.New("event does not belong to host")
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package reportservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/report_service_mock.go -package=mocks -source=interface.go
+type ReportServiceI interface {
+	GetHostReport(ctx context.Context, userID, userRole, hostID string, filter ReportFilter) (*models.HostSalesReport, error)
+}
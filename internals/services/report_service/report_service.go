@@ -0,0 +1,151 @@
+package reportservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	reportrepository "eventro_aws/internals/repository/report_repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ReportService struct {
+	ReportRepo reportrepository.ReportRepositoryI
+}
+
+func NewReportService(rRepo reportrepository.ReportRepositoryI) *ReportService {
+	return &ReportService{ReportRepo: rRepo}
+}
+
+// ReportFilter narrows a report to one event or venue and to shows starting
+// between From and To (dates, inclusive). Empty fields match everything.
+type ReportFilter struct {
+	EventID string
+	VenueID string
+	From    string
+	To      string
+}
+
+func (f ReportFilter) matches(show models.ShowSales) bool {
+	if f.EventID != "" && show.EventID != f.EventID {
+		return false
+	}
+	if f.VenueID != "" && show.VenueID != f.VenueID {
+		return false
+	}
+	date, _, _ := strings.Cut(show.ShowDateTime, "T")
+	if f.From != "" && date < f.From {
+		return false
+	}
+	if f.To != "" && date > f.To {
+		return false
+	}
+	return true
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// add folds a show's figures into the totals.
+func add(totals *models.SalesTotals, show models.SalesTotals) {
+	totals.Shows += show.Shows
+	totals.Capacity += show.Capacity
+	totals.SeatsSold += show.SeatsSold
+	totals.CancelledSeats += show.CancelledSeats
+	totals.Bookings += show.Bookings
+	totals.GrossRevenue += show.GrossRevenue
+	totals.Refunds += show.Refunds
+}
+
+// finish rounds the money and works out the derived figures.
+func finish(totals *models.SalesTotals) {
+	totals.GrossRevenue = roundMoney(totals.GrossRevenue)
+	totals.Refunds = roundMoney(totals.Refunds)
+	totals.NetRevenue = roundMoney(totals.GrossRevenue - totals.Refunds)
+	totals.Occupancy = 0
+	if totals.Capacity > 0 {
+		totals.Occupancy = math.Round(float64(totals.SeatsSold)/float64(totals.Capacity)*10000) / 10000
+	}
+}
+
+// GetHostReport returns a host's sales per show with totals by event, by
+// venue and overall. Hosts see their own report; admins pass hostID to see
+// any host's.
+func (rs *ReportService) GetHostReport(ctx context.Context, userID, userRole, hostID string, filter ReportFilter) (*models.HostSalesReport, error) {
+	switch strings.ToLower(userRole) {
+	case "host":
+		if hostID != "" && hostID != userID {
+			return nil, errors.New("hosts can only view their own reports")
+		}
+		hostID = userID
+	case "admin":
+		if hostID == "" {
+			return nil, errors.New("hostID is required")
+		}
+	default:
+		return nil, errors.New("only hosts and admins can view sales reports")
+	}
+	for _, date := range []string{filter.From, filter.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, errors.New("from and to must be dates in YYYY-MM-DD format")
+		}
+	}
+
+	all, err := rs.ReportRepo.ListShowSales(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.HostSalesReport{
+		HostID:      hostID,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Shows:       []models.ShowSales{},
+		Events:      []models.EventSales{},
+		Venues:      []models.VenueSales{},
+	}
+	events := map[string]*models.EventSales{}
+	venues := map[string]*models.VenueSales{}
+	for _, show := range all {
+		if !filter.matches(show) {
+			continue
+		}
+		finish(&show.SalesTotals)
+		report.Shows = append(report.Shows, show)
+		add(&report.Totals, show.SalesTotals)
+
+		event, ok := events[show.EventID]
+		if !ok {
+			event = &models.EventSales{EventID: show.EventID, EventName: show.EventName}
+			events[show.EventID] = event
+		}
+		add(&event.SalesTotals, show.SalesTotals)
+
+		venue, ok := venues[show.VenueID]
+		if !ok {
+			venue = &models.VenueSales{VenueID: show.VenueID, VenueName: show.VenueName}
+			venues[show.VenueID] = venue
+		}
+		add(&venue.SalesTotals, show.SalesTotals)
+	}
+
+	finish(&report.Totals)
+	for _, event := range events {
+		finish(&event.SalesTotals)
+		report.Events = append(report.Events, *event)
+	}
+	for _, venue := range venues {
+		finish(&venue.SalesTotals)
+		report.Venues = append(report.Venues, *venue)
+	}
+
+	sort.Slice(report.Shows, func(i, j int) bool { return report.Shows[i].ShowDateTime < report.Shows[j].ShowDateTime })
+	sort.Slice(report.Events, func(i, j int) bool { return report.Events[i].EventName < report.Events[j].EventName })
+	sort.Slice(report.Venues, func(i, j int) bool { return report.Venues[i].VenueName < report.Venues[j].VenueName })
+	return report, nil
+}
@@ -7,10 +7,19 @@ import (
 )
 
 type ShowServiceI interface {
-	UpdateShow(ctx context.Context, showID string, userID string, isBlocked bool) error
+	UpdateShow(ctx context.Context, showID, userID, userRole string, isBlocked bool) error
 	BrowseShows(ctx context.Context, eventID, city, date, venueID, hostID string) ([]models.ShowDTO, error)
 	CreateShow(ctx context.Context, eventID string, venueID string,
 		hostID string, price float64, showDate time.Time,
-		showTime string) error
+		showTime string, policy *models.CancellationPolicy, tiers []models.PriceTier) error
 	GetShowByID(ctx context.Context, showID string) (*models.ShowDTO, error)
+	GetSeatMap(ctx context.Context, showID string) (*models.ShowSeatMap, error)
+	CancelShow(ctx context.Context, showID, userID, userRole, reason string) (*models.ShowCancellationSummary, error)
+	RescheduleShow(ctx context.Context, showID, userID, userRole string, showDate time.Time, showTime string) (*models.ShowDTO, error)
+	BackfillVenueSlots(ctx context.Context) (int, error)
+	PreviewSeries(ctx context.Context, hostID string, spec models.SeriesSpec) (*models.SeriesPreview, error)
+	CreateSeries(ctx context.Context, hostID string, spec models.SeriesSpec, skipConflicts bool) (*models.ShowSeries, error)
+	GetSeries(ctx context.Context, seriesID, userID, userRole string) (*models.ShowSeries, error)
+	UpdateSeries(ctx context.Context, seriesID, userID, userRole string, update models.SeriesUpdate) (*models.ShowSeries, error)
+	CancelSeries(ctx context.Context, seriesID, userID, userRole, showID, reason string) ([]models.ShowCancellationSummary, error)
 }
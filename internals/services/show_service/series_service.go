@@ -0,0 +1,398 @@
+package showservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PreviewSeries expands the spec into its occurrences and marks the ones that
+// clash with shows already at the venue, or with earlier occurrences.
+func (s *ShowService) PreviewSeries(ctx context.Context, hostID string, spec models.SeriesSpec) (*models.SeriesPreview, error) {
+	preview, _, err := s.previewSeries(ctx, hostID, spec)
+	return preview, err
+}
+
+func (s *ShowService) previewSeries(ctx context.Context, hostID string, spec models.SeriesSpec) (*models.SeriesPreview, time.Duration, error) {
+	duration, err := s.validateNewShows(ctx, spec.EventID, spec.VenueID, hostID, spec.CancellationPolicy, spec.PriceTiers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	starts, err := spec.Occurrences(time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	schedule, err := s.ShowRepo.GetVenueSchedule(ctx, spec.VenueID,
+		starts[0].Add(-models.MaxEventDuration-s.ChangeoverBuffer), starts[len(starts)-1].Add(duration+s.ChangeoverBuffer))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	preview := &models.SeriesPreview{Occurrences: make([]models.SeriesOccurrence, 0, len(starts))}
+	for _, startsAt := range starts {
+		occ := models.SeriesOccurrence{StartsAt: startsAt, Status: models.ShowScheduled}
+		endsAt := startsAt.Add(duration)
+		if clashes := schedule.Conflicts(startsAt, endsAt, s.ChangeoverBuffer, ""); len(clashes) > 0 {
+			occ.Status = models.OccurrenceConflict
+			occ.ConflictsWith = clashes
+			preview.Conflicts++
+		} else {
+			occ.ShowID = uuid.New().String()
+			schedule.Slots = append(schedule.Slots, models.VenueSlot{
+				ShowID:   occ.ShowID,
+				VenueID:  spec.VenueID,
+				StartsAt: startsAt,
+				EndsAt:   endsAt,
+			})
+		}
+		preview.Occurrences = append(preview.Occurrences, occ)
+	}
+	return preview, duration, nil
+}
+
+// CreateSeries creates a show for every occurrence of the spec, in batched
+// transactions. Occurrences that clash with other shows fail the whole
+// series unless skipConflicts is set, in which case they are left out and
+// reported in Skipped. If a later batch fails the series is returned with the
+// shows created so far.
+func (s *ShowService) CreateSeries(ctx context.Context, hostID string, spec models.SeriesSpec, skipConflicts bool) (*models.ShowSeries, error) {
+	preview, duration, err := s.previewSeries(ctx, hostID, spec)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Conflicts > 0 && !skipConflicts {
+		return nil, preview.ConflictError()
+	}
+
+	scheduled := preview.Scheduled()
+	if len(scheduled) == 0 {
+		return nil, errors.New("every occurrence conflicts with shows already at the venue")
+	}
+
+	series := &models.ShowSeries{
+		ID:          uuid.New().String(),
+		HostID:      hostID,
+		Status:      models.SeriesActive,
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		SeriesSpec:  spec,
+		Occurrences: []models.SeriesOccurrence{},
+	}
+	for _, occ := range preview.Occurrences {
+		if occ.Status == models.OccurrenceConflict {
+			series.Skipped = append(series.Skipped, occ)
+		}
+	}
+
+	if err := s.ShowRepo.CreateSeries(ctx, series); err != nil {
+		return nil, err
+	}
+
+	for start := 0; start < len(scheduled); start += showrepository.MaxShowsPerBatch {
+		batch := scheduled[start:min(start+showrepository.MaxShowsPerBatch, len(scheduled))]
+		created, skipped, err := s.createSeriesBatch(ctx, series, batch, duration, skipConflicts)
+		series.Occurrences = append(series.Occurrences, created...)
+		series.Skipped = append(series.Skipped, skipped...)
+		if err != nil {
+			return series, err
+		}
+	}
+
+	return series, nil
+}
+
+// createSeriesBatch writes one batch of occurrences, re-checking them
+// against the venue schedule since other shows may have been added after
+// the preview.
+func (s *ShowService) createSeriesBatch(ctx context.Context, series *models.ShowSeries, batch []models.SeriesOccurrence,
+	duration time.Duration, skipConflicts bool) (created, skipped []models.SeriesOccurrence, err error) {
+	first, last := batch[0].StartsAt, batch[len(batch)-1].StartsAt
+
+	for attempt := 1; ; attempt++ {
+		schedule, err := s.ShowRepo.GetVenueSchedule(ctx, series.VenueID,
+			first.Add(-models.MaxEventDuration-s.ChangeoverBuffer), last.Add(duration+s.ChangeoverBuffer))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		created, skipped = nil, nil
+		shows := make([]*models.Show, 0, len(batch))
+		for _, occ := range batch {
+			endsAt := occ.StartsAt.Add(duration)
+			if clashes := schedule.Conflicts(occ.StartsAt, endsAt, s.ChangeoverBuffer, occ.ShowID); len(clashes) > 0 {
+				if !skipConflicts {
+					return nil, nil, &models.ShowConflictError{ShowIDs: clashes}
+				}
+				skipped = append(skipped, models.SeriesOccurrence{
+					StartsAt:      occ.StartsAt,
+					Status:        models.OccurrenceConflict,
+					ConflictsWith: clashes,
+				})
+				continue
+			}
+
+			shows = append(shows, &models.Show{
+				ID:          occ.ShowID,
+				HostID:      series.HostID,
+				VenueID:     series.VenueID,
+				EventID:     series.EventID,
+				Price:       series.Price,
+				ShowDate:    occ.StartsAt.Truncate(24 * time.Hour),
+				ShowTime:    occ.StartsAt.Format("15:04"),
+				BookedSeats: []string{},
+
+				CancellationPolicy: series.CancellationPolicy,
+				PriceTiers:         series.PriceTiers,
+				EndsAt:             endsAt,
+				ScheduleVersion:    schedule.Version,
+				SeriesID:           series.ID,
+			})
+			created = append(created, occ)
+		}
+		if len(shows) == 0 {
+			return nil, skipped, nil
+		}
+
+		err = s.ShowRepo.CreateBatch(ctx, shows)
+		if errors.Is(err, models.ErrScheduleChanged) && attempt < maxScheduleAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create shows: %w", err)
+		}
+		return created, skipped, nil
+	}
+}
+
+// getManagedSeries fetches a series the caller may manage: admins may manage
+// any series, hosts only their own.
+func (s *ShowService) getManagedSeries(ctx context.Context, seriesID, userID, userRole string) (*models.ShowSeries, error) {
+	series, err := s.ShowRepo.GetSeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if series == nil {
+		return nil, errors.New("series not found")
+	}
+
+	switch strings.ToLower(userRole) {
+	case "admin":
+	case "host":
+		if series.HostID != userID {
+			return nil, errors.New("forbidden: cannot manage another host's series")
+		}
+	default:
+		return nil, errors.New("forbidden: only hosts and admins can manage series")
+	}
+	return series, nil
+}
+
+// GetSeries returns the series with each occurrence's current time and
+// status, which may have changed since it was created.
+func (s *ShowService) GetSeries(ctx context.Context, seriesID, userID, userRole string) (*models.ShowSeries, error) {
+	series, err := s.getManagedSeries(ctx, seriesID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, occ := range series.Occurrences {
+		show, err := s.ShowRepo.GetByID(ctx, occ.ShowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve show %s: %w", occ.ShowID, err)
+		}
+		if show == nil {
+			continue
+		}
+		if startsAt, err := show.StartsAt(); err == nil {
+			series.Occurrences[i].StartsAt = startsAt
+		}
+		series.Occurrences[i].Status = show.Status
+	}
+	sort.Slice(series.Occurrences, func(i, j int) bool {
+		return series.Occurrences[i].StartsAt.Before(series.Occurrences[j].StartsAt)
+	})
+	return series, nil
+}
+
+func hasOccurrence(series *models.ShowSeries, showID string) bool {
+	return slices.ContainsFunc(series.Occurrences, func(o models.SeriesOccurrence) bool {
+		return o.ShowID == showID
+	})
+}
+
+// UpdateSeries reprices every upcoming occurrence of the series, or, with
+// update.ShowID set, reprices and/or moves that single occurrence.
+func (s *ShowService) UpdateSeries(ctx context.Context, seriesID, userID, userRole string, update models.SeriesUpdate) (*models.ShowSeries, error) {
+	series, err := s.getManagedSeries(ctx, seriesID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if series.Status == models.SeriesCancelled {
+		return nil, errors.New("cannot update a cancelled series")
+	}
+	if update.Price != nil && *update.Price < 0 {
+		return nil, errors.New("price cannot be negative")
+	}
+
+	if update.ShowID != "" {
+		if !hasOccurrence(series, update.ShowID) {
+			return nil, errors.New("show is not part of this series")
+		}
+		if err := s.updateOccurrence(ctx, userID, userRole, update); err != nil {
+			return nil, err
+		}
+		return s.GetSeries(ctx, seriesID, userID, userRole)
+	}
+
+	if update.ShowDate != "" || update.ShowTime != "" {
+		return nil, errors.New("show_id is required to move an occurrence")
+	}
+	if update.Price == nil && update.PriceTiers == nil {
+		return nil, errors.New("nothing to update")
+	}
+
+	if update.Price != nil {
+		series.Price = *update.Price
+	}
+	if update.PriceTiers != nil {
+		venue, err := s.VenueRepo.GetByID(ctx, series.VenueID)
+		if err != nil || venue == nil {
+			return nil, errors.New("venue not found")
+		}
+		layout := models.DefaultSeatLayout()
+		if venue.SeatLayout != nil {
+			layout = *venue.SeatLayout
+		}
+		if err := models.ValidatePriceTiers(update.PriceTiers, layout); err != nil {
+			return nil, err
+		}
+		series.PriceTiers = update.PriceTiers
+	}
+
+	if err := s.ShowRepo.UpdateSeries(ctx, series); err != nil {
+		return nil, err
+	}
+
+	// occurrences that have started or been cancelled keep their price;
+	// repeating the update retries any show that failed here
+	now := time.Now()
+	for _, occ := range series.Occurrences {
+		show, err := s.ShowRepo.GetByID(ctx, occ.ShowID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve show %s: %w", occ.ShowID, err)
+		}
+		if show == nil || show.Status == models.ShowCancelled {
+			continue
+		}
+		if startsAt, err := show.StartsAt(); err != nil || !now.Before(startsAt) {
+			continue
+		}
+		if err := s.ShowRepo.UpdatePrice(ctx, show, series.Price, series.PriceTiers); err != nil {
+			return nil, fmt.Errorf("failed to reprice show %s: %w", show.ID, err)
+		}
+	}
+
+	return s.GetSeries(ctx, seriesID, userID, userRole)
+}
+
+func (s *ShowService) updateOccurrence(ctx context.Context, userID, userRole string, update models.SeriesUpdate) error {
+	if update.ShowDate != "" || update.ShowTime != "" {
+		showDate, err := time.Parse("2006-01-02", update.ShowDate)
+		if err != nil {
+			return errors.New("invalid show_date, expected YYYY-MM-DD")
+		}
+		if _, err := s.RescheduleShow(ctx, update.ShowID, userID, userRole, showDate, update.ShowTime); err != nil {
+			return err
+		}
+	}
+
+	if update.Price == nil && update.PriceTiers == nil {
+		return nil
+	}
+
+	show, err := s.getManagedShow(ctx, update.ShowID, userID, userRole)
+	if err != nil {
+		return err
+	}
+	price, tiers := show.Price, show.PriceTiers
+	if update.Price != nil {
+		price = *update.Price
+	}
+	if update.PriceTiers != nil {
+		if err := models.ValidatePriceTiers(update.PriceTiers, show.SeatLayout); err != nil {
+			return err
+		}
+		tiers = update.PriceTiers
+	}
+	return s.ShowRepo.UpdatePrice(ctx, show, price, tiers)
+}
+
+// CancelSeries cancels every upcoming occurrence of the series, refunding
+// their bookings, or only the occurrence showID when it is set. Cancelling
+// a series again retries occurrences whose cancellation failed.
+func (s *ShowService) CancelSeries(ctx context.Context, seriesID, userID, userRole, showID, reason string) ([]models.ShowCancellationSummary, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a cancellation reason is required")
+	}
+
+	series, err := s.getManagedSeries(ctx, seriesID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if showID != "" {
+		if !hasOccurrence(series, showID) {
+			return nil, errors.New("show is not part of this series")
+		}
+		summary, err := s.CancelShow(ctx, showID, userID, userRole, reason)
+		if err != nil {
+			return nil, err
+		}
+		return []models.ShowCancellationSummary{*summary}, nil
+	}
+
+	if series.Status != models.SeriesCancelled {
+		series.Status = models.SeriesCancelled
+		if err := s.ShowRepo.UpdateSeries(ctx, series); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	summaries := []models.ShowCancellationSummary{}
+	var errs []error
+	for _, occ := range series.Occurrences {
+		show, err := s.ShowRepo.GetByID(ctx, occ.ShowID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("show %s: %w", occ.ShowID, err))
+			continue
+		}
+		if show == nil {
+			continue
+		}
+		if show.Status != models.ShowCancelled {
+			if startsAt, err := show.StartsAt(); err != nil || !now.Before(startsAt) {
+				continue
+			}
+		}
+
+		summary, err := s.CancelShow(ctx, show.ID, userID, userRole, reason)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("show %s: %w", show.ID, err))
+			continue
+		}
+		summaries = append(summaries, *summary)
+	}
+
+	return summaries, errors.Join(errs...)
+}
@@ -2,9 +2,11 @@ package showservice
 
 import (
 	"context"
-	authenticationmiddleware "eventro_aws/internals/middleware/authentication_middleware"
+	"errors"
 	"eventro_aws/internals/models"
+	eventrepository "eventro_aws/internals/repository/event_repository"
 	showrepository "eventro_aws/internals/repository/show_repository"
+	venuerepository "eventro_aws/internals/repository/venue_repository"
 	"fmt"
 	"strings"
 	"time"
@@ -12,31 +14,48 @@ import (
 	"github.com/google/uuid"
 )
 
+const maxScheduleAttempts = 3
+
+// ShowBookings is the part of the booking service that cancelling or
+// rescheduling a show cascades to.
+type ShowBookings interface {
+	CancelShowBookings(ctx context.Context, showID, reason, cancelledBy string) (*models.ShowCancellationSummary, error)
+	MoveShowBookings(ctx context.Context, showID, newShowDateTime string) error
+}
+
 type ShowService struct {
-	ShowRepo showrepository.ShowRepositoryI
+	ShowRepo  showrepository.ShowRepositoryI
+	VenueRepo venuerepository.VenueRepositoryI
+	EventRepo eventrepository.EventRepositoryI
+
+	// Bookings is only needed to cancel or reschedule shows and may be nil
+	// otherwise.
+	Bookings ShowBookings
+
+	// ChangeoverBuffer is the minimum gap kept between shows at a venue.
+	ChangeoverBuffer time.Duration
 }
 
 func NewShowService(
 	showRepo showrepository.ShowRepositoryI,
+	venueRepo venuerepository.VenueRepositoryI,
+	eventRepo eventrepository.EventRepositoryI,
+	bookings ShowBookings,
 ) *ShowService {
 	return &ShowService{
-		ShowRepo: showRepo,
+		ShowRepo:         showRepo,
+		VenueRepo:        venueRepo,
+		EventRepo:        eventRepo,
+		Bookings:         bookings,
+		ChangeoverBuffer: models.DefaultChangeoverBuffer,
 	}
 }
 
-func (s *ShowService) UpdateShow(ctx context.Context, showID string, userID string, isBlocked bool) error {
-	// fetch show first
-	_, err := s.ShowRepo.GetByID(ctx, showID)
-	if err != nil {
+func (s *ShowService) UpdateShow(ctx context.Context, showID, userID, userRole string, isBlocked bool) error {
+	if _, err := s.getManagedShow(ctx, showID, userID, userRole); err != nil {
 		return err
 	}
 
-	// authorization check (only host and admin can update)
-	userRole, _ := authenticationmiddleware.GetUserRole(ctx)
-	if strings.ToLower(userRole) != "admin" && strings.ToLower(userRole) != "host" {
-		return fmt.Errorf("forbidden: cannot update another user's show")
-	}
-
 	if err := s.ShowRepo.Update(ctx, showID, isBlocked); err != nil {
 		return err
 	}
@@ -65,7 +84,17 @@ func (s *ShowService) BrowseShows(ctx context.Context, eventID, city, date, venu
 
 func (s *ShowService) CreateShow(ctx context.Context, eventID string, venueID string,
 	hostID string, price float64, showDate time.Time,
-	showTime string) error {
+	showTime string, policy *models.CancellationPolicy, tiers []models.PriceTier) error {
+	startsAt, err := parseShowStart(showDate, showTime)
+	if err != nil {
+		return err
+	}
+
+	duration, err := s.validateNewShows(ctx, eventID, venueID, hostID, policy, tiers)
+	if err != nil {
+		return err
+	}
+
 	showID := uuid.New().String()
 
 	show := models.Show{
@@ -78,15 +107,146 @@ func (s *ShowService) CreateShow(ctx context.Context, eventID string, venueID st
 		ShowDate:    showDate,
 		ShowTime:    showTime,
 		BookedSeats: []string{},
+
+		CancellationPolicy: policy,
+		PriceTiers:         tiers,
 	}
 
-	if err := s.ShowRepo.Create(ctx, &show); err != nil {
+	err = s.scheduleAtVenue(ctx, venueID, showID, startsAt, startsAt.Add(duration), func(endsAt time.Time, version int64) error {
+		show.EndsAt = endsAt
+		show.ScheduleVersion = version
+		return s.ShowRepo.Create(ctx, &show)
+	})
+	if err != nil {
+		var conflict *models.ShowConflictError
+		if errors.As(err, &conflict) || errors.Is(err, models.ErrScheduleChanged) {
+			return err
+		}
 		return fmt.Errorf("failed to create show: %w", err)
 	}
 
 	return nil
 }
 
+// validateNewShows checks the host may schedule shows of the event at the
+// venue with the given policy and tiers, and returns how long each runs.
+func (s *ShowService) validateNewShows(ctx context.Context, eventID, venueID, hostID string,
+	policy *models.CancellationPolicy, tiers []models.PriceTier) (time.Duration, error) {
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return 0, err
+		}
+	}
+
+	venue, err := s.VenueRepo.GetByID(ctx, venueID)
+	if err != nil || venue == nil {
+		return 0, errors.New("venue not found")
+	}
+	if venue.HostID != hostID {
+		return 0, errors.New("forbidden: venue does not belong to host")
+	}
+	if venue.IsBlocked {
+		return 0, errors.New("cannot schedule shows at a blocked venue")
+	}
+
+	if len(tiers) > 0 {
+		layout := models.DefaultSeatLayout()
+		if venue.SeatLayout != nil {
+			layout = *venue.SeatLayout
+		}
+		if err := models.ValidatePriceTiers(tiers, layout); err != nil {
+			return 0, err
+		}
+	}
+
+	return s.getEventDuration(ctx, eventID)
+}
+
+// parseShowStart combines the date and HH:MM time and requires the result to
+// be in the future.
+func parseShowStart(showDate time.Time, showTime string) (time.Time, error) {
+	if _, err := time.Parse("15:04", showTime); err != nil {
+		return time.Time{}, errors.New("invalid show time, expected HH:MM")
+	}
+	startsAt, err := time.ParseInLocation("2006-01-02T15:04", showDate.Format("2006-01-02")+"T"+showTime, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid show date: %w", err)
+	}
+	if !startsAt.After(time.Now()) {
+		return time.Time{}, errors.New("shows can only be scheduled in the future")
+	}
+	return startsAt, nil
+}
+
+// getEventDuration checks the event can have shows and returns how long each
+// show runs. Events saved before durations were validated fall back to
+// DefaultEventDuration.
+func (s *ShowService) getEventDuration(ctx context.Context, eventID string) (time.Duration, error) {
+	event, err := s.EventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch event: %w", err)
+	}
+	if event == nil || event.EventID == "" {
+		return 0, errors.New("event not found")
+	}
+	if event.IsBlocked {
+		return 0, errors.New("cannot schedule shows for a blocked event")
+	}
+
+	duration, err := models.ParseEventDuration(event.Duration)
+	if err != nil {
+		return models.DefaultEventDuration, nil
+	}
+	return duration, nil
+}
+
+// scheduleAtVenue checks [startsAt, endsAt) against the venue's other shows,
+// keeping ChangeoverBuffer between them, and calls write with the schedule
+// version it checked. If another show lands at the venue in between, write
+// fails with ErrScheduleChanged and the check is repeated.
+func (s *ShowService) scheduleAtVenue(ctx context.Context, venueID, showID string, startsAt, endsAt time.Time, write func(endsAt time.Time, version int64) error) error {
+	for attempt := 1; ; attempt++ {
+		schedule, err := s.ShowRepo.GetVenueSchedule(ctx, venueID,
+			startsAt.Add(-models.MaxEventDuration-s.ChangeoverBuffer), endsAt.Add(s.ChangeoverBuffer))
+		if err != nil {
+			return err
+		}
+
+		if clashes := schedule.Conflicts(startsAt, endsAt, s.ChangeoverBuffer, showID); len(clashes) > 0 {
+			return &models.ShowConflictError{ShowIDs: clashes}
+		}
+
+		err = write(endsAt, schedule.Version)
+		if errors.Is(err, models.ErrScheduleChanged) && attempt < maxScheduleAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// BackfillVenueSlots puts upcoming shows created before venues kept a
+// schedule on it, so new shows are checked against them.
+func (s *ShowService) BackfillVenueSlots(ctx context.Context) (int, error) {
+	return s.ShowRepo.BackfillVenueSlots(ctx, time.Now())
+}
+
+// GetSeatMap renders the show's venue layout with each seat's availability.
+func (s *ShowService) GetSeatMap(ctx context.Context, showID string) (*models.ShowSeatMap, error) {
+	show, err := s.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve show: %w", err)
+	}
+	if show == nil {
+		return nil, fmt.Errorf("show not found")
+	}
+
+	return &models.ShowSeatMap{
+		ShowID:   show.ID,
+		VenueID:  show.Venue.ID,
+		Sections: show.SeatLayout.Render(show.BookedSeats, show.HeldSeats),
+	}, nil
+}
+
 func (s *ShowService) GetShowByID(ctx context.Context, showID string) (*models.ShowDTO, error) {
 	var show *models.ShowDTO
 	var err error
@@ -96,3 +256,95 @@ func (s *ShowService) GetShowByID(ctx context.Context, showID string) (*models.S
 	}
 	return show, nil
 }
+
+// getManagedShow fetches a show the caller may manage: admins may manage any
+// show, hosts only their own.
+func (s *ShowService) getManagedShow(ctx context.Context, showID, userID, userRole string) (*models.ShowDTO, error) {
+	show, err := s.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve show: %w", err)
+	}
+	if show == nil {
+		return nil, errors.New("show not found")
+	}
+
+	switch strings.ToLower(userRole) {
+	case "admin":
+	case "host":
+		if show.HostID != userID {
+			return nil, errors.New("forbidden: cannot manage another host's show")
+		}
+	default:
+		return nil, errors.New("forbidden: only hosts and admins can manage shows")
+	}
+	return show, nil
+}
+
+// CancelShow cancels the show and then every booking for it with a full
+// refund. Calling it again on a cancelled show retries any bookings or
+// refunds that failed the first time.
+func (s *ShowService) CancelShow(ctx context.Context, showID, userID, userRole, reason string) (*models.ShowCancellationSummary, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a cancellation reason is required")
+	}
+
+	show, err := s.getManagedShow(ctx, showID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if show.Status == models.ShowCancelled {
+		reason = show.CancelReason
+	} else {
+		startsAt, err := show.StartsAt()
+		if err != nil {
+			return nil, fmt.Errorf("invalid show time: %w", err)
+		}
+		if !time.Now().Before(startsAt) {
+			return nil, errors.New("cannot cancel a show that has already started")
+		}
+		if err := s.ShowRepo.Cancel(ctx, show, reason, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Bookings.CancelShowBookings(ctx, showID, reason, userID)
+}
+
+// RescheduleShow moves the show to a new date and time and re-keys its
+// bookings. Repeating the call with the same time finishes moving any
+// bookings left behind by an earlier failure.
+func (s *ShowService) RescheduleShow(ctx context.Context, showID, userID, userRole string, showDate time.Time, showTime string) (*models.ShowDTO, error) {
+	startsAt, err := parseShowStart(showDate, showTime)
+	if err != nil {
+		return nil, err
+	}
+	newShowDateTime := startsAt.Format("2006-01-02T15:04")
+
+	show, err := s.getManagedShow(ctx, showID, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	if show.Status == models.ShowCancelled {
+		return nil, errors.New("cannot reschedule a cancelled show")
+	}
+
+	if show.ShowDateTime() != newShowDateTime {
+		duration, err := s.getEventDuration(ctx, show.EventID)
+		if err != nil {
+			return nil, err
+		}
+		err = s.scheduleAtVenue(ctx, show.Venue.ID, show.ID, startsAt, startsAt.Add(duration), func(endsAt time.Time, version int64) error {
+			return s.ShowRepo.Reschedule(ctx, show, newShowDateTime, endsAt, version)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Bookings.MoveShowBookings(ctx, showID, newShowDateTime); err != nil {
+		return nil, err
+	}
+	return show, nil
+}
@@ -0,0 +1,16 @@
+package ticketservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/ticket_service_mock.go -package=mocks -source=interface.go
+type TicketServiceI interface {
+	GetTickets(ctx context.Context, userID, bookingID string) ([]models.IssuedTicket, error)
+	TicketPNG(ctx context.Context, userID, bookingID, seat string) ([]byte, error)
+	TicketPDF(ctx context.Context, userID, bookingID, seat string) ([]byte, error)
+	CheckIn(ctx context.Context, hostID, showID, token string) (*models.Admission, error)
+	GetManifest(ctx context.Context, hostID, showID string) (*models.SignedManifest, error)
+	SyncAdmissions(ctx context.Context, hostID, showID, deviceID string, scans []models.OfflineAdmission) (*models.AdmissionSyncResult, error)
+}
@@ -0,0 +1,337 @@
+package ticketservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	ticketrepository "eventro_aws/internals/repository/ticket_repository"
+	"eventro_aws/internals/tickets"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TicketService struct {
+	BookingRepo bookingrepository.BookingRepositoryI
+	ShowRepo    showrepository.ShowRepositoryI
+	TicketRepo  ticketrepository.TicketRepositoryI
+	Signer      *tickets.Signer
+}
+
+func NewTicketService(bRepo bookingrepository.BookingRepositoryI,
+	sRepo showrepository.ShowRepositoryI,
+	tRepo ticketrepository.TicketRepositoryI,
+	signer *tickets.Signer) *TicketService {
+	return &TicketService{
+		BookingRepo: bRepo,
+		ShowRepo:    sRepo,
+		TicketRepo:  tRepo,
+		Signer:      signer,
+	}
+}
+
+func (ts *TicketService) getConfirmedBooking(ctx context.Context, userID, bookingID string) (*models.UserBookingDTO, error) {
+	booking, err := ts.BookingRepo.GetByID(ctx, userID, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching booking: %w", err)
+	}
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+	if booking.Status != models.BookingConfirmed {
+		return nil, fmt.Errorf("tickets are only issued for confirmed bookings, this one is %s", booking.Status)
+	}
+	return booking, nil
+}
+
+func (ts *TicketService) issue(booking *models.UserBookingDTO, seat string) (*models.IssuedTicket, error) {
+	ticket := models.Ticket{
+		TicketID:  models.TicketIDFor(booking.BookingID, seat),
+		BookingID: booking.BookingID,
+		ShowID:    booking.ShowID,
+		Seat:      seat,
+		Version:   booking.TicketVersion,
+		IssuedAt:  time.Now().Unix(),
+	}
+	token, err := ts.Signer.Sign(ticket)
+	if err != nil {
+		return nil, fmt.Errorf("error signing ticket: %w", err)
+	}
+	return &models.IssuedTicket{Ticket: ticket, Token: token}, nil
+}
+
+// GetTickets issues a signed ticket for every seat of the user's booking.
+func (ts *TicketService) GetTickets(ctx context.Context, userID, bookingID string) ([]models.IssuedTicket, error) {
+	booking, err := ts.getConfirmedBooking(ctx, userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	issued := make([]models.IssuedTicket, 0, len(booking.Seats))
+	for _, seat := range booking.Seats {
+		ticket, err := ts.issue(booking, seat)
+		if err != nil {
+			return nil, err
+		}
+		issued = append(issued, *ticket)
+	}
+	return issued, nil
+}
+
+func (ts *TicketService) getTicket(ctx context.Context, userID, bookingID, seat string) (*models.IssuedTicket, *models.UserBookingDTO, error) {
+	booking, err := ts.getConfirmedBooking(ctx, userID, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	seat = strings.ToUpper(strings.TrimSpace(seat))
+	if !slices.Contains(booking.Seats, seat) {
+		return nil, nil, fmt.Errorf("seat %s is not part of this booking", seat)
+	}
+	ticket, err := ts.issue(booking, seat)
+	return ticket, booking, err
+}
+
+func (ts *TicketService) TicketPNG(ctx context.Context, userID, bookingID, seat string) ([]byte, error) {
+	ticket, _, err := ts.getTicket(ctx, userID, bookingID, seat)
+	if err != nil {
+		return nil, err
+	}
+	return tickets.QRCodePNG(ticket.Token)
+}
+
+func (ts *TicketService) TicketPDF(ctx context.Context, userID, bookingID, seat string) ([]byte, error) {
+	ticket, booking, err := ts.getTicket(ctx, userID, bookingID, seat)
+	if err != nil {
+		return nil, err
+	}
+
+	showTime := booking.BookingDate
+	if t, err := time.Parse("2006-01-02T15:04", booking.BookingDate); err == nil {
+		showTime = t.Format("Mon 02 Jan 2006, 15:04 UTC")
+	}
+	return tickets.PDF(ticket.Token, []string{
+		booking.EventName,
+		strings.Trim(booking.VenueName+", "+booking.VenueCity, ", "),
+		showTime,
+		"Seat " + ticket.Seat,
+		"Booking " + booking.BookingID,
+	})
+}
+
+// getHostedShow fetches a show the host runs that has not been cancelled,
+// with its start time.
+func (ts *TicketService) getHostedShow(ctx context.Context, hostID, showID string) (*models.ShowDTO, time.Time, error) {
+	show, err := ts.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error fetching show: %w", err)
+	}
+	if show == nil {
+		return nil, time.Time{}, errors.New("show not found")
+	}
+	if show.HostID != hostID {
+		return nil, time.Time{}, errors.New("forbidden: cannot manage tickets for another host's show")
+	}
+	if show.Status == models.ShowCancelled {
+		return nil, time.Time{}, errors.New("show has been cancelled")
+	}
+	startsAt, err := show.StartsAt()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid show time: %w", err)
+	}
+	return show, startsAt, nil
+}
+
+// verifyTicket checks the token's signature and that it is for the show.
+func (ts *TicketService) verifyTicket(showID, token string) (*models.Ticket, error) {
+	ticket, err := ts.Signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.ShowID != showID {
+		return nil, fmt.Errorf("%w: ticket is for another show", models.ErrInvalidTicket)
+	}
+	return ticket, nil
+}
+
+// checkBooking checks the booking still admits the ticket's seat at the
+// ticket's version.
+func checkBooking(ticket *models.Ticket, ref *models.ShowBooking) error {
+	if ref == nil {
+		return fmt.Errorf("%w: booking not found", models.ErrInvalidTicket)
+	}
+	booking := ref.Booking
+	if booking.Status != models.BookingConfirmed {
+		return fmt.Errorf("%w: booking is %s", models.ErrInvalidTicket, booking.Status)
+	}
+	if !slices.Contains(booking.Seats, ticket.Seat) {
+		return fmt.Errorf("%w: seat %s is no longer part of the booking", models.ErrInvalidTicket, ticket.Seat)
+	}
+	if ticket.Version != booking.TicketVersion {
+		return fmt.Errorf("%w: ticket has been reissued", models.ErrInvalidTicket)
+	}
+	return nil
+}
+
+// CheckIn admits the seat on a scanned ticket. The ticket must be signed by
+// us, be for this show and match a confirmed booking that still holds the
+// seat at the ticket's version. A seat can only be admitted once.
+func (ts *TicketService) CheckIn(ctx context.Context, hostID, showID, token string) (*models.Admission, error) {
+	ticket, err := ts.verifyTicket(showID, token)
+	if err != nil {
+		return nil, err
+	}
+	_, startsAt, err := ts.getHostedShow(ctx, hostID, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := ts.BookingRepo.GetShowBooking(ctx, showID, ticket.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching booking: %w", err)
+	}
+	if err := checkBooking(ticket, ref); err != nil {
+		return nil, err
+	}
+
+	admission := &models.Admission{
+		TicketID:   ticket.TicketID,
+		BookingID:  ticket.BookingID,
+		ShowID:     showID,
+		Seat:       ticket.Seat,
+		AdmittedAt: time.Now().UTC().Format(time.RFC3339),
+		AdmittedBy: hostID,
+	}
+	if err := ts.TicketRepo.Admit(ctx, admission, startsAt.Add(models.MaxEventDuration)); err != nil {
+		return nil, err
+	}
+	return admission, nil
+}
+
+// GetManifest builds the signed offline manifest of the show's valid tickets
+// and the seats admitted so far.
+func (ts *TicketService) GetManifest(ctx context.Context, hostID, showID string) (*models.SignedManifest, error) {
+	if _, _, err := ts.getHostedShow(ctx, hostID, showID); err != nil {
+		return nil, err
+	}
+
+	bookings, err := ts.BookingRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching show bookings: %w", err)
+	}
+	admissions, err := ts.TicketRepo.ListAdmissions(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := models.TicketManifest{
+		ShowID:      showID,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		PublicKey:   ts.Signer.EncodedPublicKey(),
+		Tickets:     []models.ManifestTicket{},
+		Admitted:    []string{},
+	}
+	for _, ref := range bookings {
+		if ref.Booking.Status != models.BookingConfirmed {
+			continue
+		}
+		for _, seat := range ref.Booking.Seats {
+			manifest.Tickets = append(manifest.Tickets, models.ManifestTicket{
+				TicketID:  models.TicketIDFor(ref.Booking.BookingID, seat),
+				BookingID: ref.Booking.BookingID,
+				Seat:      seat,
+				Version:   ref.Booking.TicketVersion,
+			})
+		}
+	}
+	for _, a := range admissions {
+		manifest.Admitted = append(manifest.Admitted, a.Seat)
+	}
+
+	token, err := ts.Signer.SignManifest(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error signing manifest: %w", err)
+	}
+	return &models.SignedManifest{Manifest: manifest, Token: token}, nil
+}
+
+// SyncAdmissions merges a door device's offline admission log. Each scan is
+// checked as at the door; scans of seats already admitted, online or by
+// another device, are flagged as duplicates and recorded. Uploading the same
+// log again is harmless.
+func (ts *TicketService) SyncAdmissions(ctx context.Context, hostID, showID, deviceID string, scans []models.OfflineAdmission) (*models.AdmissionSyncResult, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if deviceID == "" {
+		return nil, errors.New("device_id is required")
+	}
+
+	_, startsAt, err := ts.getHostedShow(ctx, hostID, showID)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := startsAt.Add(models.MaxEventDuration)
+
+	bookings, err := ts.BookingRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching show bookings: %w", err)
+	}
+	byID := make(map[string]*models.ShowBooking, len(bookings))
+	for i := range bookings {
+		byID[bookings[i].Booking.BookingID] = &bookings[i]
+	}
+
+	result := &models.AdmissionSyncResult{}
+	for _, scan := range scans {
+		reject := func(err error) {
+			result.Rejected = append(result.Rejected, models.RejectedAdmission{Token: scan.Token, Reason: err.Error()})
+		}
+
+		admittedAt, err := time.Parse(time.RFC3339, scan.AdmittedAt)
+		if err != nil {
+			reject(errors.New("admitted_at must be an RFC 3339 time"))
+			continue
+		}
+		ticket, err := ts.verifyTicket(showID, scan.Token)
+		if err != nil {
+			reject(err)
+			continue
+		}
+		if err := checkBooking(ticket, byID[ticket.BookingID]); err != nil {
+			reject(err)
+			continue
+		}
+
+		admission := &models.Admission{
+			TicketID:   ticket.TicketID,
+			BookingID:  ticket.BookingID,
+			ShowID:     showID,
+			Seat:       ticket.Seat,
+			AdmittedAt: admittedAt.UTC().Format(time.RFC3339),
+			AdmittedBy: hostID,
+			Device:     deviceID,
+		}
+		err = ts.TicketRepo.Admit(ctx, admission, expiresAt)
+		var admitted *models.AlreadyAdmittedError
+		switch {
+		case err == nil:
+			result.Accepted++
+		case errors.As(err, &admitted):
+			first := admitted.Admission
+			if first.Device == admission.Device && first.AdmittedAt == admission.AdmittedAt {
+				// this scan was synced before
+				result.Accepted++
+				continue
+			}
+			duplicate := models.DuplicateAdmission{Scan: *admission, First: first}
+			if err := ts.TicketRepo.RecordDuplicate(ctx, &duplicate, expiresAt); err != nil {
+				return nil, err
+			}
+			result.Duplicates = append(result.Duplicates, duplicate)
+		default:
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,14 @@
+package transferservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/transfer_service_mock.go -package=mocks -source=interface.go
+type TransferServiceI interface {
+	StartTransfer(ctx context.Context, userID, bookingID, toEmail string, seats []string) (*models.BookingTransfer, error)
+	AcceptTransfer(ctx context.Context, userID, transferID string) (*models.BookingTransfer, error)
+	CancelTransfer(ctx context.Context, userID, transferID string) (*models.BookingTransfer, error)
+	ListTransfers(ctx context.Context, userID string) (*models.TransferList, error)
+}
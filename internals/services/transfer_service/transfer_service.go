@@ -0,0 +1,241 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	userrepository "eventro_aws/internals/repository/user_repository"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultTransferCutoff = 24 * time.Hour
+
+type TransferService struct {
+	BookingRepo bookingrepository.BookingRepositoryI
+	ShowRepo    showrepository.ShowRepositoryI
+	UserRepo    userrepository.UserRepositoryI
+
+	// Cutoff is how long before the show transfers close. Pending transfers
+	// that were not accepted by then expire.
+	Cutoff time.Duration
+}
+
+func NewTransferService(bRepo bookingrepository.BookingRepositoryI,
+	sRepo showrepository.ShowRepositoryI,
+	uRepo userrepository.UserRepositoryI) *TransferService {
+	return &TransferService{
+		BookingRepo: bRepo,
+		ShowRepo:    sRepo,
+		UserRepo:    uRepo,
+		Cutoff:      DefaultTransferCutoff,
+	}
+}
+
+// transferDeadline returns when transfers for the show close.
+func (ts *TransferService) transferDeadline(ctx context.Context, showID string) (time.Time, error) {
+	show, err := ts.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error fetching show: %w", err)
+	}
+	if show == nil || show.Status == models.ShowCancelled {
+		return time.Time{}, models.ErrTransferWindowClosed
+	}
+	startsAt, err := show.StartsAt()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid show time: %w", err)
+	}
+	return startsAt.Add(-ts.Cutoff), nil
+}
+
+func newTransferEvent(action, by string) models.TransferEvent {
+	return models.TransferEvent{Action: action, By: by, At: time.Now().UTC().Format(time.RFC3339)}
+}
+
+// StartTransfer offers seats of the user's booking to another registered
+// user. No seats means the whole booking. The seats stay with the sender
+// until the recipient accepts.
+func (ts *TransferService) StartTransfer(ctx context.Context, userID, bookingID, toEmail string, seats []string) (*models.BookingTransfer, error) {
+	toEmail = strings.TrimSpace(toEmail)
+	if toEmail == "" {
+		return nil, errors.New("recipient email is required")
+	}
+	if strings.EqualFold(toEmail, userID) {
+		return nil, errors.New("cannot transfer tickets to yourself")
+	}
+	recipient, err := ts.UserRepo.GetByEmail(toEmail)
+	if err != nil || recipient == nil {
+		return nil, errors.New("recipient must be a registered user")
+	}
+	if recipient.IsBlocked {
+		return nil, errors.New("recipient cannot receive tickets")
+	}
+
+	booking, err := ts.BookingRepo.GetByID(ctx, userID, bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching booking: %w", err)
+	}
+	if booking == nil {
+		return nil, errors.New("booking not found")
+	}
+	if booking.Status != models.BookingConfirmed {
+		return nil, fmt.Errorf("only confirmed bookings can be transferred, this one is %s", booking.Status)
+	}
+	if len(seats) == 0 {
+		seats = booking.Seats
+	}
+	if _, _, err := booking.SplitSeats(seats); err != nil {
+		return nil, err
+	}
+
+	deadline, err := ts.transferDeadline(ctx, booking.ShowID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !now.Before(deadline) {
+		return nil, models.ErrTransferWindowClosed
+	}
+
+	existing, err := ts.BookingRepo.ListTransfers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching transfers: %w", err)
+	}
+	for _, t := range existing.Outgoing {
+		if t.BookingID != bookingID || t.Status != models.TransferPending || !now.Before(t.ExpiresAt) {
+			continue
+		}
+		for _, seat := range seats {
+			if slices.Contains(t.Seats, seat) {
+				return nil, fmt.Errorf("seat %s is already in transfer %s", seat, t.TransferID)
+			}
+		}
+	}
+
+	transfer := &models.BookingTransfer{
+		TransferID: uuid.New().String(),
+		BookingID:  bookingID,
+		ShowID:     booking.ShowID,
+		FromUserID: userID,
+		ToUserID:   recipient.Email,
+		Seats:      seats,
+		Status:     models.TransferPending,
+		CreatedAt:  now.UTC(),
+		ExpiresAt:  deadline.UTC(),
+		History:    []models.TransferEvent{newTransferEvent(models.TransferActionCreated, userID)},
+	}
+	if err := ts.BookingRepo.CreateTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// getPendingTransfer fetches a transfer that can still be acted on.
+func (ts *TransferService) getPendingTransfer(ctx context.Context, transferID string) (*models.BookingTransfer, error) {
+	transfer, err := ts.BookingRepo.GetTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, models.ErrTransferNotFound
+	}
+	if transfer.Status != models.TransferPending {
+		return nil, models.ErrTransferNotPending
+	}
+	return transfer, nil
+}
+
+// AcceptTransfer moves the transfer's seats into a new booking for the
+// recipient. The original booking keeps the rest of its seats, or is closed
+// if none are left, and its tickets are reissued so ones handed out before
+// the transfer no longer scan.
+func (ts *TransferService) AcceptTransfer(ctx context.Context, userID, transferID string) (*models.BookingTransfer, error) {
+	transfer, err := ts.getPendingTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, models.ErrTransferNotFound
+	}
+
+	deadline, err := ts.transferDeadline(ctx, transfer.ShowID)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(deadline) {
+		return nil, models.ErrTransferWindowClosed
+	}
+
+	booking, err := ts.BookingRepo.GetByID(ctx, transfer.FromUserID, transfer.BookingID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching booking: %w", err)
+	}
+	if booking == nil || booking.Status != models.BookingConfirmed {
+		return nil, errors.New("the booking can no longer be transferred")
+	}
+	kept, received, err := booking.SplitSeats(transfer.Seats)
+	if err != nil {
+		return nil, fmt.Errorf("the booking can no longer be transferred: %w", err)
+	}
+	received.BookingID = uuid.New().String()
+
+	var remaining *models.UserBookingDTO
+	if len(kept.Seats) > 0 {
+		remaining = &kept
+	}
+
+	event := newTransferEvent(models.TransferActionAccepted, userID)
+	if err := ts.BookingRepo.AcceptTransfer(ctx, transfer, booking, remaining, &received, event); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// CancelTransfer ends a pending transfer. The sender cancels it, the
+// recipient declines it; either way the seats stay where they are.
+func (ts *TransferService) CancelTransfer(ctx context.Context, userID, transferID string) (*models.BookingTransfer, error) {
+	transfer, err := ts.getPendingTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status, action string
+	switch userID {
+	case transfer.FromUserID:
+		status, action = models.TransferCancelled, models.TransferActionCancelled
+	case transfer.ToUserID:
+		status, action = models.TransferDeclined, models.TransferActionDeclined
+	default:
+		return nil, models.ErrTransferNotFound
+	}
+
+	if err := ts.BookingRepo.UpdateTransferStatus(ctx, transfer, status, newTransferEvent(action, userID)); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// ListTransfers returns the transfers the user has sent and received.
+// Pending transfers past their deadline are reported as expired.
+func (ts *TransferService) ListTransfers(ctx context.Context, userID string) (*models.TransferList, error) {
+	list, err := ts.BookingRepo.ListTransfers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, transfers := range [][]models.BookingTransfer{list.Incoming, list.Outgoing} {
+		for i := range transfers {
+			if transfers[i].Status == models.TransferPending && !now.Before(transfers[i].ExpiresAt) {
+				transfers[i].Status = models.TransferExpired
+			}
+		}
+	}
+	return list, nil
+}
@@ -8,4 +8,7 @@ import (
 //go:generate mockgen -destination=../../mocks/user_service_mock.go -package=mocks -source=interface.go
 type UserServiceI interface {
 	GetUserByMailID(ctx context.Context, mail string) (*models.User, error)
+	ListUsers(ctx context.Context, callerRole, query, role string, limit int, cursor string) (*models.UserPage, error)
+	UpdateUser(ctx context.Context, callerEmail, callerRole, email string, req models.UpdateUserRequest) (*models.UserSummary, error)
+	BackfillUserIndex(ctx context.Context) (int, error)
 }
@@ -2,9 +2,16 @@ package userservice
 
 import (
 	"context"
+	"errors"
 	"eventro_aws/internals/models"
 	userrepository "eventro_aws/internals/repository/user_repository"
 	"fmt"
+	"strings"
+)
+
+const (
+	defaultUserPageSize = 25
+	maxUserPageSize     = 100
 )
 
 type UserService struct {
@@ -26,3 +33,77 @@ func (s *UserService) GetUserByMailID(ctx context.Context, mail string) (*models
 	}
 	return user, nil
 }
+
+// ListUsers pages through accounts for admins, optionally searching on
+// username or email and filtering by role.
+func (s *UserService) ListUsers(ctx context.Context, callerRole, query, role string, limit int, cursor string) (*models.UserPage, error) {
+	if strings.ToLower(callerRole) != "admin" {
+		return nil, errors.New("forbidden: only admins can list users")
+	}
+
+	var roleFilter models.Role
+	if role != "" {
+		r, err := models.ParseRole(role)
+		if err != nil {
+			return nil, err
+		}
+		roleFilter = r
+	}
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+
+	return s.UserRepo.List(ctx, strings.TrimSpace(query), roleFilter, int32(limit), cursor)
+}
+
+// BackfillUserIndex lists users who signed up before the user listing index
+// existed, so admins can page through them without a scan.
+func (s *UserService) BackfillUserIndex(ctx context.Context) (int, error) {
+	return s.UserRepo.BackfillIndex(ctx)
+}
+
+// UpdateUser blocks, unblocks or changes the role of a user. Admins cannot
+// block or demote themselves, so there is always an admin left to undo a
+// mistake.
+func (s *UserService) UpdateUser(ctx context.Context, callerEmail, callerRole, email string, req models.UpdateUserRequest) (*models.UserSummary, error) {
+	if strings.ToLower(callerRole) != "admin" {
+		return nil, errors.New("forbidden: only admins can update users")
+	}
+	if email == "" {
+		return nil, fmt.Errorf("invalid email")
+	}
+	if req.IsBlocked == nil && req.Role == nil {
+		return nil, errors.New("isBlocked or role is required")
+	}
+	if req.Role != nil {
+		role, err := models.ParseRole(*req.Role)
+		if err != nil {
+			return nil, err
+		}
+		name := string(role)
+		req.Role = &name
+	}
+	if strings.EqualFold(email, callerEmail) {
+		if req.IsBlocked != nil && *req.IsBlocked {
+			return nil, errors.New("admins cannot block themselves")
+		}
+		if req.Role != nil && *req.Role != string(models.Admin) {
+			return nil, errors.New("admins cannot demote themselves")
+		}
+	}
+
+	user, err := s.UserRepo.Update(ctx, email, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserSummary{
+		Email:       user.Email,
+		Username:    user.Username,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
+		IsBlocked:   user.IsBlocked,
+	}, nil
+}
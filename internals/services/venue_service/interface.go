@@ -6,8 +6,9 @@ import (
 )
 
 type VenueServiceI interface {
-	CreateVenue(ctx context.Context, hostID, name, city, state string, isSeatLayoutRequired bool) (models.VenueResponse, error)
+	CreateVenue(ctx context.Context, hostID, name, city, state string, isSeatLayoutRequired bool, layout *models.SeatLayout) (models.VenueResponse, error)
 	UpdateVenue(ctx context.Context, venueID, userID, userRole string, isBlocked bool) error
+	UpdateSeatLayout(ctx context.Context, venueID, userID, userRole string, layout *models.SeatLayout) error
 	DeleteVenue(ctx context.Context, venueID, userID, userRole string) error
 	GetHostVenues(ctx context.Context, hostID string) ([]models.VenueResponse, error)
 	GetVenueByID(ctx context.Context, venueID string) (*models.VenueResponse, error)
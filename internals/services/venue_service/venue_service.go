@@ -2,42 +2,57 @@ package venueservice
 
 import (
 	"context"
+	"errors"
 	"eventro_aws/internals/models"
+	showrepository "eventro_aws/internals/repository/show_repository"
 	venuerepository "eventro_aws/internals/repository/venue_repository"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type VenueService struct {
 	VenueRepo venuerepository.VenueRepositoryI
+	ShowRepo  showrepository.ShowRepositoryI
 }
 
-func NewVenueService(repo venuerepository.VenueRepositoryI) *VenueService {
-	return &VenueService{VenueRepo: repo}
+func NewVenueService(repo venuerepository.VenueRepositoryI, showRepo showrepository.ShowRepositoryI) *VenueService {
+	return &VenueService{VenueRepo: repo, ShowRepo: showRepo}
 }
 
-func (vs *VenueService) CreateVenue(ctx context.Context, hostID, name, city, state string, isSeatLayoutRequired bool) (models.VenueResponse, error) {
+func (vs *VenueService) CreateVenue(ctx context.Context, hostID, name, city, state string, isSeatLayoutRequired bool, layout *models.SeatLayout) (models.VenueResponse, error) {
+	if isSeatLayoutRequired && layout == nil {
+		return models.VenueResponse{}, errors.New("seat layout is required")
+	}
+	if layout != nil {
+		if err := layout.Validate(); err != nil {
+			return models.VenueResponse{}, fmt.Errorf("invalid seat layout: %w", err)
+		}
+	}
+
 	venueID := uuid.New().String()
 
 	venue := models.Venue{
-		ID:     venueID,
-		HostID: hostID,
-		Name:   name,
-		City:   city,
-		State:  state,
+		ID:         venueID,
+		HostID:     hostID,
+		Name:       name,
+		City:       city,
+		State:      state,
+		SeatLayout: layout,
 	}
 
 	if err := vs.VenueRepo.Create(ctx, &venue); err != nil {
 		return models.VenueResponse{}, fmt.Errorf("failed to create venue: %w", err)
 	}
 	venueDTO := models.VenueResponse{
-		ID:     venueID,
-		HostID: hostID,
-		Name:   name,
-		City:   city,
-		State:  state,
+		ID:         venueID,
+		HostID:     hostID,
+		Name:       name,
+		City:       city,
+		State:      state,
+		SeatLayout: layout,
 	}
 
 	return venueDTO, nil
@@ -60,6 +75,58 @@ func (s *VenueService) UpdateVenue(ctx context.Context, venueID, userID, userRol
 	return nil
 }
 
+func (s *VenueService) UpdateSeatLayout(ctx context.Context, venueID, userID, userRole string, layout *models.SeatLayout) error {
+	if layout == nil {
+		return errors.New("seat layout is required")
+	}
+	if err := layout.Validate(); err != nil {
+		return fmt.Errorf("invalid seat layout: %w", err)
+	}
+
+	venue, err := s.VenueRepo.GetByID(ctx, venueID)
+	if err != nil {
+		return err
+	}
+	if venue.HostID != userID && strings.ToLower(userRole) != "admin" {
+		return fmt.Errorf("forbidden: cannot update another user's venue")
+	}
+	if err := s.checkLayoutChange(ctx, venueID, layout); err != nil {
+		return err
+	}
+
+	return s.VenueRepo.UpdateSeatLayout(ctx, venueID, venue.HostID, layout)
+}
+
+// checkLayoutChange rejects a layout that drops seats booked or held for a
+// show that may not have finished yet, or sections its price tiers refer to.
+func (s *VenueService) checkLayoutChange(ctx context.Context, venueID string, layout *models.SeatLayout) error {
+	shows, err := s.ShowRepo.ListByVenue(ctx, venueID)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-models.MaxEventDuration)
+	for _, show := range shows {
+		if show.Status == models.ShowCancelled {
+			continue
+		}
+		if startsAt, err := show.StartsAt(); err == nil && startsAt.Before(cutoff) {
+			continue
+		}
+		for _, seats := range [][]string{show.BookedSeats, show.HeldSeats} {
+			for _, seat := range seats {
+				if !layout.HasSeat(seat) {
+					return fmt.Errorf("seat %s is taken for show %s and cannot be removed", seat, show.ID)
+				}
+			}
+		}
+		if err := models.ValidatePriceTiers(show.PriceTiers, *layout); err != nil {
+			return fmt.Errorf("show %s: %w", show.ID, err)
+		}
+	}
+	return nil
+}
+
 func (s *VenueService) DeleteVenue(ctx context.Context, venueID, userID, userRole string) error {
 	venue, err := s.VenueRepo.GetByID(ctx, venueID)
 	if err != nil {
@@ -97,6 +164,8 @@ func (s *VenueService) GetVenueByID(ctx context.Context, venueID string) (*model
 		City:      v.City,
 		State:     v.State,
 		IsBlocked: v.IsBlocked,
+
+		SeatLayout: v.SeatLayout,
 	}
 	return &venueDTO, nil
 
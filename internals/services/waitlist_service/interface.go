@@ -0,0 +1,16 @@
+package waitlistservice
+
+import (
+	"context"
+	"eventro_aws/internals/models"
+)
+
+//go:generate mockgen -destination=../../mocks/waitlist_service_mock.go -package=mocks -source=interface.go
+type WaitlistServiceI interface {
+	Join(ctx context.Context, userID, showID string, seats int) (*models.WaitlistEntry, error)
+	Leave(ctx context.Context, userID, showID string) error
+	GetPosition(ctx context.Context, userID, showID string) (*models.WaitlistEntry, error)
+	ListForUser(ctx context.Context, userID string) ([]models.WaitlistEntry, error)
+	OnSeatsReleased(ctx context.Context, showID string) error
+	ProcessAll(ctx context.Context) error
+}
@@ -0,0 +1,245 @@
+package waitlistservice
+
+import (
+	"context"
+	"errors"
+	"eventro_aws/internals/models"
+	bookingrepository "eventro_aws/internals/repository/booking_repository"
+	showrepository "eventro_aws/internals/repository/show_repository"
+	waitlistrepository "eventro_aws/internals/repository/waitlist_repository"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultOfferDuration = 15 * time.Minute
+	maxWaitlistSeats     = 10
+)
+
+type WaitlistService struct {
+	WaitlistRepo  waitlistrepository.WaitlistRepositoryI
+	ShowRepo      showrepository.ShowRepositoryI
+	BookingRepo   bookingrepository.BookingRepositoryI
+	OfferDuration time.Duration
+}
+
+func NewWaitlistService(wRepo waitlistrepository.WaitlistRepositoryI,
+	sRepo showrepository.ShowRepositoryI,
+	bRepo bookingrepository.BookingRepositoryI) *WaitlistService {
+	return &WaitlistService{
+		WaitlistRepo:  wRepo,
+		ShowRepo:      sRepo,
+		BookingRepo:   bRepo,
+		OfferDuration: DefaultOfferDuration,
+	}
+}
+
+// getOpenShow fetches a show that can still be booked and returns when it
+// starts.
+func (ws *WaitlistService) getOpenShow(ctx context.Context, showID string) (*models.ShowDTO, time.Time, error) {
+	show, err := ws.ShowRepo.GetByID(ctx, showID)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error fetching show: %w", err)
+	}
+	if show == nil {
+		return nil, time.Time{}, errors.New("show not found")
+	}
+	if show.Status == models.ShowCancelled || show.IsBlocked {
+		return nil, time.Time{}, errors.New("show is not open for booking")
+	}
+	startsAt, err := show.StartsAt()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid show time: %w", err)
+	}
+	if !time.Now().Before(startsAt) {
+		return nil, time.Time{}, errors.New("show has already started")
+	}
+	return show, startsAt, nil
+}
+
+// Join puts the user at the back of a sold-out show's waitlist.
+func (ws *WaitlistService) Join(ctx context.Context, userID, showID string, seats int) (*models.WaitlistEntry, error) {
+	if seats < 1 || seats > maxWaitlistSeats {
+		return nil, fmt.Errorf("seats must be between 1 and %d", maxWaitlistSeats)
+	}
+
+	show, startsAt, err := ws.getOpenShow(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+	if len(show.AvailableSeats) > 0 {
+		return nil, errors.New("show still has free seats, book them directly")
+	}
+
+	entry := &models.WaitlistEntry{
+		ShowID:   showID,
+		UserID:   userID,
+		Seats:    seats,
+		JoinedAt: time.Now().UTC(),
+		Status:   models.WaitlistWaiting,
+	}
+	if err := ws.WaitlistRepo.Join(ctx, entry, startsAt); err != nil {
+		return nil, err
+	}
+
+	return ws.GetPosition(ctx, userID, showID)
+}
+
+// Leave removes the user from the waitlist, releasing any offer they were
+// holding so it passes to the next person.
+func (ws *WaitlistService) Leave(ctx context.Context, userID, showID string) error {
+	entry, err := ws.WaitlistRepo.Get(ctx, showID, userID)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return models.ErrNotWaitlisted
+	}
+
+	if err := ws.WaitlistRepo.Remove(ctx, entry); err != nil {
+		return err
+	}
+
+	if entry.Status == models.WaitlistOffered && !entry.OfferExpired(time.Now()) {
+		hold, err := ws.BookingRepo.GetHold(ctx, userID, entry.OfferHoldID)
+		if err != nil {
+			return fmt.Errorf("error fetching offer: %w", err)
+		}
+		if hold != nil {
+			if err := ws.BookingRepo.ReleaseHold(ctx, hold); err != nil {
+				return fmt.Errorf("error releasing offer: %w", err)
+			}
+			return ws.OnSeatsReleased(ctx, showID)
+		}
+	}
+	return nil
+}
+
+// GetPosition returns the user's entry with its place in the queue.
+func (ws *WaitlistService) GetPosition(ctx context.Context, userID, showID string) (*models.WaitlistEntry, error) {
+	entries, err := ws.WaitlistRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return nil, err
+	}
+
+	position := 0
+	for _, entry := range entries {
+		if entry.Status == models.WaitlistWaiting {
+			position++
+		}
+		if entry.UserID == userID {
+			if entry.Status == models.WaitlistWaiting {
+				entry.Position = position
+			}
+			return &entry, nil
+		}
+	}
+	return nil, models.ErrNotWaitlisted
+}
+
+// ListForUser returns every waitlist the user is on with their positions.
+func (ws *WaitlistService) ListForUser(ctx context.Context, userID string) ([]models.WaitlistEntry, error) {
+	mine, err := ws.WaitlistRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.WaitlistEntry, 0, len(mine))
+	for _, e := range mine {
+		entry, err := ws.GetPosition(ctx, userID, e.ShowID)
+		if errors.Is(err, models.ErrNotWaitlisted) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+// OnSeatsReleased offers the show's free seats to the waitlist in the order
+// people joined. Offers are only made while the person at the front can be
+// given all the seats they asked for, so nobody is skipped for asking for
+// more. Offers that lapsed without being taken are dropped from the queue.
+func (ws *WaitlistService) OnSeatsReleased(ctx context.Context, showID string) error {
+	entries, err := ws.WaitlistRepo.ListByShow(ctx, showID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	show, _, err := ws.getOpenShow(ctx, showID)
+	if err != nil {
+		// nothing can be offered for a show that is over or called off
+		return nil
+	}
+
+	now := time.Now()
+	free := show.AvailableSeats
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status == models.WaitlistOffered {
+			if entry.OfferExpired(now) {
+				if err := ws.WaitlistRepo.Remove(ctx, entry); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if entry.Seats > len(free) {
+			break
+		}
+
+		hold := &models.SeatHold{
+			HoldID:    uuid.New().String(),
+			ShowID:    showID,
+			UserID:    entry.UserID,
+			Seats:     free[:entry.Seats],
+			ExpiresAt: now.Add(ws.OfferDuration).UTC(),
+		}
+		if err := ws.BookingRepo.CreateHold(ctx, hold); err != nil {
+			var conflict *models.SeatConflictError
+			if errors.As(err, &conflict) {
+				// someone took the seats first; the next release tries again
+				return nil
+			}
+			return fmt.Errorf("error creating offer: %w", err)
+		}
+
+		if err := ws.WaitlistRepo.MarkOffered(ctx, entry, hold); err != nil {
+			// the entry left the queue in the meantime, so give the seats back
+			if rerr := ws.BookingRepo.ReleaseHold(ctx, hold); rerr != nil {
+				return fmt.Errorf("%w (releasing offer failed: %v)", err, rerr)
+			}
+			if errors.Is(err, models.ErrNotWaitlisted) {
+				continue
+			}
+			return err
+		}
+		free = free[entry.Seats:]
+	}
+	return nil
+}
+
+// ProcessAll runs OnSeatsReleased for every show with a waitlist. Seats free
+// up silently when holds expire, so this is run on a schedule.
+func (ws *WaitlistService) ProcessAll(ctx context.Context) error {
+	showIDs, err := ws.WaitlistRepo.ListWaitlistedShows(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, showID := range showIDs {
+		if err := ws.OnSeatsReleased(ctx, showID); err != nil {
+			errs = append(errs, fmt.Errorf("show %s: %w", showID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,101 @@
+package tickets
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const qrSize = 512
+
+// QRCodePNG encodes the token as a PNG QR code.
+func QRCodePNG(token string) ([]byte, error) {
+	return qrcode.Encode(token, qrcode.Medium, qrSize)
+}
+
+// PDF renders a one page ticket with the token's QR code above the lines of
+// text, which should be plain ASCII.
+func PDF(token string, lines []string) ([]byte, error) {
+	qr, err := qrcode.New(token, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+
+	// one grey byte per QR module; the page scales it up
+	bitmap := qr.Bitmap()
+	modules := len(bitmap)
+	var raw bytes.Buffer
+	for _, row := range bitmap {
+		for _, dark := range row {
+			if dark {
+				raw.WriteByte(0x00)
+			} else {
+				raw.WriteByte(0xff)
+			}
+		}
+	}
+	var image bytes.Buffer
+	zw := zlib.NewWriter(&image)
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	// A6 portrait in points
+	const pageW, pageH, qrPt = 298, 420, 220
+	var content strings.Builder
+	fmt.Fprintf(&content, "q %d 0 0 %d %d %d cm /Im1 Do Q\n", qrPt, qrPt, (pageW-qrPt)/2, pageH-qrPt-20)
+	content.WriteString("BT /F1 11 Tf 24 170 Td 14 TL\n")
+	for _, line := range lines {
+		fmt.Fprintf(&content, "(%s) '\n", pdfText(line))
+	}
+	content.WriteString("ET\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R >> /XObject << /Im1 5 0 R >> >> /Contents 6 0 R >>", pageW, pageH),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Interpolate false /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			modules, modules, image.Len(), image.String()),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes(), nil
+}
+
+// pdfText escapes a string for a PDF literal, replacing anything outside
+// printable ASCII.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
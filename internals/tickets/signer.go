@@ -0,0 +1,110 @@
+package tickets
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"eventro_aws/internals/models"
+	"fmt"
+	"strings"
+)
+
+// Tokens are "<payload>.<signature>", both unpadded base64url. The payload is
+// the ticket as JSON and the signature is Ed25519 over "ticket:v1." followed
+// by the encoded payload, so a scanner holding only the public key can check
+// a ticket offline. The prefix keeps a signature made for anything else from
+// passing as a ticket's.
+
+var b64 = base64.RawURLEncoding
+
+const (
+	ticketDomain   = "ticket:v1"
+	manifestDomain = "manifest:v1"
+)
+
+func signedMessage(domain, encoded string) []byte {
+	return []byte(domain + "." + encoded)
+}
+
+type Signer struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+// NewSigner builds a signer from a base64 encoded 32 byte Ed25519 seed, as
+// kept in TICKET_SIGNING_KEY. There is no fallback key: anyone holding the
+// seed can forge tickets.
+func NewSigner(encodedSeed string) (*Signer, error) {
+	if strings.TrimSpace(encodedSeed) == "" {
+		return nil, errors.New("TICKET_SIGNING_KEY is not configured")
+	}
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedSeed))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ticket signing key: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("ticket signing key must be %d bytes", ed25519.SeedSize)
+	}
+	private := ed25519.NewKeyFromSeed(seed)
+	return &Signer{private: private, public: private.Public().(ed25519.PublicKey)}, nil
+}
+
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.public
+}
+
+func (s *Signer) Sign(ticket models.Ticket) (string, error) {
+	return s.sign(ticketDomain, ticket)
+}
+
+// SignManifest signs a show's offline manifest in the same token format as
+// tickets, with "manifest:v1." in place of "ticket:v1.".
+func (s *Signer) SignManifest(manifest models.TicketManifest) (string, error) {
+	return s.sign(manifestDomain, manifest)
+}
+
+func (s *Signer) sign(domain string, v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := b64.EncodeToString(payload)
+	sig := ed25519.Sign(s.private, signedMessage(domain, encoded))
+	return encoded + "." + b64.EncodeToString(sig), nil
+}
+
+// EncodedPublicKey is the public key as standard base64, as published in
+// manifests.
+func (s *Signer) EncodedPublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.public)
+}
+
+func (s *Signer) Verify(token string) (*models.Ticket, error) {
+	return Verify(s.public, token)
+}
+
+// Verify checks the token was signed by the key and returns its ticket.
+func Verify(publicKey ed25519.PublicKey, token string) (*models.Ticket, error) {
+	encoded, sigPart, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return nil, models.ErrInvalidTicket
+	}
+	sig, err := b64.DecodeString(sigPart)
+	if err != nil || !ed25519.Verify(publicKey, signedMessage(ticketDomain, encoded), sig) {
+		return nil, models.ErrInvalidTicket
+	}
+
+	payload, err := b64.DecodeString(encoded)
+	if err != nil {
+		return nil, models.ErrInvalidTicket
+	}
+	var ticket models.Ticket
+	if err := json.Unmarshal(payload, &ticket); err != nil {
+		return nil, models.ErrInvalidTicket
+	}
+	if ticket.BookingID == "" || ticket.ShowID == "" || ticket.Seat == "" {
+		return nil, errors.Join(models.ErrInvalidTicket, errors.New("ticket is missing fields"))
+	}
+	return &ticket, nil
+}
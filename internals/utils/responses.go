@@ -1,6 +1,7 @@
 package customresponse
 
 import (
+	"encoding/base64"
 	"encoding/json"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -39,3 +40,21 @@ func LambdaError(statusCode int, message string) (events.APIGatewayProxyResponse
 	}, nil
 
 }
+
+// SendBinaryResponse returns a file such as an image or PDF. API Gateway
+// decodes the base64 body for content types listed in BinaryMediaTypes.
+func SendBinaryResponse(statusCode int, contentType, filename string, data []byte) (events.APIGatewayProxyResponse, error) {
+	headers := map[string]string{
+		"Content-Type": contentType,
+	}
+	if filename != "" {
+		headers["Content-Disposition"] = `inline; filename="` + filename + `"`
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode:      statusCode,
+		Body:            base64.StdEncoding.EncodeToString(data),
+		IsBase64Encoded: true,
+		Headers:         headers,
+	}, nil
+}